@@ -33,6 +33,9 @@ bx build --name my_module --version 1.2.3
 
 # Build .last_version
 bx build --name my_module --last
+
+# Build from a git tag without touching the working directory
+bx build --name my_module --version 1.4.0 --ref v1.4.0
 `,
 		RunE: build,
 	}
@@ -43,6 +46,7 @@ bx build --name my_module --last
 	cmd.Flags().StringP("repository", "r", "", "Path to a repository")
 	cmd.Flags().StringP("description", "d", "", "Version description")
 	cmd.Flags().BoolP("last", "", false, "Build a module .last_version.zip")
+	cmd.Flags().StringP("ref", "", "", "Git ref (tag, branch or commit) to read the sources from")
 
 	return cmd
 }
@@ -74,6 +78,13 @@ func build(cmd *cobra.Command, _ []string) error {
 
 	mod.LastVersion = last
 
+	ref, _ := cmd.Flags().GetString("ref")
+	mod.Ref = ref
+
+	if mod.UsesWorktree() {
+		_, _ = fmt.Fprintln(cmd.ErrOrStderr(), module.WorktreeWarning)
+	}
+
 	loggerInstance := logger.NewFileLogger(mod.Log, mod.Name)
 	builder := builderFunc(mod, loggerInstance)
 
@@ -84,7 +95,7 @@ func build(cmd *cobra.Command, _ []string) error {
 
 	builder.Cleanup()
 
-	fmt.Printf("Module %s successfully built. Version: %s\n", mod.Name, mod.Version)
+	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Module %s successfully built. Version: %s\n", mod.Name, mod.Version)
 
 	return nil
 }
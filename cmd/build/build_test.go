@@ -6,8 +6,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
-	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -36,6 +36,20 @@ func (m *FakeFailBuilder) Rollback() error                 { return errors.New("
 func (m *FakeFailBuilder) Collect(_ context.Context) error { return errors.New("collect error") }
 func (m *FakeFailBuilder) Cleanup()                        {}
 
+// writeModule writes the default module configuration to a temporary directory
+// and returns its path. The log of the module is written to the same directory.
+func writeModule(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	filePath := filepath.Join(dir, "module.yaml")
+	data := strings.Replace(helpers.DefaultYAML(), `dir: "./logs"`, fmt.Sprintf("dir: %q", filepath.Join(dir, "logs")), 1)
+
+	require.NoError(t, os.WriteFile(filePath, []byte(data), 0600))
+
+	return filePath
+}
+
 func Test_newBuildCommand(t *testing.T) {
 	cmd := NewBuildCommand()
 	assert.NotNil(t, cmd)
@@ -49,20 +63,7 @@ func Test_newBuildCommand(t *testing.T) {
 }
 
 func Test_build_IsValid(t *testing.T) {
-	fileName := fmt.Sprintf("mod-%d.yaml", time.Now().UTC().Unix())
-	filePath := fmt.Sprintf("./%s", fileName)
-	filePath = filepath.Clean(filePath)
-
-	err := os.WriteFile(filePath, []byte(helpers.DefaultYAML()), 0600)
-	if err != nil {
-		t.Error(err)
-	}
-	defer func(name string) {
-		err := os.Remove(name)
-		if err != nil {
-			t.Error(err)
-		}
-	}(filePath)
+	filePath := writeModule(t)
 
 	originalReadModule := readModuleFromFlagsFunc
 	readModuleFromFlagsFunc = func(cmd *cobra.Command) (*module.Module, error) {
@@ -74,25 +75,12 @@ func Test_build_IsValid(t *testing.T) {
 	}()
 
 	cmd := NewBuildCommand()
-	err = cmd.Execute()
+	err := cmd.Execute()
 	require.Error(t, err)
 }
 
 func Test_build_success(t *testing.T) {
-	fileName := fmt.Sprintf("mod-%d.yaml", time.Now().UTC().Unix())
-	filePath := fmt.Sprintf("./%s", fileName)
-	filePath = filepath.Clean(filePath)
-
-	err := os.WriteFile(filePath, []byte(helpers.DefaultYAML()), 0600)
-	if err != nil {
-		t.Error(err)
-	}
-	defer func(name string) {
-		err := os.Remove(name)
-		if err != nil {
-			t.Error(err)
-		}
-	}(filePath)
+	filePath := writeModule(t)
 
 	originalReadModule := readModuleFromFlagsFunc
 	originalBuilder := builderFunc
@@ -115,25 +103,12 @@ func Test_build_success(t *testing.T) {
 
 	cmd := NewBuildCommand()
 	cmd.SetArgs([]string{"--last", "--description", "some description"})
-	err = cmd.Execute()
+	err := cmd.Execute()
 	require.NoError(t, err)
 }
 
 func Test_build_fail(t *testing.T) {
-	fileName := fmt.Sprintf("mod-%d.yaml", time.Now().UTC().Unix())
-	filePath := fmt.Sprintf("./%s", fileName)
-	filePath = filepath.Clean(filePath)
-
-	err := os.WriteFile(filePath, []byte(helpers.DefaultYAML()), 0600)
-	if err != nil {
-		t.Error(err)
-	}
-	defer func(name string) {
-		err := os.Remove(name)
-		if err != nil {
-			t.Error(err)
-		}
-	}(filePath)
+	filePath := writeModule(t)
 
 	originalReadModule := readModuleFromFlagsFunc
 	originalBuilder := builderFunc
@@ -155,25 +130,12 @@ func Test_build_fail(t *testing.T) {
 	}()
 
 	cmd := NewBuildCommand()
-	err = cmd.Execute()
+	err := cmd.Execute()
 	require.Error(t, err)
 }
 
 func Test_build_invalid_version(t *testing.T) {
-	fileName := fmt.Sprintf("mod-%d.yaml", time.Now().UTC().Unix())
-	filePath := fmt.Sprintf("./%s", fileName)
-	filePath = filepath.Clean(filePath)
-
-	err := os.WriteFile(filePath, []byte(helpers.DefaultYAML()), 0600)
-	if err != nil {
-		t.Error(err)
-	}
-	defer func(name string) {
-		err := os.Remove(name)
-		if err != nil {
-			t.Error(err)
-		}
-	}(filePath)
+	filePath := writeModule(t)
 
 	originalReadModule := readModuleFromFlagsFunc
 	originalBuilder := builderFunc
@@ -196,25 +158,12 @@ func Test_build_invalid_version(t *testing.T) {
 
 	cmd := NewBuildCommand()
 	cmd.SetArgs([]string{"--version", " invalid module version "})
-	err = cmd.Execute()
+	err := cmd.Execute()
 	require.Error(t, err)
 }
 
 func Test_build_valid_version(t *testing.T) {
-	fileName := fmt.Sprintf("mod-%d.yaml", time.Now().UTC().Unix())
-	filePath := fmt.Sprintf("./%s", fileName)
-	filePath = filepath.Clean(filePath)
-
-	err := os.WriteFile(filePath, []byte(helpers.DefaultYAML()), 0600)
-	if err != nil {
-		t.Error(err)
-	}
-	defer func(name string) {
-		err := os.Remove(name)
-		if err != nil {
-			t.Error(err)
-		}
-	}(filePath)
+	filePath := writeModule(t)
 
 	originalReadModule := readModuleFromFlagsFunc
 	originalBuilder := builderFunc
@@ -237,25 +186,12 @@ func Test_build_valid_version(t *testing.T) {
 
 	cmd := NewBuildCommand()
 	cmd.SetArgs([]string{"--version", "1.0.0"})
-	err = cmd.Execute()
+	err := cmd.Execute()
 	require.Error(t, err)
 }
 
 func Test_build_repository(t *testing.T) {
-	fileName := fmt.Sprintf("mod-%d.yaml", time.Now().UTC().Unix())
-	filePath := fmt.Sprintf("./%s", fileName)
-	filePath = filepath.Clean(filePath)
-
-	err := os.WriteFile(filePath, []byte(helpers.DefaultYAML()), 0600)
-	if err != nil {
-		t.Error(err)
-	}
-	defer func(name string) {
-		err := os.Remove(name)
-		if err != nil {
-			t.Error(err)
-		}
-	}(filePath)
+	filePath := writeModule(t)
 
 	originalReadModule := readModuleFromFlagsFunc
 	originalBuilder := builderFunc
@@ -278,25 +214,12 @@ func Test_build_repository(t *testing.T) {
 
 	cmd := NewBuildCommand()
 	cmd.SetArgs([]string{"--repository", "."})
-	err = cmd.Execute()
+	err := cmd.Execute()
 	require.Error(t, err)
 }
 
 func Test_build_invalid_last(t *testing.T) {
-	fileName := fmt.Sprintf("mod-%d.yaml", time.Now().UTC().Unix())
-	filePath := fmt.Sprintf("./%s", fileName)
-	filePath = filepath.Clean(filePath)
-
-	err := os.WriteFile(filePath, []byte(helpers.DefaultYAML()), 0600)
-	if err != nil {
-		t.Error(err)
-	}
-	defer func(name string) {
-		err := os.Remove(name)
-		if err != nil {
-			t.Error(err)
-		}
-	}(filePath)
+	filePath := writeModule(t)
 
 	originalReadModule := readModuleFromFlagsFunc
 	originalBuilder := builderFunc
@@ -327,26 +250,11 @@ func Test_build_invalid_last(t *testing.T) {
 
 	cmd := NewBuildCommand()
 	cmd.SetArgs([]string{"--last", "."})
-	err = cmd.Execute()
+	err := cmd.Execute()
 	require.Error(t, err)
 }
 
 func Test_build_read_module_error(t *testing.T) {
-	fileName := fmt.Sprintf("mod-%d.yaml", time.Now().UTC().Unix())
-	filePath := fmt.Sprintf("./%s", fileName)
-	filePath = filepath.Clean(filePath)
-
-	err := os.WriteFile(filePath, []byte(helpers.DefaultYAML()), 0600)
-	if err != nil {
-		t.Error(err)
-	}
-	defer func(name string) {
-		err := os.Remove(name)
-		if err != nil {
-			t.Error(err)
-		}
-	}(filePath)
-
 	originalReadModule := readModuleFromFlagsFunc
 
 	readModuleFromFlagsFunc = func(cmd *cobra.Command) (*module.Module, error) {
@@ -357,6 +265,6 @@ func Test_build_read_module_error(t *testing.T) {
 	}()
 
 	cmd := NewBuildCommand()
-	err = cmd.Execute()
+	err := cmd.Execute()
 	require.Error(t, err)
 }
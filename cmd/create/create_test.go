@@ -50,7 +50,7 @@ func Test_create_empty_name(t *testing.T) {
 
 func Test_create_not_empty_name(t *testing.T) {
 	cmd := &cobra.Command{}
-	cmd.SetContext(context.WithValue(context.Background(), helpers.RootDir, "."))
+	cmd.SetContext(context.WithValue(context.Background(), helpers.RootDir, t.TempDir()))
 	cmd.SetArgs([]string{"--name", "test-module"})
 
 	origModuleInputNameFunc := moduleNameInputFunc
@@ -69,7 +69,7 @@ func Test_create_not_empty_name(t *testing.T) {
 
 func Test_create_not_empty_invalid_name(t *testing.T) {
 	cmd := &cobra.Command{}
-	cmd.SetContext(context.WithValue(context.Background(), helpers.RootDir, "."))
+	cmd.SetContext(context.WithValue(context.Background(), helpers.RootDir, t.TempDir()))
 	cmd.SetArgs([]string{"--name", "test-module"})
 
 	origModuleInputNameFunc := moduleNameInputFunc
@@ -87,34 +87,22 @@ func Test_create_not_empty_invalid_name(t *testing.T) {
 }
 
 func Test_create_success(t *testing.T) {
+	dir := t.TempDir()
 	moduleName := fmt.Sprintf("mod-%d", time.Now().UTC().Unix())
-	defer func() {
-		err := os.Remove(fmt.Sprintf("./%s.yaml", moduleName))
-		if err != nil {
-			return
-		}
-	}()
 
 	cmd := NewCreateCommand()
-	cmd.SetContext(context.WithValue(context.Background(), helpers.RootDir, "."))
+	cmd.SetContext(context.WithValue(context.Background(), helpers.RootDir, dir))
 	cmd.SetArgs([]string{"--name", moduleName})
 
 	err := cmd.Execute()
 	require.NoError(t, err)
+	assert.FileExists(t, filepath.Join(dir, moduleName+".yaml"))
 }
 
 func Test_create_module_exists(t *testing.T) {
+	dir := t.TempDir()
 	moduleName := fmt.Sprintf("mod-%d", time.Now().UTC().Unix())
-	fileName := fmt.Sprintf("%s.yaml", moduleName)
-	filePath := fmt.Sprintf("./%s", fileName)
-	filePath = filepath.Clean(filePath)
-
-	defer func() {
-		err := os.Remove(filePath)
-		if err != nil {
-			t.Error(err)
-		}
-	}()
+	filePath := filepath.Join(dir, moduleName+".yaml")
 
 	err := os.WriteFile(filePath, []byte(helpers.DefaultYAML()), 0600)
 	if err != nil {
@@ -122,7 +110,7 @@ func Test_create_module_exists(t *testing.T) {
 	}
 
 	cmd := NewCreateCommand()
-	cmd.SetContext(context.WithValue(context.Background(), helpers.RootDir, "."))
+	cmd.SetContext(context.WithValue(context.Background(), helpers.RootDir, dir))
 	cmd.SetArgs([]string{"--name", moduleName})
 
 	err = cmd.Execute()
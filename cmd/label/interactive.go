@@ -0,0 +1,292 @@
+package label
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/pixel365/bx/internal/helpers"
+	"github.com/pixel365/bx/internal/labels"
+	"github.com/pixel365/bx/internal/types"
+)
+
+var labelOrder = []types.VersionLabel{types.Alpha, types.Beta, types.Stable}
+
+var (
+	titleStyle   = lipgloss.NewStyle().Bold(true)
+	cursorStyle  = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("212"))
+	changedStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("214"))
+	helpStyle    = lipgloss.NewStyle().Faint(true)
+)
+
+const (
+	listHelp = "↑/↓ move • ←/→ change label • a/b/s set label • r reset • o sort order • enter preview • q quit"
+	diffHelp = "y/enter submit • n/esc back • q quit"
+	// chromeLines is the number of lines around the list: the title, the blank lines and the help.
+	chromeLines = 5
+)
+
+// labelRow is a version in the interactive editor.
+type labelRow struct {
+	version  string
+	original types.VersionLabel
+	label    types.VersionLabel
+}
+
+// labelModel is the interactive label editor of `bx label -i`: a list of versions
+// with their labels, changed with the keyboard, and a preview of the changes before they are submitted.
+type labelModel struct {
+	name      string
+	sorting   types.SortingType
+	rows      []labelRow
+	cursor    int
+	offset    int
+	height    int
+	preview   bool
+	submitted bool
+}
+
+func newLabelModel(name string, versions types.Versions) *labelModel {
+	m := &labelModel{name: name, sorting: types.Desc}
+
+	for _, version := range helpers.SortSemanticVersions(maps.Keys(versions)) {
+		m.rows = append(m.rows, labelRow{version: version, original: versions[version], label: versions[version]})
+	}
+	slices.Reverse(m.rows)
+
+	return m
+}
+
+func (m *labelModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m *labelModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.height = msg.Height
+		m.scroll()
+	case tea.KeyMsg:
+		if msg.String() == "ctrl+c" || msg.String() == "q" {
+			return m, tea.Quit
+		}
+		if m.preview {
+			return m.updatePreview(msg)
+		}
+		return m.updateList(msg)
+	}
+
+	return m, nil
+}
+
+func (m *labelModel) updateList(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		return m, tea.Quit
+	case "up", "k":
+		m.cursor = max(m.cursor-1, 0)
+	case "down", "j":
+		m.cursor = min(m.cursor+1, len(m.rows)-1)
+	case "home", "g":
+		m.cursor = 0
+	case "end", "G":
+		m.cursor = len(m.rows) - 1
+	case "left", "h":
+		m.shift(-1)
+	case "right", "l", " ":
+		m.shift(1)
+	case "a":
+		m.set(types.Alpha)
+	case "b":
+		m.set(types.Beta)
+	case "s":
+		m.set(types.Stable)
+	case "r":
+		if len(m.rows) > 0 {
+			m.rows[m.cursor].label = m.rows[m.cursor].original
+		}
+	case "o":
+		m.toggleSorting()
+	case "enter":
+		if len(m.changes()) > 0 {
+			m.preview = true
+		}
+	}
+
+	m.scroll()
+
+	return m, nil
+}
+
+func (m *labelModel) updatePreview(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "y", "enter":
+		m.submitted = true
+		return m, tea.Quit
+	case "n", "esc":
+		m.preview = false
+	}
+
+	return m, nil
+}
+
+// shift moves the label of the current version by delta positions in alpha, beta, stable.
+func (m *labelModel) shift(delta int) {
+	if len(m.rows) == 0 {
+		return
+	}
+
+	row := &m.rows[m.cursor]
+	index := slices.Index(labelOrder, row.label)
+	if index < 0 {
+		index = 0
+	} else {
+		index = (index + delta + len(labelOrder)) % len(labelOrder)
+	}
+
+	row.label = labelOrder[index]
+}
+
+func (m *labelModel) set(label types.VersionLabel) {
+	if len(m.rows) > 0 {
+		m.rows[m.cursor].label = label
+	}
+}
+
+// toggleSorting reverses the order of the list and keeps the cursor on the same version.
+func (m *labelModel) toggleSorting() {
+	slices.Reverse(m.rows)
+	if len(m.rows) > 0 {
+		m.cursor = len(m.rows) - 1 - m.cursor
+	}
+
+	if m.sorting == types.Desc {
+		m.sorting = types.Asc
+	} else {
+		m.sorting = types.Desc
+	}
+}
+
+// scroll keeps the cursor within the visible part of the list.
+func (m *labelModel) scroll() {
+	visible := m.visibleRows()
+
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+visible {
+		m.offset = m.cursor - visible + 1
+	}
+	m.offset = max(min(m.offset, len(m.rows)-visible), 0)
+}
+
+func (m *labelModel) visibleRows() int {
+	if m.height <= 0 {
+		return len(m.rows)
+	}
+
+	return max(m.height-chromeLines, 1)
+}
+
+// changes returns the changed labels in semver order.
+func (m *labelModel) changes() []labels.Change {
+	var changes []labels.Change
+	for _, row := range m.rows {
+		if row.label != row.original {
+			changes = append(changes, labels.Change{Version: row.version, From: row.original, To: row.label})
+		}
+	}
+
+	slices.SortFunc(changes, func(a, b labels.Change) int {
+		return helpers.CompareSemanticVersions(a.Version, b.Version)
+	})
+
+	return changes
+}
+
+func (m *labelModel) View() string {
+	var b strings.Builder
+
+	if m.preview {
+		changes := m.changes()
+		b.WriteString(titleStyle.Render(fmt.Sprintf("Label changes of %s (%d)", m.name, len(changes))))
+		b.WriteString("\n\n")
+		_ = labels.WriteDiff(&b, changes)
+		b.WriteString("\n")
+		b.WriteString(helpStyle.Render(diffHelp))
+		b.WriteString("\n")
+		return b.String()
+	}
+
+	b.WriteString(titleStyle.Render(fmt.Sprintf("%s: %d versions, %s", m.name, len(m.rows), m.sorting)))
+	if n := len(m.changes()); n > 0 {
+		b.WriteString(changedStyle.Render(fmt.Sprintf(" • %d changed", n)))
+	}
+	b.WriteString("\n\n")
+
+	if len(m.rows) == 0 {
+		b.WriteString("No versions\n")
+	}
+
+	end := min(m.offset+m.visibleRows(), len(m.rows))
+	width := 0
+	for _, row := range m.rows {
+		width = max(width, len(row.version))
+	}
+
+	for i := m.offset; i < end; i++ {
+		m.writeRow(&b, i, width)
+	}
+
+	b.WriteString("\n")
+	b.WriteString(helpStyle.Render(listHelp))
+	b.WriteString("\n")
+
+	return b.String()
+}
+
+func (m *labelModel) writeRow(w io.StringWriter, i, width int) {
+	row := m.rows[i]
+
+	cursor := "  "
+	if i == m.cursor {
+		cursor = cursorStyle.Render("> ")
+	}
+
+	options := make([]string, 0, len(labelOrder))
+	for _, label := range labelOrder {
+		mark := "( )"
+		if label == row.label {
+			mark = "(•)"
+		}
+		options = append(options, mark+" "+string(label))
+	}
+
+	line := fmt.Sprintf("%-*s  %s", width, row.version, strings.Join(options, "  "))
+	if row.label != row.original {
+		line = changedStyle.Render(line + "  *")
+	}
+
+	_, _ = w.WriteString(cursor + line + "\n")
+}
+
+// runInteractive shows the interactive editor and returns the changes confirmed by the user,
+// or nil if the user quit without submitting.
+func runInteractive(name string, versions types.Versions, in io.Reader, out io.Writer) ([]labels.Change, error) {
+	model := newLabelModel(name, versions)
+
+	if _, err := tea.NewProgram(model, tea.WithInput(in), tea.WithOutput(out), tea.WithAltScreen()).Run(); err != nil {
+		return nil, err
+	}
+
+	if !model.submitted {
+		return nil, nil
+	}
+
+	return model.changes(), nil
+}
@@ -0,0 +1,240 @@
+package label
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pixel365/bx/internal/client"
+	errors2 "github.com/pixel365/bx/internal/errors"
+	"github.com/pixel365/bx/internal/labels"
+	"github.com/pixel365/bx/internal/module"
+	"github.com/pixel365/bx/internal/portaltest"
+	"github.com/pixel365/bx/internal/types"
+)
+
+func keys(m *labelModel, input ...string) tea.Cmd {
+	var cmd tea.Cmd
+
+	for _, k := range input {
+		var msg tea.KeyMsg
+		switch k {
+		case "up":
+			msg = tea.KeyMsg{Type: tea.KeyUp}
+		case "down":
+			msg = tea.KeyMsg{Type: tea.KeyDown}
+		case "left":
+			msg = tea.KeyMsg{Type: tea.KeyLeft}
+		case "right":
+			msg = tea.KeyMsg{Type: tea.KeyRight}
+		case "enter":
+			msg = tea.KeyMsg{Type: tea.KeyEnter}
+		case "esc":
+			msg = tea.KeyMsg{Type: tea.KeyEsc}
+		default:
+			msg = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+		}
+		_, cmd = m.Update(msg)
+	}
+
+	return cmd
+}
+
+func isQuit(cmd tea.Cmd) bool {
+	if cmd == nil {
+		return false
+	}
+	_, ok := cmd().(tea.QuitMsg)
+	return ok
+}
+
+var interactiveVersions = types.Versions{
+	"1.0.0": types.Stable,
+	"1.1.0": types.Beta,
+	"1.2.0": types.Alpha,
+}
+
+func Test_labelModel_edit(t *testing.T) {
+	t.Parallel()
+
+	m := newLabelModel("vendor.module", interactiveVersions)
+	assert.Equal(t, []string{"1.2.0", "1.1.0", "1.0.0"}, rowVersions(m))
+
+	keys(m, "right", "down", "s", "down", "down", "left")
+	assert.Equal(t, []labels.Change{
+		{Version: "1.0.0", From: types.Stable, To: types.Beta},
+		{Version: "1.1.0", From: types.Beta, To: types.Stable},
+		{Version: "1.2.0", From: types.Alpha, To: types.Beta},
+	}, m.changes())
+
+	keys(m, "r", "up", "up", "l", "l")
+	assert.Equal(t, []labels.Change{
+		{Version: "1.1.0", From: types.Beta, To: types.Stable},
+	}, m.changes(), "reset and a full cycle of labels leave no change")
+	assert.Contains(t, m.View(), "1 changed")
+}
+
+func Test_labelModel_sorting(t *testing.T) {
+	t.Parallel()
+
+	m := newLabelModel("vendor.module", interactiveVersions)
+	keys(m, "down", "o")
+
+	assert.Equal(t, types.Asc, m.sorting)
+	assert.Equal(t, []string{"1.0.0", "1.1.0", "1.2.0"}, rowVersions(m))
+	assert.Equal(t, "1.1.0", m.rows[m.cursor].version, "the cursor stays on the version")
+
+	keys(m, "o")
+	assert.Equal(t, types.Desc, m.sorting)
+	assert.Equal(t, "1.1.0", m.rows[m.cursor].version)
+}
+
+func Test_labelModel_preview(t *testing.T) {
+	t.Parallel()
+
+	m := newLabelModel("vendor.module", interactiveVersions)
+
+	keys(m, "enter")
+	assert.False(t, m.preview, "nothing to preview")
+
+	keys(m, "b", "enter")
+	require.True(t, m.preview)
+	assert.Contains(t, m.View(), "1.2.0  alpha  -> beta")
+
+	keys(m, "n")
+	assert.False(t, m.preview)
+
+	cmd := keys(m, "enter", "y")
+	assert.True(t, m.submitted)
+	assert.True(t, isQuit(cmd))
+}
+
+func Test_labelModel_quit(t *testing.T) {
+	t.Parallel()
+
+	m := newLabelModel("vendor.module", interactiveVersions)
+	cmd := keys(m, "b", "q")
+	assert.True(t, isQuit(cmd))
+	assert.False(t, m.submitted)
+
+	m = newLabelModel("vendor.module", interactiveVersions)
+	assert.True(t, isQuit(keys(m, "esc")))
+}
+
+func Test_labelModel_scroll(t *testing.T) {
+	t.Parallel()
+
+	versions := make(types.Versions)
+	for _, v := range []string{"1.0.0", "1.0.1", "1.0.2", "1.0.3", "1.0.4", "1.0.5", "1.0.6", "1.0.7"} {
+		versions[v] = types.Alpha
+	}
+
+	m := newLabelModel("vendor.module", versions)
+	m.Update(tea.WindowSizeMsg{Width: 80, Height: chromeLines + 3})
+
+	keys(m, "down", "down", "down", "down")
+	assert.Equal(t, 4, m.cursor)
+	assert.Equal(t, 2, m.offset)
+
+	view := m.View()
+	assert.Contains(t, view, "1.0.3")
+	assert.NotContains(t, view, "1.0.7")
+	assert.NotContains(t, view, "1.0.0")
+
+	keys(m, "g")
+	assert.Equal(t, 0, m.offset)
+	keys(m, "G")
+	assert.Equal(t, 5, m.offset)
+}
+
+func Test_labelModel_empty(t *testing.T) {
+	t.Parallel()
+
+	m := newLabelModel("vendor.module", types.Versions{})
+	keys(m, "down", "right", "s", "r", "o", "enter")
+
+	assert.Empty(t, m.changes())
+	assert.Contains(t, m.View(), "No versions")
+}
+
+func Test_runInteractive(t *testing.T) {
+	t.Parallel()
+
+	changes, err := runInteractive("vendor.module", interactiveVersions, strings.NewReader("s\ry"), io.Discard)
+	require.NoError(t, err)
+	assert.Equal(t, []labels.Change{{Version: "1.2.0", From: types.Alpha, To: types.Stable}}, changes)
+
+	changes, err = runInteractive("vendor.module", interactiveVersions, strings.NewReader("s\x03"), io.Discard)
+	require.NoError(t, err)
+	assert.Nil(t, changes)
+}
+
+func TestLabelCommand_interactive(t *testing.T) {
+	portal := portaltest.NewPortal()
+	defer portal.Close()
+
+	portal.AddAccount("partner", "secret")
+	portal.AddModule("partner", "vendor.module", interactiveVersions)
+
+	originalReadModule := readModuleFromFlagsFunc
+	originalRunInteractive := runInteractiveFunc
+	originalChangeLabels := changeLabelsFunc
+	readModuleFromFlagsFunc = func(cmd *cobra.Command) (*module.Module, error) {
+		return &module.Module{Name: "vendor.module", Account: "partner", Portal: portal.URL()}, nil
+	}
+	defer func() {
+		readModuleFromFlagsFunc = originalReadModule
+		runInteractiveFunc = originalRunInteractive
+		changeLabelsFunc = originalChangeLabels
+	}()
+
+	var shown types.Versions
+	runInteractiveFunc = func(name string, versions types.Versions, _ io.Reader, _ io.Writer) ([]labels.Change, error) {
+		shown = versions
+		return []labels.Change{
+			{Version: "1.1.0", From: types.Beta, To: types.Stable},
+			{Version: "1.2.0", From: types.Alpha, To: types.Beta},
+		}, nil
+	}
+
+	calls := 0
+	changeLabelsFunc = func(c client.HTTPClient, m *module.Module, cookies []*http.Cookie, v types.Versions) error {
+		calls++
+		return originalChangeLabels(c, m, cookies, v)
+	}
+
+	var out bytes.Buffer
+	cmd := NewLabelCommand()
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"-i", "--password", "secret"})
+	require.NoError(t, cmd.Execute())
+
+	assert.Equal(t, interactiveVersions, shown)
+	assert.Equal(t, 1, calls, "the changes are submitted in one request")
+	assert.Equal(t, types.Versions{"1.0.0": types.Stable, "1.1.0": types.Stable, "1.2.0": types.Beta},
+		portal.Versions("vendor.module"))
+	assert.Contains(t, out.String(), "1.1.0  beta   -> stable")
+
+	cmd = NewLabelCommand()
+	cmd.SetArgs([]string{"-i", "stable"})
+	require.ErrorIs(t, cmd.Execute(), errors2.ErrInvalidArgument)
+
+	cmd = NewLabelCommand()
+	cmd.SetArgs([]string{"-i", "--password-stdin"})
+	require.ErrorIs(t, cmd.Execute(), errors2.ErrInvalidArgument)
+}
+
+func rowVersions(m *labelModel) []string {
+	versions := make([]string, 0, len(m.rows))
+	for _, row := range m.rows {
+		versions = append(versions, row.version)
+	}
+	return versions
+}
@@ -2,14 +2,20 @@ package label
 
 import (
 	"errors"
-	"time"
+	"fmt"
+	"net/http"
 
+	"github.com/pixel365/bx/internal/callback"
 	"github.com/pixel365/bx/internal/client"
+	"github.com/pixel365/bx/internal/helpers"
+	"github.com/pixel365/bx/internal/interfaces"
+	"github.com/pixel365/bx/internal/labels"
 	"github.com/pixel365/bx/internal/request"
 
 	errors2 "github.com/pixel365/bx/internal/errors"
 
 	"github.com/pixel365/bx/internal/auth"
+	"github.com/pixel365/bx/internal/logger"
 
 	"github.com/spf13/cobra"
 
@@ -19,10 +25,13 @@ import (
 
 var (
 	readModuleFromFlagsFunc = module.ReadModuleFromFlags
-	authFunc                = auth.Authenticate
+	authFunc                = auth.Login
 	inputPasswordFunc       = auth.InputPassword
 	changeLabelsFunc        = request.ChangeLabels
-	newClientFunc           = client.NewClient
+	versionsFunc            = request.Versions
+	newClientFunc           = client.NewPortalClient
+	confirmFunc             = helpers.Confirm
+	runInteractiveFunc      = runInteractive
 )
 
 func NewLabelCommand() *cobra.Command {
@@ -32,57 +41,240 @@ func NewLabelCommand() *cobra.Command {
 		Example: `
 # Change module label
 bx label stable
+
+# Change the label of several versions and ranges
+bx label beta --versions 2.0.3,2.1.0-2.1.4
+
+# Mark all versions below 2.0.0 as stable
+bx label --all-below 2.0.0 --label stable
+
+# Browse the versions and edit the labels interactively
+bx label -i
 `,
 		RunE: label,
 	}
 
 	cmd.Flags().StringP("name", "n", "", "Name of the module")
 	cmd.Flags().StringP("version", "v", "", "Version of the module")
+	cmd.Flags().StringSlice("versions", nil, "Versions and ranges, e.g. 2.0.3,2.1.0-2.1.4")
+	cmd.Flags().String("all-below", "", "Select all versions lower than the version")
+	cmd.Flags().StringP("label", "l", "", "Version label, instead of the argument")
+	cmd.Flags().BoolP("yes", "y", false, "Do not ask for confirmation")
+	cmd.Flags().BoolP("interactive", "i", false, "Browse the versions and edit the labels interactively")
 	cmd.Flags().StringP("file", "f", "", "Path to a module")
 	cmd.Flags().StringP("password", "p", "", "Account password")
+	cmd.Flags().Bool("password-stdin", false, "Read the account password from stdin")
 	cmd.Flags().BoolP("silent", "s", false, "Silent mode")
+	cmd.Flags().Duration("timeout", 0, "Timeout of a portal request (default 30s)")
+	cmd.Flags().Int("retries", types.DefaultRetries, "Retries of idempotent portal requests")
 
 	return cmd
 }
 
+// label sets a label on the version of the module, or on the versions selected
+// by the --versions and --all-below flags.
+//
+// The versions are read from the portal first, and only the versions whose label differs
+// are changed. A batch change is printed as a diff and submitted after confirmation,
+// unless the --yes flag is set. With the --interactive flag, the labels are edited
+// in the interactive editor instead (see labelInteractive).
 func label(cmd *cobra.Command, _ []string) error {
-	if len(cmd.Flags().Args()) != 1 {
-		return errors.New("label is required")
+	if interactive, _ := cmd.Flags().GetBool("interactive"); interactive {
+		return labelInteractive(cmd)
+	}
+
+	l, err := readLabel(cmd)
+	if err != nil {
+		return err
+	}
+
+	specs, _ := cmd.Flags().GetStringSlice("versions")
+	below, _ := cmd.Flags().GetString("all-below")
+	batch := len(specs) > 0 || below != ""
+
+	s, err := login(cmd)
+	if err != nil {
+		return err
+	}
+
+	if !batch {
+		if s.module.Version == "" {
+			return errors2.ErrEmptyVersion
+		}
+		specs = []string{s.module.Version}
+	}
+
+	selected, err := labels.Select(s.versions, specs, below)
+	if err != nil {
+		return err
+	}
+
+	return s.apply(cmd, labels.Plan(s.versions, selected, l), batch)
+}
+
+// labelInteractive shows the versions of the module in the interactive editor and submits
+// the confirmed changes in a single request.
+func labelInteractive(cmd *cobra.Command) error {
+	flag, _ := cmd.Flags().GetString("label")
+	specs, _ := cmd.Flags().GetStringSlice("versions")
+	below, _ := cmd.Flags().GetString("all-below")
+	if len(cmd.Flags().Args()) > 0 || flag != "" || len(specs) > 0 || below != "" {
+		return fmt.Errorf("%w: labels and versions are chosen in the interactive mode",
+			errors2.ErrInvalidArgument)
+	}
+
+	if fromStdin, _ := cmd.Flags().GetBool("password-stdin"); fromStdin {
+		return fmt.Errorf("%w: stdin is used by the interactive mode, --password-stdin is not supported",
+			errors2.ErrInvalidArgument)
+	}
+
+	s, err := login(cmd)
+	if err != nil {
+		return err
+	}
+
+	changes, err := runInteractiveFunc(s.module.Name, s.versions, cmd.InOrStdin(), cmd.OutOrStdout())
+	if err != nil {
+		return err
+	}
+
+	if len(changes) == 0 {
+		if !s.silent {
+			_, _ = fmt.Fprintln(cmd.OutOrStdout(), "No label changes")
+		}
+		return nil
+	}
+
+	if err := s.submit(cmd, changes); err != nil {
+		return err
+	}
+
+	if !s.silent {
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Changed the labels of %s:\n", s.module.Name)
+		return labels.WriteDiff(cmd.OutOrStdout(), changes)
+	}
+
+	return nil
+}
+
+// readLabel returns the label from the argument or the --label flag.
+func readLabel(cmd *cobra.Command) (types.VersionLabel, error) {
+	args := cmd.Flags().Args()
+	flag, _ := cmd.Flags().GetString("label")
+
+	var value string
+	switch {
+	case len(args) == 1 && flag == "":
+		value = args[0]
+	case len(args) == 0 && flag != "":
+		value = flag
+	default:
+		return "", errors.New("label is required")
 	}
 
-	l := types.VersionLabel(cmd.Flags().Args()[0])
+	l := types.VersionLabel(value)
 	switch l {
 	case types.Alpha, types.Beta, types.Stable:
+		return l, nil
 	default:
-		return errors2.ErrInvalidLabel
+		return "", errors2.ErrInvalidLabel
 	}
+}
+
+// labelSession is an authenticated session with the versions of the module read from the portal.
+type labelSession struct {
+	client   client.HTTPClient
+	log      interfaces.Logger
+	module   *module.Module
+	versions types.Versions
+	cookies  []*http.Cookie
+	silent   bool
+}
 
+// login reads the module, authenticates and reads the versions of the module.
+func login(cmd *cobra.Command) (*labelSession, error) {
 	mod, err := readModuleFromFlagsFunc(cmd)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	password, err := inputPasswordFunc(cmd, mod)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	silent, _ := cmd.Flags().GetBool("silent")
 
-	httpClient := newClientFunc(10 * time.Second)
+	log := logger.NewFileLogger(mod.Log, mod.Name)
+	httpClient := newClientFunc(mod.HTTP, log)
 
 	cookies, err := authFunc(httpClient, mod, password, silent)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	versions := make(types.Versions, 1)
-	versions[mod.Version] = l
-
-	err = changeLabelsFunc(httpClient, mod, cookies, versions)
+	versions, err := versionsFunc(cmd.Context(), httpClient, mod, cookies)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return nil
+	return &labelSession{
+		client:   httpClient,
+		log:      log,
+		module:   mod,
+		versions: versions,
+		cookies:  cookies,
+		silent:   silent,
+	}, nil
+}
+
+// apply prints the changes, asks for confirmation if needed, and submits them.
+//
+// Parameters:
+//   - cmd: The command; its output receives the diff, and its --yes flag skips the confirmation.
+//   - changes: The label changes.
+//   - confirm: Whether the changes need confirmation.
+func (s *labelSession) apply(cmd *cobra.Command, changes []labels.Change, confirm bool) error {
+	out := cmd.OutOrStdout()
+
+	if len(changes) == 0 {
+		if !s.silent {
+			_, _ = fmt.Fprintln(out, "No label changes")
+		}
+		return nil
+	}
+
+	yes, _ := cmd.Flags().GetBool("yes")
+	confirm = confirm && !yes
+
+	if !s.silent || confirm {
+		_, _ = fmt.Fprintf(out, "Label changes of %s:\n", s.module.Name)
+		if err := labels.WriteDiff(out, changes); err != nil {
+			return err
+		}
+	}
+
+	if confirm {
+		ok, err := confirmFunc(fmt.Sprintf("Change the labels of %d version(s)?", len(changes)))
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return errors2.ErrCanceled
+		}
+	}
+
+	return s.submit(cmd, changes)
+}
+
+// submit changes the labels and runs the afterLabel hook of the module with the outcome.
+// A failure of the hook is reported as a warning and does not change the result.
+func (s *labelSession) submit(cmd *cobra.Command, changes []labels.Change) error {
+	err := changeLabelsFunc(s.client, s.module, s.cookies, labels.Versions(changes))
+
+	if hookErr := s.module.RunFinalHook(cmd.Context(), callback.AfterLabel, err, s.log); hookErr != nil {
+		_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Warning: %v\n", hookErr)
+	}
+
+	return err
 }
@@ -1,23 +1,27 @@
 package label
 
 import (
+	"bytes"
 	"errors"
 	"net/http"
+	"net/http/httptest"
 	"testing"
-	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/pixel365/bx/internal/types"
 
+	"github.com/pixel365/bx/internal/callback"
 	"github.com/pixel365/bx/internal/client"
 
 	errors2 "github.com/pixel365/bx/internal/errors"
 
 	"github.com/spf13/cobra"
 
+	"github.com/pixel365/bx/internal/interfaces"
 	"github.com/pixel365/bx/internal/module"
+	"github.com/pixel365/bx/internal/portaltest"
 )
 
 func TestNewLabelCommand(t *testing.T) {
@@ -159,7 +163,7 @@ func TestLabelCommand_change_labels(t *testing.T) {
 		changeLabelsFunc = originalChangeLabelsFunc
 	}()
 
-	newClientFunc = func(ttl time.Duration) client.HTTPClient {
+	newClientFunc = func(policy *types.HTTP, logger interfaces.Logger) client.HTTPClient {
 		return &client.MockHttpClient{}
 	}
 	defer func() {
@@ -178,3 +182,192 @@ func TestLabelCommand_change_labels(t *testing.T) {
 	err := cmd.Execute()
 	require.Error(t, err)
 }
+
+func TestLabelCommand_portal(t *testing.T) {
+	portal := portaltest.NewPortal()
+	defer portal.Close()
+
+	portal.AddAccount("partner", "secret")
+	portal.AddModule("partner", "vendor.module", types.Versions{"1.0.0": types.Stable, "1.1.0": types.Alpha})
+
+	originalReadModule := readModuleFromFlagsFunc
+	readModuleFromFlagsFunc = func(cmd *cobra.Command) (*module.Module, error) {
+		return &module.Module{Name: "vendor.module", Account: "partner", Version: "1.1.0", Portal: portal.URL()}, nil
+	}
+	defer func() {
+		readModuleFromFlagsFunc = originalReadModule
+	}()
+
+	cmd := NewLabelCommand()
+	cmd.SetArgs([]string{"beta", "--password", "secret", "--silent"})
+	require.NoError(t, cmd.Execute())
+
+	assert.Equal(t, types.Versions{"1.0.0": types.Stable, "1.1.0": types.Beta}, portal.Versions("vendor.module"))
+}
+
+func TestLabelCommand_hooks(t *testing.T) {
+	portal := portaltest.NewPortal()
+	defer portal.Close()
+
+	portal.AddAccount("partner", "secret")
+	portal.AddModule("partner", "vendor.module", types.Versions{"1.0.0": types.Stable, "1.1.0": types.Alpha})
+
+	var hooks []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		hooks = append(hooks, r.Form.Get("hook")+":"+r.Form.Get("status"))
+	}))
+	defer server.Close()
+
+	mod := &module.Module{
+		Name:    "vendor.module",
+		Account: "partner",
+		Version: "1.1.0",
+		Portal:  portal.URL(),
+		Hooks: &callback.Hooks{
+			AfterLabel: []callback.CallbackParameters{
+				{Type: callback.ExternalType, Action: server.URL, Method: http.MethodPost},
+			},
+		},
+	}
+
+	originalReadModule := readModuleFromFlagsFunc
+	readModuleFromFlagsFunc = func(cmd *cobra.Command) (*module.Module, error) {
+		return mod, nil
+	}
+	defer func() {
+		readModuleFromFlagsFunc = originalReadModule
+	}()
+
+	cmd := NewLabelCommand()
+	cmd.SetArgs([]string{"beta", "--password", "secret", "--silent"})
+	require.NoError(t, cmd.Execute())
+	assert.Equal(t, []string{"afterLabel:success"}, hooks)
+
+	cmd = NewLabelCommand()
+	cmd.SetArgs([]string{"beta", "--password", "secret", "--silent"})
+	require.NoError(t, cmd.Execute())
+	assert.Len(t, hooks, 1, "the hook is not run without label changes")
+}
+
+func TestLabelCommand_batch(t *testing.T) {
+	portal := portaltest.NewPortal()
+	defer portal.Close()
+
+	portal.AddAccount("partner", "secret")
+	portal.AddModule("partner", "vendor.module", types.Versions{
+		"1.0.0": types.Beta,
+		"1.1.0": types.Alpha,
+		"2.1.0": types.Alpha,
+		"2.1.1": types.Beta,
+		"2.1.4": types.Alpha,
+		"2.2.0": types.Alpha,
+	})
+
+	originalReadModule := readModuleFromFlagsFunc
+	originalConfirmFunc := confirmFunc
+	readModuleFromFlagsFunc = func(cmd *cobra.Command) (*module.Module, error) {
+		return &module.Module{Name: "vendor.module", Account: "partner", Portal: portal.URL()}, nil
+	}
+	defer func() {
+		readModuleFromFlagsFunc = originalReadModule
+		confirmFunc = originalConfirmFunc
+	}()
+
+	var asked []string
+	answer := false
+	confirmFunc = func(title string) (bool, error) {
+		asked = append(asked, title)
+		return answer, nil
+	}
+
+	var out bytes.Buffer
+	cmd := NewLabelCommand()
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"beta", "--versions", "2.1.0-2.1.4", "--password", "secret"})
+	require.ErrorIs(t, cmd.Execute(), errors2.ErrCanceled)
+	assert.Len(t, asked, 1)
+	assert.Equal(t, types.Alpha, portal.Versions("vendor.module")["2.1.0"], "nothing is changed without confirmation")
+	assert.Contains(t, out.String(), "2.1.0  alpha  -> beta")
+	assert.Contains(t, out.String(), "2.1.4  alpha  -> beta")
+	assert.NotContains(t, out.String(), "2.1.1", "versions with the label are not changed")
+
+	answer = true
+	cmd = NewLabelCommand()
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"beta", "--versions", "2.1.0-2.1.4", "--password", "secret"})
+	require.NoError(t, cmd.Execute())
+
+	cmd = NewLabelCommand()
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"--all-below", "2.0.0", "--label", "stable", "--yes", "--password", "secret", "--silent"})
+	require.NoError(t, cmd.Execute())
+	assert.Len(t, asked, 2, "--yes skips the confirmation")
+
+	assert.Equal(t, types.Versions{
+		"1.0.0": types.Stable,
+		"1.1.0": types.Stable,
+		"2.1.0": types.Beta,
+		"2.1.1": types.Beta,
+		"2.1.4": types.Beta,
+		"2.2.0": types.Alpha,
+	}, portal.Versions("vendor.module"))
+
+	cmd = NewLabelCommand()
+	cmd.SetArgs([]string{"beta", "--versions", "3.0.0", "--password", "secret", "--silent"})
+	require.ErrorIs(t, cmd.Execute(), errors2.ErrVersionNotFound)
+}
+
+func TestPromoteCommand_portal(t *testing.T) {
+	portal := portaltest.NewPortal()
+	defer portal.Close()
+
+	portal.AddAccount("partner", "secret")
+	portal.AddModule("partner", "vendor.module", types.Versions{
+		"1.0.0": types.Stable,
+		"1.1.0": types.Beta,
+		"1.2.0": types.Alpha,
+		"1.3.0": types.Alpha,
+	})
+
+	mod := &module.Module{Name: "vendor.module", Account: "partner", Portal: portal.URL()}
+
+	originalReadModule := readModuleFromFlagsFunc
+	originalConfirmFunc := confirmFunc
+	readModuleFromFlagsFunc = func(cmd *cobra.Command) (*module.Module, error) {
+		return mod, nil
+	}
+	defer func() {
+		readModuleFromFlagsFunc = originalReadModule
+		confirmFunc = originalConfirmFunc
+	}()
+
+	confirmFunc = func(title string) (bool, error) {
+		return true, nil
+	}
+
+	var out bytes.Buffer
+	cmd := NewPromoteCommand()
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"--password", "secret"})
+	require.NoError(t, cmd.Execute())
+
+	assert.Equal(t, "Label changes of vendor.module:\n  1.1.0  beta   -> stable\n  1.3.0  alpha  -> beta\n", out.String())
+	assert.Equal(t, types.Versions{
+		"1.0.0": types.Stable,
+		"1.1.0": types.Stable,
+		"1.2.0": types.Alpha,
+		"1.3.0": types.Beta,
+	}, portal.Versions("vendor.module"))
+
+	mod.Promotion = []types.PromotionRule{{From: types.Alpha, To: types.Stable}}
+	cmd = NewPromoteCommand()
+	cmd.SetArgs([]string{"--password", "secret", "--silent", "--from", "alpha"})
+	require.NoError(t, cmd.Execute())
+	assert.Equal(t, types.Stable, portal.Versions("vendor.module")["1.2.0"], "the configured rule is applied")
+	assert.Equal(t, types.Beta, portal.Versions("vendor.module")["1.3.0"])
+
+	cmd = NewPromoteCommand()
+	cmd.SetArgs([]string{"--from", "gamma"})
+	require.ErrorIs(t, cmd.Execute(), errors2.ErrInvalidLabel)
+}
@@ -0,0 +1,56 @@
+package label
+
+import (
+	"github.com/spf13/cobra"
+
+	errors2 "github.com/pixel365/bx/internal/errors"
+	"github.com/pixel365/bx/internal/labels"
+	"github.com/pixel365/bx/internal/types"
+)
+
+func NewPromoteCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "promote",
+		Short: "Promote the newest module versions to the next label",
+		Example: `
+# Promote the newest alpha to beta and the newest beta to stable
+bx promote --name my_module
+
+# Promote only the newest beta
+bx promote --name my_module --from beta
+`,
+		RunE: promote,
+	}
+
+	cmd.Flags().StringP("name", "n", "", "Name of the module")
+	cmd.Flags().StringP("file", "f", "", "Path to a module")
+	cmd.Flags().String("from", "", "Apply only the rule for the label")
+	cmd.Flags().BoolP("yes", "y", false, "Do not ask for confirmation")
+	cmd.Flags().StringP("password", "p", "", "Account password")
+	cmd.Flags().Bool("password-stdin", false, "Read the account password from stdin")
+	cmd.Flags().BoolP("silent", "s", false, "Silent mode")
+	cmd.Flags().Duration("timeout", 0, "Timeout of a portal request (default 30s)")
+	cmd.Flags().Int("retries", types.DefaultRetries, "Retries of idempotent portal requests")
+
+	return cmd
+}
+
+// promote applies the promotion rules of the module (see labels.Promote):
+// the changes are printed as a diff and submitted after confirmation, unless the --yes flag is set.
+func promote(cmd *cobra.Command, _ []string) error {
+	from, _ := cmd.Flags().GetString("from")
+	switch types.VersionLabel(from) {
+	case "", types.Alpha, types.Beta, types.Stable:
+	default:
+		return errors2.ErrInvalidLabel
+	}
+
+	s, err := login(cmd)
+	if err != nil {
+		return err
+	}
+
+	changes := labels.Promote(s.versions, s.module.PromotionRules(), types.VersionLabel(from))
+
+	return s.apply(cmd, changes, true)
+}
@@ -2,10 +2,10 @@ package list
 
 import (
 	"fmt"
-	"maps"
-	"time"
+	"slices"
 
-	"github.com/pixel365/bx/internal/helpers"
+	"github.com/pixel365/bx/internal/logger"
+	"github.com/pixel365/bx/internal/validators"
 
 	"github.com/pixel365/bx/internal/client"
 	"github.com/pixel365/bx/internal/request"
@@ -20,9 +20,10 @@ import (
 
 var (
 	readModuleFromFlagsFunc = module.ReadModuleFromFlags
-	authFunc                = auth.Authenticate
+	authFunc                = auth.Login
 	inputPasswordFunc       = auth.InputPassword
 	versionsFunc            = request.Versions
+	newClientFunc           = client.NewPortalClient
 )
 
 func NewListCommand() *cobra.Command {
@@ -35,6 +36,9 @@ bx list --name my_module
 
 # List all module versions by file path
 bx list -f config.yaml
+
+# List stable versions since 2.0.0 as JSON
+bx list --name my_module --label stable --since 2.0.0 --output json
 `,
 		RunE: list,
 	}
@@ -42,14 +46,25 @@ bx list -f config.yaml
 	cmd.Flags().StringP("name", "n", "", "Name of the module")
 	cmd.Flags().StringP("file", "f", "", "Path to a module")
 	cmd.Flags().StringP("password", "p", "", "Account password")
+	cmd.Flags().Bool("password-stdin", false, "Read the account password from stdin")
 	cmd.Flags().BoolP("head", "", false, "Show last module version")
 	cmd.Flags().StringP("sort", "", "", "Sort module versions by name")
+	cmd.Flags().StringP("output", "o", string(outputText), "Output format: text, json, yaml, table or csv")
+	cmd.Flags().StringSliceP("label", "l", nil, "Show only versions with the labels")
+	cmd.Flags().String("since", "", "Show only versions starting from the version")
 	cmd.Flags().BoolP("silent", "s", false, "Silent mode")
+	cmd.Flags().Duration("timeout", 0, "Timeout of a portal request (default 30s)")
+	cmd.Flags().Int("retries", types.DefaultRetries, "Retries of idempotent portal requests")
 
 	return cmd
 }
 
 func list(cmd *cobra.Command, _ []string) error {
+	format, filter, err := readListFlags(cmd)
+	if err != nil {
+		return err
+	}
+
 	mod, err := readModuleFromFlagsFunc(cmd)
 	if err != nil {
 		return err
@@ -60,9 +75,11 @@ func list(cmd *cobra.Command, _ []string) error {
 		return err
 	}
 
+	// The spinner would mix with machine-readable output.
 	silent, _ := cmd.Flags().GetBool("silent")
+	silent = silent || format != outputText
 
-	httpClient := client.NewClient(10 * time.Second)
+	httpClient := newClientFunc(mod.HTTP, logger.NewFileLogger(mod.Log, mod.Name))
 
 	cookies, err := authFunc(httpClient, mod, password, silent)
 	if err != nil {
@@ -74,39 +91,46 @@ func list(cmd *cobra.Command, _ []string) error {
 		return err
 	}
 
-	head, _ := cmd.Flags().GetBool("head")
-	s, _ := cmd.Flags().GetString("sort")
+	return writeVersions(cmd.OutOrStdout(), format, selectVersions(versions, filter))
+}
 
-	sorting := types.Desc
+// readListFlags validates the output format and the filters of the list command.
+func readListFlags(cmd *cobra.Command) (outputFormat, versionFilter, error) {
+	filter := versionFilter{sorting: types.Desc}
 
+	format := outputFormat(cmd.Flag("output").Value.String())
+	if !slices.Contains(outputFormats, format) {
+		return "", filter, fmt.Errorf("%w: unknown output format %q", errors.ErrInvalidArgument, format)
+	}
+
+	filter.head, _ = cmd.Flags().GetBool("head")
+
+	s, _ := cmd.Flags().GetString("sort")
 	if s != "" {
 		switch s {
 		case string(types.Asc), string(types.Desc):
-			sorting = types.SortingType(s)
+			filter.sorting = types.SortingType(s)
 		default:
-			return errors.ErrInvalidArgument
+			return "", filter, errors.ErrInvalidArgument
 		}
 	}
 
-	keys := maps.Keys(versions)
-	items := helpers.SortSemanticVersions(keys)
-
-	if sorting == types.Asc {
-		for _, version := range items {
-			fmt.Printf("%s (%s)\n", version, versions[version])
-			if head {
-				break
-			}
+	labels, _ := cmd.Flags().GetStringSlice("label")
+	for _, label := range labels {
+		switch l := types.VersionLabel(label); l {
+		case types.Alpha, types.Beta, types.Stable:
+			filter.labels = append(filter.labels, l)
+		default:
+			return "", filter, errors.ErrInvalidLabel
 		}
-		return nil
 	}
 
-	for i := len(items) - 1; i >= 0; i-- {
-		fmt.Printf("%s (%s)\n", items[i], versions[items[i]])
-		if head {
-			break
+	filter.since, _ = cmd.Flags().GetString("since")
+	if filter.since != "" {
+		if err := validators.ValidateVersion(filter.since); err != nil {
+			return "", filter, err
 		}
 	}
 
-	return nil
+	return format, filter, nil
 }
@@ -1,6 +1,7 @@
 package list
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
@@ -21,6 +22,7 @@ import (
 
 	"github.com/pixel365/bx/internal/helpers"
 	"github.com/pixel365/bx/internal/module"
+	"github.com/pixel365/bx/internal/portaltest"
 )
 
 func TestNewListCommand(t *testing.T) {
@@ -197,3 +199,50 @@ func Test_list_versions(t *testing.T) {
 	err = cmd.Execute()
 	require.Error(t, err)
 }
+
+func Test_list_portal(t *testing.T) {
+	portal := portaltest.NewPortal()
+	defer portal.Close()
+
+	portal.AddAccount("partner", "secret")
+	portal.AddModule("partner", "vendor.module", types.Versions{"1.0.0": types.Stable, "1.1.0": types.Beta})
+
+	originalReadModule := readModuleFromFlagsFunc
+	originalVersionsFunc := versionsFunc
+
+	readModuleFromFlagsFunc = func(cmd *cobra.Command) (*module.Module, error) {
+		return &module.Module{Name: "vendor.module", Account: "partner", Portal: portal.URL()}, nil
+	}
+	defer func() {
+		readModuleFromFlagsFunc = originalReadModule
+	}()
+
+	var got types.Versions
+	versionsFunc = func(ctx context.Context, client client.HTTPClient, module *module.Module,
+		cookies []*http.Cookie) (types.Versions, error) {
+		versions, err := originalVersionsFunc(ctx, client, module, cookies)
+		got = versions
+		return versions, err
+	}
+	defer func() {
+		versionsFunc = originalVersionsFunc
+	}()
+
+	cmd := NewListCommand()
+	cmd.SetArgs([]string{"--password", "secret", "--silent", "--head"})
+	require.NoError(t, cmd.Execute())
+
+	assert.Equal(t, types.Versions{"1.0.0": types.Stable, "1.1.0": types.Beta}, got)
+	assert.Equal(t, 1, portal.Logins())
+
+	var out bytes.Buffer
+	cmd = NewListCommand()
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"--password", "secret", "--output", "json", "--label", "stable"})
+	require.NoError(t, cmd.Execute())
+	assert.JSONEq(t, `[{"version": "1.0.0", "label": "stable"}]`, out.String())
+
+	cmd = NewListCommand()
+	cmd.SetArgs([]string{"--password", "invalid", "--silent"})
+	require.Error(t, cmd.Execute())
+}
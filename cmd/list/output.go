@@ -0,0 +1,131 @@
+package list
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/pixel365/bx/internal/helpers"
+	"github.com/pixel365/bx/internal/types"
+)
+
+// outputFormat is the format of the list printed by the list command.
+type outputFormat string
+
+const (
+	outputText  outputFormat = "text"
+	outputJSON  outputFormat = "json"
+	outputYAML  outputFormat = "yaml"
+	outputTable outputFormat = "table"
+	outputCSV   outputFormat = "csv"
+)
+
+var outputFormats = []outputFormat{outputText, outputJSON, outputYAML, outputTable, outputCSV}
+
+// versionInfo is a module version as printed by the list command.
+// New columns of the versions table are added here as fields.
+type versionInfo struct {
+	Version string             `json:"version" yaml:"version"`
+	Label   types.VersionLabel `json:"label"   yaml:"label"`
+}
+
+// versionFilter selects the versions printed by the list command.
+type versionFilter struct {
+	since   string
+	labels  []types.VersionLabel
+	sorting types.SortingType
+	head    bool
+}
+
+// selectVersions filters the versions and orders them by semantic version.
+//
+// Parameters:
+//   - versions: The versions of the module and their labels.
+//   - filter: The labels to keep (all if empty), the lowest version to keep (all if empty),
+//     the order, and whether only the first version is kept.
+//
+// Returns:
+//   - []versionInfo: The selected versions; never nil.
+func selectVersions(versions types.Versions, filter versionFilter) []versionInfo {
+	keys := helpers.SortSemanticVersions(maps.Keys(versions))
+	if filter.sorting != types.Asc {
+		slices.Reverse(keys)
+	}
+
+	items := make([]versionInfo, 0, len(keys))
+	for _, version := range keys {
+		label := versions[version]
+
+		if len(filter.labels) > 0 && !slices.Contains(filter.labels, label) {
+			continue
+		}
+
+		if filter.since != "" && helpers.CompareSemanticVersions(version, filter.since) < 0 {
+			continue
+		}
+
+		items = append(items, versionInfo{Version: version, Label: label})
+
+		if filter.head {
+			break
+		}
+	}
+
+	return items
+}
+
+// writeVersions prints the versions in the format.
+//
+// Formats:
+//   - text: `version (label)` lines, as printed before the formats were added.
+//   - json: An array of objects with the `version` and `label` fields.
+//   - yaml: A sequence of mappings with the same fields.
+//   - table: Aligned columns with a header.
+//   - csv: A `version,label` header followed by a record per version.
+func writeVersions(w io.Writer, format outputFormat, items []versionInfo) error {
+	switch format {
+	case outputJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(items)
+	case outputYAML:
+		if len(items) == 0 {
+			_, err := fmt.Fprintln(w, "[]")
+			return err
+		}
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(items); err != nil {
+			return err
+		}
+		return encoder.Close()
+	case outputTable:
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintln(tw, "VERSION\tLABEL")
+		for _, item := range items {
+			_, _ = fmt.Fprintf(tw, "%s\t%s\n", item.Version, item.Label)
+		}
+		return tw.Flush()
+	case outputCSV:
+		cw := csv.NewWriter(w)
+		_ = cw.Write([]string{"version", "label"})
+		for _, item := range items {
+			_ = cw.Write([]string{item.Version, string(item.Label)})
+		}
+		cw.Flush()
+		return cw.Error()
+	default:
+		for _, item := range items {
+			if _, err := fmt.Fprintf(w, "%s (%s)\n", item.Version, item.Label); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,213 @@
+package list
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/pixel365/bx/internal/errors"
+	"github.com/pixel365/bx/internal/types"
+)
+
+var testVersions = types.Versions{
+	"1.9.0":       types.Stable,
+	"1.10.0":      types.Stable,
+	"2.0.0-beta":  types.Beta,
+	"2.0.0":       types.Stable,
+	"2.1.0":       types.Alpha,
+	"2.0.10":      types.Beta,
+	"10.0.0-rc.1": types.Alpha,
+}
+
+func Test_selectVersions(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		want   []string
+		filter versionFilter
+	}{
+		{
+			"desc by default",
+			[]string{"10.0.0-rc.1", "2.1.0", "2.0.10", "2.0.0", "2.0.0-beta", "1.10.0", "1.9.0"},
+			versionFilter{},
+		},
+		{
+			"asc",
+			[]string{"1.9.0", "1.10.0", "2.0.0-beta", "2.0.0", "2.0.10", "2.1.0", "10.0.0-rc.1"},
+			versionFilter{sorting: types.Asc},
+		},
+		{
+			"label",
+			[]string{"2.0.0", "1.10.0", "1.9.0"},
+			versionFilter{labels: []types.VersionLabel{types.Stable}},
+		},
+		{
+			"labels",
+			[]string{"2.0.10", "2.0.0", "2.0.0-beta", "1.10.0", "1.9.0"},
+			versionFilter{labels: []types.VersionLabel{types.Stable, types.Beta}},
+		},
+		{
+			"since",
+			[]string{"10.0.0-rc.1", "2.1.0", "2.0.10", "2.0.0"},
+			versionFilter{since: "2.0.0"},
+		},
+		{
+			"since with label and head",
+			[]string{"2.0.0"},
+			versionFilter{since: "1.10.0", labels: []types.VersionLabel{types.Stable}, head: true},
+		},
+		{
+			"head asc",
+			[]string{"1.9.0"},
+			versionFilter{sorting: types.Asc, head: true},
+		},
+		{
+			"nothing",
+			[]string{},
+			versionFilter{since: "11.0.0"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			items := selectVersions(testVersions, tt.filter)
+			require.NotNil(t, items)
+
+			got := make([]string, 0, len(items))
+			for _, item := range items {
+				got = append(got, item.Version)
+				assert.Equal(t, testVersions[item.Version], item.Label)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_writeVersions(t *testing.T) {
+	t.Parallel()
+
+	items := []versionInfo{{Version: "2.0.0", Label: types.Stable}, {Version: "1.0.0", Label: types.Beta}}
+
+	tests := []struct {
+		format outputFormat
+		want   string
+	}{
+		{outputText, "2.0.0 (stable)\n1.0.0 (beta)\n"},
+		{outputTable, "VERSION  LABEL\n2.0.0    stable\n1.0.0    beta\n"},
+		{outputCSV, "version,label\n2.0.0,stable\n1.0.0,beta\n"},
+		{outputYAML, "- version: 2.0.0\n  label: stable\n- version: 1.0.0\n  label: beta\n"},
+		{
+			outputJSON,
+			"[\n  {\n    \"version\": \"2.0.0\",\n    \"label\": \"stable\"\n  },\n" +
+				"  {\n    \"version\": \"1.0.0\",\n    \"label\": \"beta\"\n  }\n]\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			t.Parallel()
+
+			var out bytes.Buffer
+			require.NoError(t, writeVersions(&out, tt.format, items))
+			assert.Equal(t, tt.want, out.String())
+		})
+	}
+}
+
+func Test_writeVersions_roundTrip(t *testing.T) {
+	t.Parallel()
+
+	items := selectVersions(testVersions, versionFilter{})
+
+	var out bytes.Buffer
+	require.NoError(t, writeVersions(&out, outputJSON, items))
+	var fromJSON []versionInfo
+	require.NoError(t, json.Unmarshal(out.Bytes(), &fromJSON))
+	assert.Equal(t, items, fromJSON)
+
+	out.Reset()
+	require.NoError(t, writeVersions(&out, outputYAML, items))
+	var fromYAML []versionInfo
+	require.NoError(t, yaml.Unmarshal(out.Bytes(), &fromYAML))
+	assert.Equal(t, items, fromYAML)
+
+	out.Reset()
+	require.NoError(t, writeVersions(&out, outputCSV, items))
+	records, err := csv.NewReader(&out).ReadAll()
+	require.NoError(t, err)
+	assert.Len(t, records, len(items)+1)
+}
+
+func Test_writeVersions_empty(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+	require.NoError(t, writeVersions(&out, outputJSON, []versionInfo{}))
+	assert.Equal(t, "[]\n", out.String())
+
+	out.Reset()
+	require.NoError(t, writeVersions(&out, outputYAML, []versionInfo{}))
+	assert.Equal(t, "[]\n", out.String())
+
+	out.Reset()
+	require.NoError(t, writeVersions(&out, outputText, []versionInfo{}))
+	assert.Empty(t, out.String())
+}
+
+func Test_readListFlags(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		wantErr error
+		name    string
+		args    []string
+		want    versionFilter
+		format  outputFormat
+	}{
+		{nil, "defaults", nil, versionFilter{sorting: types.Desc}, outputText},
+		{
+			nil,
+			"all",
+			[]string{"-o", "csv", "--label", "stable,beta", "--since", "2.0.0", "--sort", "asc", "--head"},
+			versionFilter{
+				sorting: types.Asc,
+				since:   "2.0.0",
+				labels:  []types.VersionLabel{types.Stable, types.Beta},
+				head:    true,
+			},
+			outputCSV,
+		},
+		{errors.ErrInvalidArgument, "unknown format", []string{"--output", "xml"}, versionFilter{}, ""},
+		{errors.ErrInvalidArgument, "unknown sort", []string{"--sort", "up"}, versionFilter{}, ""},
+		{errors.ErrInvalidLabel, "unknown label", []string{"--label", "gamma"}, versionFilter{}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cmd := NewListCommand()
+			require.NoError(t, cmd.ParseFlags(tt.args))
+
+			format, filter, err := readListFlags(cmd)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.format, format)
+			assert.Equal(t, tt.want, filter)
+		})
+	}
+
+	cmd := NewListCommand()
+	require.NoError(t, cmd.ParseFlags([]string{"--since", "two"}))
+	_, _, err := readListFlags(cmd)
+	require.Error(t, err)
+}
@@ -0,0 +1,100 @@
+package logout
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/pixel365/bx/internal/auth"
+	"github.com/pixel365/bx/internal/module"
+	"github.com/pixel365/bx/internal/session"
+)
+
+var (
+	readModuleFromFlagsFunc = module.ReadModuleFromFlags
+	sessionStoreFunc        = session.DefaultStore
+)
+
+func NewLogoutCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "logout",
+		Short: "Delete the cached portal session",
+		Example: `
+# Delete the cached session of the module account
+bx logout --name my_module
+
+# Delete the cached session of an account
+bx logout --account partner@example.com
+
+# Delete the cached session of an account on another portal
+bx logout --account partner@example.com --portal https://partners.example.com
+
+# Delete all cached sessions
+bx logout --all
+`,
+		RunE: logout,
+	}
+
+	cmd.Flags().StringP("name", "n", "", "Name of the module")
+	cmd.Flags().StringP("file", "f", "", "Path to a module")
+	cmd.Flags().StringP("account", "a", "", "Account login")
+	cmd.Flags().StringP("portal", "", "", "Portal URL of the account session; used with --account")
+	cmd.Flags().BoolP("all", "", false, "Delete the cached sessions of all accounts")
+
+	return cmd
+}
+
+// logout deletes cached portal sessions.
+//
+// With --all, the sessions of all accounts are deleted. With --account, the session of
+// that account on the portal given by --portal (or the BX_PORTAL_URL environment variable)
+// is deleted. Otherwise, the account is taken from the module selected by --name or --file,
+// the same way as in the other commands.
+//
+// Deleting a session that is not cached is not an error.
+func logout(cmd *cobra.Command, _ []string) error {
+	store, err := sessionStoreFunc()
+	if err != nil {
+		return err
+	}
+
+	all, _ := cmd.Flags().GetBool("all")
+	if all {
+		n, err := store.DeleteAll()
+		if err != nil {
+			return err
+		}
+
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Deleted cached sessions: %d\n", n)
+
+		return nil
+	}
+
+	account, _ := cmd.Flags().GetString("account")
+	account = strings.TrimSpace(account)
+
+	var mod *module.Module
+	if account != "" {
+		portal, _ := cmd.Flags().GetString("portal")
+		mod = &module.Module{Account: account, Portal: strings.TrimSpace(portal)}
+		if _, err := mod.PortalURL(); err != nil {
+			return err
+		}
+	} else {
+		mod, err = readModuleFromFlagsFunc(cmd)
+		if err != nil {
+			return err
+		}
+	}
+
+	account = auth.SessionAccount(mod)
+
+	if err := store.Delete(account); err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Logged out: %s\n", account)
+
+	return nil
+}
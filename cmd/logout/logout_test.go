@@ -0,0 +1,142 @@
+package logout
+
+import (
+	"bytes"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pixel365/bx/internal/errors"
+	"github.com/pixel365/bx/internal/module"
+	"github.com/pixel365/bx/internal/session"
+)
+
+func TestNewLogoutCommand(t *testing.T) {
+	cmd := NewLogoutCommand()
+
+	assert.NotNil(t, cmd)
+	assert.Equal(t, "logout", cmd.Use)
+	assert.Equal(t, "Delete the cached portal session", cmd.Short)
+	assert.NotNil(t, cmd.RunE)
+	assert.True(t, cmd.HasFlags())
+	assert.False(t, cmd.HasSubCommands())
+}
+
+func TestLogoutCommand(t *testing.T) {
+	originalStore := sessionStoreFunc
+	originalReadModule := readModuleFromFlagsFunc
+	defer func() {
+		sessionStoreFunc = originalStore
+		readModuleFromFlagsFunc = originalReadModule
+	}()
+
+	store := &session.Store{Dir: t.TempDir()}
+	sessionStoreFunc = func() (*session.Store, error) { return store, nil }
+	readModuleFromFlagsFunc = func(_ *cobra.Command) (*module.Module, error) {
+		return &module.Module{Account: "module-account"}, nil
+	}
+
+	cookies := []*http.Cookie{{Name: "BITRIX_SM_LOGIN", Value: "partner"}}
+	save := func() {
+		for _, account := range []string{"module-account", "partner", "other", "other https://partners.example.com"} {
+			require.NoError(t, store.Save(account, "secret", cookies, time.Hour))
+		}
+	}
+	cached := func(account string) bool {
+		_, err := store.Load(account, "secret")
+		return err == nil
+	}
+
+	tests := []struct {
+		name   string
+		output string
+		args   []string
+		left   []string
+	}{
+		{"module", "Logged out: module-account\n", nil, []string{"partner", "other"}},
+		{"account", "Logged out: partner\n", []string{"--account", "partner"}, []string{"module-account", "other"}},
+		{
+			"account on another portal",
+			"Logged out: other https://partners.example.com\n",
+			[]string{"--account", "other", "--portal", "https://partners.example.com/"},
+			[]string{"module-account", "partner", "other"},
+		},
+		{"all", "Deleted cached sessions: 4\n", []string{"--all"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			save()
+
+			var out bytes.Buffer
+			cmd := NewLogoutCommand()
+			cmd.SetOut(&out)
+			cmd.SetArgs(tt.args)
+
+			require.NoError(t, cmd.Execute())
+			assert.Equal(t, tt.output, out.String())
+
+			for _, account := range []string{"module-account", "partner", "other"} {
+				assert.Equal(t, slices.Contains(tt.left, account), cached(account), account)
+			}
+		})
+	}
+}
+
+func TestLogoutCommand_portalEnv(t *testing.T) {
+	originalStore := sessionStoreFunc
+	defer func() {
+		sessionStoreFunc = originalStore
+	}()
+
+	t.Setenv(module.PortalEnv, "https://partners.example.com")
+
+	store := &session.Store{Dir: t.TempDir()}
+	sessionStoreFunc = func() (*session.Store, error) { return store, nil }
+
+	cookies := []*http.Cookie{{Name: "BITRIX_SM_LOGIN", Value: "partner"}}
+	require.NoError(t, store.Save("partner https://partners.example.com", "secret", cookies, time.Hour))
+
+	cmd := NewLogoutCommand()
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetArgs([]string{"--account", "partner"})
+	require.NoError(t, cmd.Execute())
+
+	_, err := store.Load("partner https://partners.example.com", "secret")
+	require.Error(t, err, "the session on the portal from the environment is deleted")
+}
+
+func TestLogoutCommand_invalidPortal(t *testing.T) {
+	originalStore := sessionStoreFunc
+	defer func() {
+		sessionStoreFunc = originalStore
+	}()
+
+	sessionStoreFunc = func() (*session.Store, error) { return &session.Store{Dir: t.TempDir()}, nil }
+
+	cmd := NewLogoutCommand()
+	cmd.SetArgs([]string{"--account", "partner", "--portal", "ftp://example.com"})
+	require.ErrorContains(t, cmd.Execute(), "must be an absolute http or https url")
+}
+
+func TestLogoutCommand_readModule_error(t *testing.T) {
+	originalStore := sessionStoreFunc
+	originalReadModule := readModuleFromFlagsFunc
+	defer func() {
+		sessionStoreFunc = originalStore
+		readModuleFromFlagsFunc = originalReadModule
+	}()
+
+	sessionStoreFunc = func() (*session.Store, error) { return &session.Store{Dir: t.TempDir()}, nil }
+	readModuleFromFlagsFunc = func(_ *cobra.Command) (*module.Module, error) {
+		return nil, errors.ErrNilModule
+	}
+
+	cmd := NewLogoutCommand()
+	cmd.SetArgs(nil)
+	require.ErrorIs(t, cmd.Execute(), errors.ErrNilModule)
+}
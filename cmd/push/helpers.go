@@ -2,14 +2,17 @@ package push
 
 import (
 	"context"
+	"fmt"
 	"net/http"
-	"time"
+	"os"
 
+	"github.com/pixel365/bx/internal/callback"
 	"github.com/pixel365/bx/internal/client"
 
 	"github.com/pixel365/bx/internal/types"
 
 	"github.com/pixel365/bx/internal/helpers"
+	"github.com/pixel365/bx/internal/logger"
 
 	"github.com/pixel365/bx/internal/auth"
 
@@ -23,15 +26,27 @@ import (
 var (
 	readModuleFromFlagsFunc = module.ReadModuleFromFlags
 	uploadFunc              = upload
-	authFunc                = auth.Authenticate
+	authFunc                = auth.Login
 	inputPasswordFunc       = auth.InputPassword
-	spinnerFunc             = helpers.Spinner
+	uploadZIPFunc           = request.UploadZIP
+	newProgressBarFunc      = helpers.NewProgressBar
+	newClientFunc           = client.NewPortalClient
+	versionsFunc            = request.Versions
+	changeLabelsFunc        = request.ChangeLabels
 )
 
 // push handles the logic for pushing a module to the Marketplace.
 // It validates the module name, reads the module configuration, and authenticates the user.
 // The module is then uploaded to the specified server after authentication.
 //
+// Before the upload, the versions of the module are read from the portal, and a version that
+// already exists is refused unless the --force flag is set. After the upload and the label
+// change, the versions are read again to confirm that the new version is listed
+// with the expected label.
+//
+// The beforePush hook of the module is run before the upload; its failure cancels the push.
+// The afterPush hook is run after the push with its outcome; its failure is reported as a warning.
+//
 // Parameters:
 //   - cmd (*cobra.Command): The Cobra command that invoked the push function.
 //   - args ([]string): A slice of arguments passed to the command (unused here).
@@ -60,23 +75,140 @@ func push(cmd *cobra.Command, _ []string) error {
 	}
 
 	silent, _ := cmd.Flags().GetBool("silent")
+	force, _ := cmd.Flags().GetBool("force")
 
-	httpClient := client.NewClient(10 * time.Second)
+	log := logger.NewFileLogger(mod.Log, mod.Name)
+	httpClient := newClientFunc(mod.HTTP, log)
 
 	cookies, err := authFunc(httpClient, mod, password, silent)
 	if err != nil {
 		return err
 	}
 
-	err = uploadFunc(cmd.Context(), httpClient, mod, cookies, silent)
-	if err != nil {
+	ctx := cmd.Context()
+
+	if err := checkVersion(ctx, httpClient, mod, cookies, force, silent); err != nil {
+		return err
+	}
+
+	if err := mod.RunHook(ctx, callback.BeforePush, nil, log); err != nil {
+		return err
+	}
+
+	err = publish(ctx, httpClient, mod, cookies, silent)
+
+	if hookErr := mod.RunFinalHook(ctx, callback.AfterPush, err, log); hookErr != nil {
+		_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Warning: %v\n", hookErr)
+	}
+
+	return err
+}
+
+// publish uploads the module, sets the label of the new version and verifies the upload.
+func publish(
+	ctx context.Context,
+	client client.HTTPClient,
+	module *module.Module,
+	cookies []*http.Cookie,
+	silent bool,
+) error {
+	if err := uploadFunc(ctx, client, module, cookies, silent); err != nil {
 		return err
 	}
 
 	versions := make(types.Versions, 1)
-	versions[mod.Version] = mod.GetLabel()
+	versions[module.Version] = module.GetLabel()
+
+	if err := changeLabelsFunc(client, module, cookies, versions); err != nil {
+		return err
+	}
+
+	return verifyVersion(ctx, client, module, cookies)
+}
+
+// checkVersion refuses to upload a version that is already listed on the portal.
+//
+// Parameters:
+//   - ctx: The context of the command.
+//   - client: The authenticated portal client.
+//   - module: The module being pushed.
+//   - cookies: The session cookies.
+//   - force: Upload the version even if it already exists; a warning is printed instead.
+//   - silent: Suppresses the warning.
+//
+// Returns:
+//   - error: A *errors.VersionError wrapping errors.ErrVersionExists if the version exists
+//     and force is not set, or the error of reading the versions.
+func checkVersion(
+	ctx context.Context,
+	client client.HTTPClient,
+	module *module.Module,
+	cookies []*http.Cookie,
+	force, silent bool,
+) error {
+	versions, err := versionsFunc(ctx, client, module, cookies)
+	if err != nil {
+		return fmt.Errorf("failed to read versions of %s: %w", module.Name, err)
+	}
+
+	label, exists := versions[module.Version]
+	if !exists {
+		return nil
+	}
+
+	if !force {
+		return &errors.VersionError{
+			Err:     errors.ErrVersionExists,
+			Module:  module.Name,
+			Version: module.Version,
+			Label:   string(label),
+		}
+	}
+
+	if !silent {
+		_, _ = fmt.Fprintf(os.Stderr, "Warning: version %s of %s already exists (%s), uploading anyway\n",
+			module.Version, module.Name, label)
+	}
+
+	return nil
+}
+
+// verifyVersion confirms that the uploaded version is listed on the portal with the label of the module.
+//
+// Returns:
+//   - error: A *errors.VersionError wrapping errors.ErrVersionNotUploaded if the version is not listed,
+//     or errors.ErrVersionLabelMismatch if its label differs; or the error of reading the versions.
+func verifyVersion(
+	ctx context.Context,
+	client client.HTTPClient,
+	module *module.Module,
+	cookies []*http.Cookie,
+) error {
+	versions, err := versionsFunc(ctx, client, module, cookies)
+	if err != nil {
+		return fmt.Errorf("failed to verify the upload of %s: %w", module.Name, err)
+	}
+
+	label, exists := versions[module.Version]
+	if !exists {
+		return &errors.VersionError{
+			Err:     errors.ErrVersionNotUploaded,
+			Module:  module.Name,
+			Version: module.Version,
+		}
+	}
 
-	return request.ChangeLabels(httpClient, mod, cookies, versions)
+	if expected := module.GetLabel(); label != expected {
+		return &errors.VersionError{
+			Err:      errors.ErrVersionLabelMismatch,
+			Module:   module.Name,
+			Version:  module.Version,
+			Label:    string(label),
+			Expected: string(expected),
+		}
+	}
+
+	return nil
 }
 
 func upload(
@@ -99,13 +231,12 @@ func upload(
 	}
 
 	if silent {
-		return request.UploadZIP(ctx, client, module, cookies)
+		return uploadZIPFunc(ctx, client, module, cookies, nil)
 	}
 
-	return spinnerFunc(
-		"Uploading module to partners.1c-bitrix.ru...",
-		func(ctx context.Context) error {
-			return request.UploadZIP(ctx, client, module, cookies)
-		},
-	)
+	bar := newProgressBarFunc(os.Stderr, "Uploading module")
+	err := uploadZIPFunc(ctx, client, module, cookies, bar.Update)
+	bar.Done(err)
+
+	return err
 }
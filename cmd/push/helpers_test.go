@@ -1,23 +1,33 @@
 package push
 
 import (
+	"archive/zip"
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"testing"
 	"time"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/pixel365/bx/internal/callback"
 	"github.com/pixel365/bx/internal/client"
+	errors2 "github.com/pixel365/bx/internal/errors"
 
 	"github.com/spf13/cobra"
 
 	"github.com/pixel365/bx/internal/helpers"
 	"github.com/pixel365/bx/internal/module"
+	"github.com/pixel365/bx/internal/portaltest"
+	"github.com/pixel365/bx/internal/request"
+	"github.com/pixel365/bx/internal/types"
 )
 
 func Test_push_ReadModuleFromFlags(t *testing.T) {
@@ -253,12 +263,24 @@ func Test_upload(t *testing.T) {
 		{"not silent", args{&client.MockHttpClient{}, &module.Module{}, c, false}, false},
 	}
 
-	origSpinnerFunc := spinnerFunc
-	spinnerFunc = func(_ string, _ func(context.Context) error) error {
+	origUploadZIPFunc := uploadZIPFunc
+	origNewProgressBarFunc := newProgressBarFunc
+	uploadZIPFunc = func(_ context.Context, _ client.HTTPClient, _ *module.Module,
+		_ []*http.Cookie, progress request.ProgressFunc) error {
+		if progress != nil {
+			progress(50, 100)
+			progress(100, 100)
+		}
 		return nil
 	}
+	newProgressBarFunc = func(_ io.Writer, title string) *helpers.ProgressBar {
+		return helpers.NewProgressBar(io.Discard, title)
+	}
 
-	defer func() { spinnerFunc = origSpinnerFunc }()
+	defer func() {
+		uploadZIPFunc = origUploadZIPFunc
+		newProgressBarFunc = origNewProgressBarFunc
+	}()
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -441,3 +463,234 @@ func Test_push_invalid_input(t *testing.T) {
 	err := cmd.Execute()
 	require.Error(t, err)
 }
+
+func Test_push_portal(t *testing.T) {
+	portal := portaltest.NewPortal()
+	defer portal.Close()
+
+	portal.AddAccount("partner", "secret")
+	portal.AddModule("partner", "vendor.module", types.Versions{"1.0.0": types.Stable})
+
+	mod := &module.Module{
+		Name:           "vendor.module",
+		Account:        "partner",
+		Version:        "1.1.0",
+		Label:          types.Beta,
+		Portal:         portal.URL(),
+		BuildDirectory: t.TempDir(),
+	}
+
+	f, err := os.Create(filepath.Join(mod.BuildDirectory, "1.1.0.zip"))
+	require.NoError(t, err)
+	w := zip.NewWriter(f)
+	_, err = w.Create("1.1.0/install/index.php")
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	require.NoError(t, f.Close())
+
+	originalReadModule := readModuleFromFlagsFunc
+	readModuleFromFlagsFunc = func(cmd *cobra.Command) (*module.Module, error) {
+		return mod, nil
+	}
+	defer func() {
+		readModuleFromFlagsFunc = originalReadModule
+	}()
+
+	cmd := NewPushCommand()
+	cmd.SetArgs([]string{"--password", "secret", "--silent"})
+	require.NoError(t, cmd.Execute())
+
+	assert.Len(t, portal.Uploads(), 1)
+	assert.Equal(t, types.Versions{"1.0.0": types.Stable, "1.1.0": types.Beta}, portal.Versions("vendor.module"))
+
+	cmd = NewPushCommand()
+	cmd.SetArgs([]string{"--password", "secret", "--silent"})
+	err = cmd.Execute()
+	require.ErrorIs(t, err, errors2.ErrVersionExists)
+	assert.Len(t, portal.Uploads(), 1, "the existing version is refused before the upload")
+
+	var versionErr *errors2.VersionError
+	require.ErrorAs(t, err, &versionErr)
+	assert.Equal(t, "1.1.0", versionErr.Version)
+	assert.Equal(t, string(types.Beta), versionErr.Label)
+
+	cmd = NewPushCommand()
+	cmd.SetArgs([]string{"--password", "secret", "--silent", "--force"})
+	require.Error(t, cmd.Execute(), "the portal rejects the duplicate upload")
+}
+
+func Test_checkVersion(t *testing.T) {
+	ctx := context.Background()
+	mod := &module.Module{Name: "vendor.module", Version: "1.1.0"}
+	cookies := []*http.Cookie{{Name: "BITRIX_SM_LOGIN", Value: "partner"}}
+
+	originalVersionsFunc := versionsFunc
+	defer func() {
+		versionsFunc = originalVersionsFunc
+	}()
+
+	tests := []struct {
+		versionsErr error
+		versions    types.Versions
+		wantErr     error
+		name        string
+		force       bool
+	}{
+		{nil, types.Versions{"1.0.0": types.Stable}, nil, "new version", false},
+		{nil, types.Versions{"1.1.0": types.Alpha}, errors2.ErrVersionExists, "existing version", false},
+		{nil, types.Versions{"1.1.0": types.Alpha}, nil, "existing version with force", true},
+		{errors2.ErrEmptySession, nil, errors2.ErrEmptySession, "versions error", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			versionsFunc = func(_ context.Context, _ client.HTTPClient, _ *module.Module,
+				_ []*http.Cookie) (types.Versions, error) {
+				return tt.versions, tt.versionsErr
+			}
+
+			err := checkVersion(ctx, nil, mod, cookies, tt.force, true)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func Test_verifyVersion(t *testing.T) {
+	ctx := context.Background()
+	mod := &module.Module{Name: "vendor.module", Version: "1.1.0", Label: types.Beta}
+	cookies := []*http.Cookie{{Name: "BITRIX_SM_LOGIN", Value: "partner"}}
+
+	originalVersionsFunc := versionsFunc
+	defer func() {
+		versionsFunc = originalVersionsFunc
+	}()
+
+	tests := []struct {
+		versionsErr error
+		versions    types.Versions
+		wantErr     error
+		name        string
+		wantMessage string
+	}{
+		{nil, types.Versions{"1.1.0": types.Beta}, nil, "uploaded", ""},
+		{
+			nil,
+			types.Versions{"1.0.0": types.Stable},
+			errors2.ErrVersionNotUploaded,
+			"missing",
+			"vendor.module 1.1.0: version is missing after upload",
+		},
+		{
+			nil,
+			types.Versions{"1.1.0": types.Alpha},
+			errors2.ErrVersionLabelMismatch,
+			"wrong label",
+			"vendor.module 1.1.0: version label does not match: expected beta, got alpha",
+		},
+		{errors2.ErrEmptySession, nil, errors2.ErrEmptySession, "versions error", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			versionsFunc = func(_ context.Context, _ client.HTTPClient, _ *module.Module,
+				_ []*http.Cookie) (types.Versions, error) {
+				return tt.versions, tt.versionsErr
+			}
+
+			err := verifyVersion(ctx, nil, mod, cookies)
+			if tt.wantErr == nil {
+				require.NoError(t, err)
+				return
+			}
+
+			require.ErrorIs(t, err, tt.wantErr)
+			if tt.wantMessage != "" {
+				assert.EqualError(t, err, tt.wantMessage)
+			}
+		})
+	}
+}
+
+func Test_push_hooks(t *testing.T) {
+	portal := portaltest.NewPortal()
+	defer portal.Close()
+
+	portal.AddAccount("partner", "secret")
+	portal.AddModule("partner", "vendor.module", types.Versions{"1.0.0": types.Stable})
+
+	var hooks []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		hooks = append(hooks, r.Form.Get("hook")+":"+r.Form.Get("status"))
+
+		if r.Form.Get("hook") == callback.BeforePush && r.Form.Get("cancel") != "" {
+			w.WriteHeader(http.StatusConflict)
+		}
+	}))
+	defer server.Close()
+
+	action := func(params ...string) []callback.CallbackParameters {
+		return []callback.CallbackParameters{
+			{Type: callback.ExternalType, Action: server.URL, Method: http.MethodPost, Parameters: params},
+		}
+	}
+
+	mod := &module.Module{
+		Name:           "vendor.module",
+		Account:        "partner",
+		Version:        "1.1.0",
+		Label:          types.Beta,
+		Portal:         portal.URL(),
+		BuildDirectory: t.TempDir(),
+		Hooks: &callback.Hooks{
+			BeforePush: action("cancel=1"),
+			AfterPush:  action(),
+		},
+	}
+
+	f, err := os.Create(filepath.Join(mod.BuildDirectory, "1.1.0.zip"))
+	require.NoError(t, err)
+	w := zip.NewWriter(f)
+	_, err = w.Create("1.1.0/install/index.php")
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	require.NoError(t, f.Close())
+
+	originalReadModule := readModuleFromFlagsFunc
+	readModuleFromFlagsFunc = func(cmd *cobra.Command) (*module.Module, error) {
+		return mod, nil
+	}
+	defer func() {
+		readModuleFromFlagsFunc = originalReadModule
+	}()
+
+	cmd := NewPushCommand()
+	cmd.SetArgs([]string{"--password", "secret", "--silent"})
+	require.ErrorContains(t, cmd.Execute(), "hook beforePush [0] failed")
+	assert.Empty(t, portal.Uploads(), "a failed beforePush hook cancels the push")
+	assert.Equal(t, []string{"beforePush:success"}, hooks)
+
+	hooks = nil
+	mod.Hooks.BeforePush = action()
+
+	cmd = NewPushCommand()
+	cmd.SetArgs([]string{"--password", "secret", "--silent"})
+	require.NoError(t, cmd.Execute())
+	assert.Len(t, portal.Uploads(), 1)
+	assert.Equal(t, []string{"beforePush:success", "afterPush:success"}, hooks)
+
+	hooks = nil
+	mod.Hooks.AfterPush = append(mod.Hooks.AfterPush, callback.CallbackParameters{
+		Type: callback.CommandType, Action: "bx-missing-command",
+	})
+
+	var stderr bytes.Buffer
+	cmd = NewPushCommand()
+	cmd.SetErr(&stderr)
+	cmd.SetArgs([]string{"--password", "secret", "--silent", "--force"})
+	require.Error(t, cmd.Execute(), "the portal rejects the duplicate upload")
+	assert.Equal(t, []string{"beforePush:success", "afterPush:failure"}, hooks)
+	assert.Contains(t, stderr.String(), "Warning: hook afterPush [1] failed")
+}
@@ -2,6 +2,8 @@ package push
 
 import (
 	"github.com/spf13/cobra"
+
+	"github.com/pixel365/bx/internal/types"
 )
 
 func NewPushCommand() *cobra.Command {
@@ -17,6 +19,9 @@ bx push -f config.yaml
 
 # Override version
 bx push --name my_module --version 1.2.3
+
+# Upload a version that already exists on the portal
+bx push --name my_module --force
 `,
 		RunE: push,
 	}
@@ -26,7 +31,12 @@ bx push --name my_module --version 1.2.3
 	cmd.Flags().StringP("version", "v", "", "Version of the module")
 	cmd.Flags().StringP("label", "l", "", "Version label")
 	cmd.Flags().StringP("password", "p", "", "Account password")
+	cmd.Flags().Bool("password-stdin", false, "Read the account password from stdin")
 	cmd.Flags().BoolP("silent", "s", false, "Silent mode")
+	cmd.Flags().Bool("force", false, "Upload the version even if it already exists")
+	cmd.Flags().Duration("timeout", 0, "Timeout of a portal request (default 30s)")
+	cmd.Flags().Duration("upload-timeout", 0, "Timeout of the release upload (default 10m)")
+	cmd.Flags().Int("retries", types.DefaultRetries, "Retries of idempotent portal requests")
 
 	return cmd
 }
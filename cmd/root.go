@@ -3,21 +3,25 @@ package cmd
 import (
 	"context"
 	"errors"
+	"io"
 	"os"
 
 	"github.com/pixel365/bx/cmd/label"
 
 	"github.com/pixel365/bx/cmd/list"
+	"github.com/pixel365/bx/cmd/logout"
 
 	"github.com/pixel365/bx/cmd/build"
 	"github.com/pixel365/bx/cmd/check"
 	"github.com/pixel365/bx/cmd/create"
 	"github.com/pixel365/bx/cmd/run"
+	"github.com/pixel365/bx/cmd/secret"
 	"github.com/pixel365/bx/cmd/version"
 
 	"github.com/pixel365/bx/cmd/push"
 
 	"github.com/pixel365/bx/internal/helpers"
+	"github.com/pixel365/bx/internal/redact"
 
 	"github.com/spf13/cobra"
 
@@ -57,10 +61,25 @@ func NewRootCmd(ctx context.Context) *cobra.Command {
 	cmd.AddCommand(version.NewVersionCommand())
 	cmd.AddCommand(list.NewListCommand())
 	cmd.AddCommand(label.NewLabelCommand())
+	cmd.AddCommand(label.NewPromoteCommand())
+	cmd.AddCommand(logout.NewLogoutCommand())
+	cmd.AddCommand(secret.NewSecretCommand())
 
 	return cmd
 }
 
+// SetOutput sets the stdout and stderr of the command and its subcommands.
+// Secrets written to them are masked, except the output of `bx secret decrypt`.
+//
+// Parameters:
+//   - cmd (*cobra.Command): The root command.
+//   - stdout (io.Writer): The writer of the command output.
+//   - stderr (io.Writer): The writer of errors and warnings.
+func SetOutput(cmd *cobra.Command, stdout, stderr io.Writer) {
+	cmd.SetOut(redact.Writer(stdout))
+	cmd.SetErr(redact.Writer(stderr))
+}
+
 // initRootDir is responsible for initializing the root directory for the project.
 // It checks if the specified root directory exists, creates it if it doesn't,
 // and returns the absolute path to the directory.
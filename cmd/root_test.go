@@ -1,13 +1,20 @@
 package cmd
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"fmt"
 	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/pixel365/bx/internal/redact"
+	"github.com/pixel365/bx/internal/secret"
 )
 
 func TestNewRootCmd(t *testing.T) {
@@ -130,3 +137,43 @@ func Test_Cmd_PersistentPreRunE_Err(t *testing.T) {
 	err := cmd.PersistentPreRunE(cmd, []string{})
 	require.Error(t, err)
 }
+
+func TestSetOutput(t *testing.T) {
+	origInitRootDir := initRootDirFunc
+	initRootDirFunc = func() (string, error) {
+		return t.TempDir(), nil
+	}
+	defer func() {
+		initRootDirFunc = origInitRootDir
+	}()
+
+	const value = "root-output-secret"
+	redact.Add(value)
+
+	key, err := secret.GenerateKey()
+	require.NoError(t, err)
+	keyPath := filepath.Join(t.TempDir(), "secret.key")
+	require.NoError(t, secret.WriteKey(keyPath, key))
+	sealed, err := secret.Seal(key, value)
+	require.NoError(t, err)
+
+	run := func(args ...string) string {
+		var stdout, stderr bytes.Buffer
+		cmd := NewRootCmd(context.Background())
+		cmd.AddCommand(&cobra.Command{
+			Use: "print",
+			Run: func(cmd *cobra.Command, _ []string) {
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "value: %s\n", value)
+			},
+		})
+		SetOutput(cmd, &stdout, &stderr)
+		cmd.SetArgs(args)
+		require.NoError(t, cmd.Execute())
+
+		return stdout.String()
+	}
+
+	assert.Equal(t, "value: ******\n", run("print"), "the output is masked")
+	assert.Equal(t, value+"\n", run("secret", "decrypt", "--key", keyPath, sealed),
+		"a decrypted value is printed in plain text")
+}
@@ -0,0 +1,304 @@
+package secret
+
+import (
+	"bytes"
+	errs "errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/pixel365/bx/internal/errors"
+	"github.com/pixel365/bx/internal/helpers"
+	"github.com/pixel365/bx/internal/redact"
+	"github.com/pixel365/bx/internal/secret"
+)
+
+var keyPathFunc = secret.DefaultKeyPath
+
+func NewSecretCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "secret",
+		Short: "Encrypt values of module configurations",
+		Example: `
+# Encrypt a value read from stdin and paste the output into the module configuration
+echo "token" | bx secret encrypt
+
+# Decrypt a value
+bx secret decrypt 'v1:...'
+
+# Re-encrypt the secrets of all modules with a new key
+bx secret rotate
+`,
+	}
+
+	cmd.PersistentFlags().String("key", "", "Path to the key file")
+
+	cmd.AddCommand(newEncryptCommand(), newDecryptCommand(), newRotateCommand())
+
+	return cmd
+}
+
+func newEncryptCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "encrypt [value]",
+		Short: "Encrypt a value",
+		Long: "Encrypt a value given as the argument or read from stdin and print it as a !secret YAML value.\n" +
+			"A new key file is created if it does not exist.",
+		Args: cobra.MaximumNArgs(1),
+		RunE: encrypt,
+	}
+}
+
+func newDecryptCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "decrypt [value]",
+		Short: "Decrypt a value",
+		Long:  "Decrypt a value given as the argument or read from stdin. The `!secret` tag may be included.",
+		Args:  cobra.MaximumNArgs(1),
+		RunE:  decrypt,
+	}
+}
+
+func newRotateCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rotate",
+		Short: "Re-encrypt the secrets of module configurations with a new key",
+		Long: "Re-encrypt the !secret values of the module configurations with a new key and replace the key file.\n" +
+			"The previous key is kept next to the key file with the .old suffix.",
+		Args: cobra.NoArgs,
+		RunE: rotate,
+	}
+
+	cmd.Flags().StringSliceP("file", "f", nil,
+		"Module configuration files. By default, all configurations in the .bx directory")
+
+	return cmd
+}
+
+// encrypt prints the value sealed with the key, creating the key file on first use.
+func encrypt(cmd *cobra.Command, args []string) error {
+	path, err := keyPath(cmd)
+	if err != nil {
+		return err
+	}
+
+	key, err := secret.LoadKey(path)
+	if errs.Is(err, errors.ErrSecretKeyNotFound) {
+		if key, err = secret.GenerateKey(); err == nil {
+			err = secret.WriteKey(path, key)
+		}
+		if err == nil {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Created a new secret key: %s\n", path)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	value, err := readValue(cmd, args)
+	if err != nil {
+		return err
+	}
+
+	sealed, err := secret.Seal(key, value)
+	if err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%s %s\n", secret.Tag, sealed)
+
+	return nil
+}
+
+// decrypt prints the plain text of a sealed value.
+func decrypt(cmd *cobra.Command, args []string) error {
+	key, err := loadKey(cmd)
+	if err != nil {
+		return err
+	}
+
+	value, err := readValue(cmd, args)
+	if err != nil {
+		return err
+	}
+
+	plain, err := secret.Open(key, strings.TrimPrefix(strings.TrimSpace(value), secret.Tag))
+	if err != nil {
+		return err
+	}
+
+	// the value is requested in plain text, so the output is not masked
+	_, _ = fmt.Fprintln(redact.Unwrap(cmd.OutOrStdout()), plain)
+
+	return nil
+}
+
+// rotate re-encrypts the secrets of the module configurations with a new key.
+//
+// All values are decrypted before anything is written, so a value that cannot be decrypted
+// leaves the files and the key intact. The sealed values are replaced in the file contents,
+// so the formatting and comments of the files are kept. The previous key is saved to `<key>.old`
+// before the new key and the files are written.
+func rotate(cmd *cobra.Command, _ []string) error {
+	path, err := keyPath(cmd)
+	if err != nil {
+		return err
+	}
+
+	oldKey, err := secret.LoadKey(path)
+	if err != nil {
+		return err
+	}
+
+	files, err := moduleFiles(cmd)
+	if err != nil {
+		return err
+	}
+
+	newKey, err := secret.GenerateKey()
+	if err != nil {
+		return err
+	}
+
+	contents := make(map[string][]byte, len(files))
+	count := 0
+	for _, file := range files {
+		data, n, err := reseal(file, oldKey, newKey)
+		if err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+		if n > 0 {
+			contents[file] = data
+			count += n
+		}
+	}
+
+	if err := secret.WriteKey(path+".old", oldKey); err != nil {
+		return err
+	}
+
+	if err := secret.WriteKey(path, newKey); err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		data, ok := contents[file]
+		if !ok {
+			continue
+		}
+
+		info, err := os.Stat(file)
+		if err != nil {
+			return err
+		}
+
+		if err := os.WriteFile(file, data, info.Mode().Perm()); err != nil {
+			return err
+		}
+	}
+
+	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Re-encrypted %d secret(s) in %d file(s). The previous key is saved to %s\n",
+		count, len(contents), path+".old")
+
+	return nil
+}
+
+// reseal returns the content of the file with its `!secret` values sealed with the new key
+// and the number of the values.
+func reseal(file string, oldKey, newKey secret.Key) ([]byte, int, error) {
+	data, err := os.ReadFile(filepath.Clean(file))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, 0, err
+	}
+
+	nodes := secret.Sealed(&doc)
+	for _, node := range nodes {
+		plain, err := secret.Open(oldKey, node.Value)
+		if err != nil {
+			return nil, 0, fmt.Errorf("line %d: %w", node.Line, err)
+		}
+
+		sealed, err := secret.Seal(newKey, plain)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		if !bytes.Contains(data, []byte(node.Value)) {
+			return nil, 0, fmt.Errorf("line %d: %w: the value must be written on one line",
+				node.Line, errors.ErrSecretInvalid)
+		}
+		data = bytes.Replace(data, []byte(node.Value), []byte(sealed), 1)
+	}
+
+	return data, len(nodes), nil
+}
+
+// moduleFiles returns the files set by the --file flag or the YAML files in the .bx directory.
+func moduleFiles(cmd *cobra.Command) ([]string, error) {
+	files, _ := cmd.Flags().GetStringSlice("file")
+	if len(files) > 0 {
+		return files, nil
+	}
+
+	dir, ok := cmd.Context().Value(helpers.RootDir).(string)
+	if !ok {
+		return nil, errors.ErrInvalidRootDir
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.yaml"))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("%w: no module configurations in %s", errors.ErrNoItems, dir)
+	}
+
+	return files, nil
+}
+
+func keyPath(cmd *cobra.Command) (string, error) {
+	if path, _ := cmd.Flags().GetString("key"); strings.TrimSpace(path) != "" {
+		return strings.TrimSpace(path), nil
+	}
+
+	return keyPathFunc()
+}
+
+func loadKey(cmd *cobra.Command) (secret.Key, error) {
+	path, err := keyPath(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	return secret.LoadKey(path)
+}
+
+// readValue returns the argument, or stdin without the trailing line break.
+func readValue(cmd *cobra.Command, args []string) (string, error) {
+	if len(args) == 1 {
+		return args[0], nil
+	}
+
+	data, err := io.ReadAll(cmd.InOrStdin())
+	if err != nil {
+		return "", err
+	}
+
+	value := strings.TrimRight(string(data), "\r\n")
+	if value == "" {
+		return "", fmt.Errorf("%w: empty value", errors.ErrInvalidArgument)
+	}
+
+	return value, nil
+}
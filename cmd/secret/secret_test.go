@@ -0,0 +1,178 @@
+package secret
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/pixel365/bx/internal/errors"
+	"github.com/pixel365/bx/internal/helpers"
+	"github.com/pixel365/bx/internal/secret"
+)
+
+func TestNewSecretCommand(t *testing.T) {
+	cmd := NewSecretCommand()
+
+	assert.NotNil(t, cmd)
+	assert.Equal(t, "secret", cmd.Use)
+	assert.Equal(t, "Encrypt values of module configurations", cmd.Short)
+	assert.Nil(t, cmd.RunE)
+	assert.True(t, cmd.HasSubCommands())
+	assert.Len(t, cmd.Commands(), 3)
+}
+
+func execute(t *testing.T, dir, stdin string, args ...string) (string, string, error) {
+	t.Helper()
+
+	var stdout, stderr bytes.Buffer
+	cmd := NewSecretCommand()
+	cmd.SetIn(strings.NewReader(stdin))
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&stderr)
+	cmd.SetArgs(args)
+
+	err := cmd.ExecuteContext(context.WithValue(context.Background(), helpers.RootDir, dir))
+
+	return stdout.String(), stderr.String(), err
+}
+
+func TestSecretCommand_encrypt_decrypt(t *testing.T) {
+	dir := t.TempDir()
+	keyFile := filepath.Join(dir, "keys", "secret.key")
+
+	original := keyPathFunc
+	defer func() {
+		keyPathFunc = original
+	}()
+	keyPathFunc = func() (string, error) { return keyFile, nil }
+
+	_, _, err := execute(t, dir, "", "decrypt", "v1:AAAA")
+	require.ErrorIs(t, err, errors.ErrSecretKeyNotFound)
+
+	out, stderr, err := execute(t, dir, "token\n", "encrypt")
+	require.NoError(t, err)
+	assert.Contains(t, stderr, "Created a new secret key: "+keyFile)
+	require.True(t, strings.HasPrefix(out, "!secret v1:"))
+	assert.NotContains(t, out, "token")
+	assert.FileExists(t, keyFile)
+
+	plain, _, err := execute(t, dir, "", "decrypt", strings.TrimSpace(out))
+	require.NoError(t, err)
+	assert.Equal(t, "token\n", plain)
+
+	sealed, stderr, err := execute(t, dir, "", "encrypt", "other")
+	require.NoError(t, err)
+	assert.Empty(t, stderr, "the key is reused")
+
+	plain, _, err = execute(t, dir, sealed, "decrypt")
+	require.NoError(t, err)
+	assert.Equal(t, "other\n", plain)
+
+	_, _, err = execute(t, dir, "", "encrypt")
+	require.ErrorIs(t, err, errors.ErrInvalidArgument)
+
+	other := filepath.Join(dir, "other.key")
+	_, _, err = execute(t, dir, "", "decrypt", "--key", other, sealed)
+	require.ErrorIs(t, err, errors.ErrSecretKeyNotFound, "--key overrides the default key file")
+}
+
+func TestSecretCommand_rotate(t *testing.T) {
+	dir := t.TempDir()
+	keyFile := filepath.Join(dir, "secret.key")
+
+	original := keyPathFunc
+	defer func() {
+		keyPathFunc = original
+	}()
+	keyPathFunc = func() (string, error) { return keyFile, nil }
+
+	_, _, err := execute(t, dir, "", "rotate")
+	require.ErrorIs(t, err, errors.ErrSecretKeyNotFound)
+
+	oldKey, err := secret.GenerateKey()
+	require.NoError(t, err)
+	require.NoError(t, secret.WriteKey(keyFile, oldKey))
+
+	token, _ := secret.Seal(oldKey, "token")
+	password, _ := secret.Seal(oldKey, "password")
+
+	withSecrets := "# module\nname: first\nvariables:\n  token: !secret " + token + " # webhook\n" +
+		"  password: !secret '" + password + "'\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "first.yaml"), []byte(withSecrets), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "second.yaml"), []byte("name: second\n"), 0600))
+
+	out, _, err := execute(t, dir, "", "rotate")
+	require.NoError(t, err)
+	assert.Contains(t, out, "Re-encrypted 2 secret(s) in 1 file(s)")
+
+	newKey, err := secret.LoadKey(keyFile)
+	require.NoError(t, err)
+	assert.NotEqual(t, oldKey, newKey)
+
+	backup, err := secret.LoadKey(keyFile + ".old")
+	require.NoError(t, err)
+	assert.Equal(t, oldKey, backup)
+
+	data, err := os.ReadFile(filepath.Join(dir, "first.yaml"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), token)
+	assert.Contains(t, string(data), "# module\n")
+	assert.Contains(t, string(data), " # webhook\n", "the formatting is kept")
+
+	var doc yaml.Node
+	require.NoError(t, yaml.Unmarshal(data, &doc))
+	values, err := secret.Decrypt(&doc, func() (secret.Key, error) { return newKey, nil })
+	require.NoError(t, err)
+	require.Len(t, values, 2)
+	assert.Equal(t, "token", values[0].Plain)
+	assert.Equal(t, "password", values[1].Plain)
+
+	second, err := os.ReadFile(filepath.Join(dir, "second.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "name: second\n", string(second))
+}
+
+func TestSecretCommand_rotate_invalid(t *testing.T) {
+	dir := t.TempDir()
+	keyFile := filepath.Join(dir, "secret.key")
+
+	original := keyPathFunc
+	defer func() {
+		keyPathFunc = original
+	}()
+	keyPathFunc = func() (string, error) { return keyFile, nil }
+
+	key, err := secret.GenerateKey()
+	require.NoError(t, err)
+	require.NoError(t, secret.WriteKey(keyFile, key))
+
+	otherKey, err := secret.GenerateKey()
+	require.NoError(t, err)
+	foreign, _ := secret.Seal(otherKey, "token")
+
+	file := filepath.Join(dir, "module.yaml")
+	content := "name: test\ntoken: !secret " + foreign + "\n"
+	require.NoError(t, os.WriteFile(file, []byte(content), 0600))
+
+	_, _, err = execute(t, dir, "", "rotate", "--file", file)
+	require.ErrorIs(t, err, errors.ErrSecretInvalid)
+
+	kept, err := secret.LoadKey(keyFile)
+	require.NoError(t, err)
+	assert.Equal(t, key, kept, "the key is not replaced")
+	assert.NoFileExists(t, keyFile+".old")
+
+	data, err := os.ReadFile(file)
+	require.NoError(t, err)
+	assert.Equal(t, content, string(data))
+
+	_, _, err = execute(t, t.TempDir(), "", "rotate")
+	require.ErrorIs(t, err, errors.ErrNoItems)
+}
@@ -31,7 +31,8 @@ func NewVersionCommand() *cobra.Command {
 func printVersion(cmd *cobra.Command) {
 	verbose, _ := cmd.Flags().GetBool("verbose")
 	if verbose {
-		fmt.Printf(
+		_, _ = fmt.Fprintf(
+			cmd.OutOrStdout(),
 			"Version: %s\nCommit: %s\nDate: %s\nGo: %s %s/%s\n",
 			buildVersion,
 			buildCommit,
@@ -43,5 +44,5 @@ func printVersion(cmd *cobra.Command) {
 		return
 	}
 
-	fmt.Printf("%s\n", buildVersion)
+	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%s\n", buildVersion)
 }
@@ -2,6 +2,8 @@ package auth
 
 import (
 	"context"
+	errs "errors"
+	"fmt"
 	"net/http"
 	"os"
 	"strings"
@@ -13,7 +15,9 @@ import (
 	"github.com/pixel365/bx/internal/errors"
 	"github.com/pixel365/bx/internal/helpers"
 	"github.com/pixel365/bx/internal/module"
+	"github.com/pixel365/bx/internal/redact"
 	"github.com/pixel365/bx/internal/request"
+	"github.com/pixel365/bx/internal/session"
 	"github.com/pixel365/bx/internal/types"
 	"github.com/pixel365/bx/internal/validators"
 )
@@ -21,8 +25,95 @@ import (
 var (
 	inputPasswordFunc     = helpers.UserInput
 	newPasswordPromptFunc = types.NewPrompt
+	authenticateFunc      = Authenticate
+	validateSessionFunc   = request.ValidateSession
+	sessionStoreFunc      = session.DefaultStore
+	readPasswordFileFunc  = readPasswordFile
+	credentialHelperFunc  = runCredentialHelper
 )
 
+// Login returns session cookies for the module account, reusing the cached session
+// if the module enables the session cache.
+//
+// Parameters:
+//   - client (client.HTTPClient): The HTTP client used for the requests.
+//   - module (*module.Module): The module object.
+//     If nil, the function returns errors.ErrNilModule.
+//   - password (string): The account password. It is also the key of the cache encryption.
+//   - silent (bool): Skip spinner.
+//
+// Returns:
+//   - []*http.Cookie: The session cookies.
+//   - Error: Any error encountered during authentication.
+//
+// Behavior:
+//   - Without `session.cache: true` in the module, it is the same as Authenticate.
+//   - Cached cookies are checked against the portal before reuse. If the cache is missing,
+//     expired, cannot be decrypted or is rejected by the portal, the function authenticates
+//     again and replaces the cache.
+//   - Cache read and write failures do not fail the login; a warning is printed instead.
+func Login(
+	client client.HTTPClient,
+	module *module.Module,
+	password string,
+	silent bool,
+) ([]*http.Cookie, error) {
+	if module == nil {
+		return nil, errors.ErrNilModule
+	}
+
+	if module.Session == nil || !module.Session.Cache {
+		return authenticateFunc(client, module, password, silent)
+	}
+
+	store, err := sessionStoreFunc()
+	if err != nil {
+		warn(err)
+		return authenticateFunc(client, module, password, silent)
+	}
+
+	account := SessionAccount(module)
+
+	cookies, err := store.Load(account, password)
+	if err == nil {
+		if validateSessionFunc(client, module, cookies) == nil {
+			return cookies, nil
+		}
+	} else if !errs.Is(err, errors.ErrSessionNotFound) &&
+		!errs.Is(err, errors.ErrSessionExpired) &&
+		!errs.Is(err, errors.ErrSessionInvalid) {
+		warn(err)
+	}
+
+	cookies, err = authenticateFunc(client, module, password, silent)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := store.Save(account, password, cookies, module.Session.Lifetime()); err != nil {
+		warn(err)
+	}
+
+	return cookies, nil
+}
+
+// SessionAccount returns the name the session of the module account is cached under.
+//
+// For the default portal, it is the account itself. Sessions on other portals
+// (see module.PortalURL) are cached separately, under the account followed by the portal URL.
+func SessionAccount(mod *module.Module) string {
+	u, err := mod.PortalURL()
+	if err != nil || u.String() == module.DefaultPortalURL {
+		return mod.Account
+	}
+
+	return mod.Account + " " + u.String()
+}
+
+func warn(err error) {
+	_, _ = fmt.Fprintf(os.Stderr, "Warning: %v\n", redact.Error(err))
+}
+
 // Authenticate performs authentication against the partners.1c-bitrix.ru service
 // using the provided module and password.
 //
@@ -62,11 +153,11 @@ func Authenticate(
 	var cookies []*http.Cookie
 
 	if silent {
-		cookies, err = request.Authenticate(client, module.Account, password)
+		cookies, err = request.Authenticate(client, module, password)
 	} else {
 		err = helpers.Spinner("Authenticate on partners.1c-bitrix.ru...",
 			func(ctx context.Context) error {
-				cookies, err = request.Authenticate(client, module.Account, password)
+				cookies, err = request.Authenticate(client, module, password)
 				return err
 			})
 	}
@@ -79,22 +170,29 @@ func Authenticate(
 }
 
 // InputPassword manages the process of obtaining and validating the password needed for authentication.
-// It first checks if the password was provided as a flag, then checks environment variables, and if neither are found,
-// it prompts the user to enter a password interactively.
+//
+// The password is taken from the first source that provides it:
+//   - the `--password` flag;
+//   - stdin, if the `--password-stdin` flag is set;
+//   - the `<MODULE>_PASSWORD` environment variable (see module.PasswordEnv);
+//   - the file set by `passwordFile` in the module;
+//   - the command set by `credentialHelper` in the module, asked for the password of the module account;
+//   - an interactive prompt.
 //
 // Parameters:
-// - cmd (*cobra.Command): The Cobra command that invoked the function.
-// - module (*internal.Module): The module for which the password is being provided (may use environment variable).
+//   - cmd (*cobra.Command): The Cobra command that invoked the function. Stdin is read from cmd.InOrStdin.
+//   - module (*internal.Module): The module for which the password is being provided.
+//
+// The password is registered in the redact package, so it is masked in logs and errors.
 //
 // Returns:
-// - string: The validated password.
-// - error: An error if the password is invalid or the prompt fails.
+//   - string: The validated password.
+//   - error: An error if the password is invalid, a source fails or the prompt fails.
+//     Setting both `--password` and `--password-stdin` is errors.ErrInvalidArgument.
 func InputPassword(cmd *cobra.Command, module *module.Module) (string, error) {
-	password, _ := cmd.Flags().GetString("password")
-	password = strings.TrimSpace(password)
-
-	if password == "" {
-		password = os.Getenv(module.PasswordEnv())
+	password, err := readPassword(cmd, module)
+	if err != nil {
+		return "", err
 	}
 
 	if password == "" {
@@ -119,5 +217,52 @@ func InputPassword(cmd *cobra.Command, module *module.Module) (string, error) {
 		return "", err
 	}
 
+	redact.Add(password)
+
 	return password, nil
 }
+
+// readPassword returns the password from the non-interactive sources of InputPassword,
+// or an empty string if none of them provides it.
+func readPassword(cmd *cobra.Command, module *module.Module) (string, error) {
+	password, _ := cmd.Flags().GetString("password")
+	password = strings.TrimSpace(password)
+	fromStdin, _ := cmd.Flags().GetBool("password-stdin")
+
+	if password != "" {
+		if fromStdin {
+			return "", fmt.Errorf("%w: --password and --password-stdin are mutually exclusive",
+				errors.ErrInvalidArgument)
+		}
+		return password, nil
+	}
+
+	if fromStdin {
+		password, err := readFirstLine(cmd.InOrStdin())
+		if err != nil {
+			return "", fmt.Errorf("password-stdin: %w", err)
+		}
+		if strings.TrimSpace(password) == "" {
+			return "", fmt.Errorf("password-stdin: %w", errors.ErrEmptyPassword)
+		}
+		return password, nil
+	}
+
+	if password = os.Getenv(module.PasswordEnv()); password != "" {
+		return password, nil
+	}
+
+	if module.PasswordFile != "" {
+		return readPasswordFileFunc(module.PasswordFile)
+	}
+
+	if module.CredentialHelper != "" {
+		ctx := cmd.Context()
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		return credentialHelperFunc(ctx, module)
+	}
+
+	return "", nil
+}
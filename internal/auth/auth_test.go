@@ -1,6 +1,7 @@
 package auth
 
 import (
+	"fmt"
 	"net/http"
 	"testing"
 
@@ -8,6 +9,10 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/pixel365/bx/internal/client"
+	"github.com/pixel365/bx/internal/errors"
+	"github.com/pixel365/bx/internal/redact"
+	"github.com/pixel365/bx/internal/session"
+	"github.com/pixel365/bx/internal/types"
 
 	"github.com/spf13/cobra"
 
@@ -94,8 +99,88 @@ func Test_InputPassword(t *testing.T) {
 				require.Error(t, err)
 			} else {
 				require.NoError(t, err)
+				assert.Equal(t, redact.Mask, redact.String(res), "the password is masked in logs")
 			}
 			assert.Equal(t, tt.want, res)
 		})
 	}
 }
+
+func Test_Login(t *testing.T) {
+	originalAuthenticate := authenticateFunc
+	originalValidate := validateSessionFunc
+	originalStore := sessionStoreFunc
+	defer func() {
+		authenticateFunc = originalAuthenticate
+		validateSessionFunc = originalValidate
+		sessionStoreFunc = originalStore
+	}()
+
+	store := &session.Store{Dir: t.TempDir()}
+	sessionStoreFunc = func() (*session.Store, error) { return store, nil }
+
+	logins := 0
+	authenticateFunc = func(_ client.HTTPClient, _ *module.Module, _ string, _ bool) ([]*http.Cookie, error) {
+		logins++
+		return []*http.Cookie{{Name: "BITRIX_SM_LOGIN", Value: fmt.Sprintf("login-%d", logins)}}, nil
+	}
+
+	valid := true
+	validateSessionFunc = func(_ client.HTTPClient, _ *module.Module, _ []*http.Cookie) error {
+		if !valid {
+			return errors.ErrEmptySession
+		}
+		return nil
+	}
+
+	_, err := Login(nil, nil, "secret", true)
+	require.ErrorIs(t, err, errors.ErrNilModule)
+
+	mod := &module.Module{Name: "test", Account: "partner"}
+
+	cookies, err := Login(nil, mod, "secret", true)
+	require.NoError(t, err)
+	assert.Equal(t, "login-1", cookies[0].Value)
+	_, err = store.Load("partner", "secret")
+	require.ErrorIs(t, err, errors.ErrSessionNotFound, "the cache is disabled by default")
+
+	mod.Session = &types.Session{Cache: true}
+
+	cookies, err = Login(nil, mod, "secret", true)
+	require.NoError(t, err)
+	assert.Equal(t, "login-2", cookies[0].Value)
+
+	cookies, err = Login(nil, mod, "secret", true)
+	require.NoError(t, err)
+	assert.Equal(t, "login-2", cookies[0].Value, "cached session is reused")
+	assert.Equal(t, 2, logins)
+
+	valid = false
+	cookies, err = Login(nil, mod, "secret", true)
+	require.NoError(t, err)
+	assert.Equal(t, "login-3", cookies[0].Value, "rejected session is refreshed")
+
+	valid = true
+	cookies, err = Login(nil, mod, "secret", true)
+	require.NoError(t, err)
+	assert.Equal(t, "login-3", cookies[0].Value)
+
+	cookies, err = Login(nil, mod, "changed", true)
+	require.NoError(t, err)
+	assert.Equal(t, "login-4", cookies[0].Value, "password change invalidates the cache")
+
+	authenticateFunc = func(_ client.HTTPClient, _ *module.Module, _ string, _ bool) ([]*http.Cookie, error) {
+		return nil, errors.ErrAuthentication
+	}
+	_, err = Login(nil, mod, "other", true)
+	require.ErrorIs(t, err, errors.ErrAuthentication)
+}
+
+func Test_SessionAccount(t *testing.T) {
+	t.Setenv(module.PortalEnv, "")
+
+	assert.Equal(t, "partner", SessionAccount(&module.Module{Account: "partner"}))
+	assert.Equal(t, "partner", SessionAccount(&module.Module{Account: "partner", Portal: module.DefaultPortalURL + "/"}))
+	assert.Equal(t, "partner http://127.0.0.1:8080",
+		SessionAccount(&module.Module{Account: "partner", Portal: "http://127.0.0.1:8080"}))
+}
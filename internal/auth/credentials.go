@@ -0,0 +1,204 @@
+package auth
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	errs "errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/pixel365/bx/internal/errors"
+	"github.com/pixel365/bx/internal/module"
+)
+
+// credentialHelperTimeout limits the run of a credential helper.
+const credentialHelperTimeout = 30 * time.Second
+
+// readFirstLine returns the first line of r without the line break.
+// The password is read this way from stdin (`--password-stdin`) and from `passwordFile`.
+func readFirstLine(r io.Reader) (string, error) {
+	line, err := bufio.NewReader(r).ReadString('\n')
+	if err != nil && !errs.Is(err, io.EOF) {
+		return "", err
+	}
+
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// readPasswordFile reads the password from the first line of the file set by `passwordFile`.
+//
+// On Unix-like systems the file must not be accessible by the group or other users,
+// like the private keys of ssh. A leading `~/` is expanded to the home directory.
+//
+// Returns:
+//   - string: The password.
+//   - error: If the file cannot be read, its permissions are too open or the password is empty.
+func readPasswordFile(path string) (string, error) {
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("passwordFile: %w", err)
+		}
+		path = filepath.Join(home, rest)
+	}
+	path = filepath.Clean(path)
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("passwordFile: %w", err)
+	}
+
+	if perm := info.Mode().Perm(); runtime.GOOS != "windows" && perm&0o077 != 0 {
+		return "", fmt.Errorf("%w: %s has mode %04o, expected 0600 or stricter",
+			errors.ErrInsecurePasswordFile, path, perm)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("passwordFile: %w", err)
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	password, err := readFirstLine(file)
+	if err != nil {
+		return "", fmt.Errorf("passwordFile: %w", err)
+	}
+
+	if strings.TrimSpace(password) == "" {
+		return "", fmt.Errorf("passwordFile: %s: %w", path, errors.ErrEmptyPassword)
+	}
+
+	return password, nil
+}
+
+// runCredentialHelper gets the password of the module account from the command set by `credentialHelper`.
+//
+// The command is split into arguments with shell-style quoting (see splitCommand), so paths and
+// arguments may contain spaces. The helper is called the way git calls its credential helpers:
+// the command is run with the `get` argument, receives `protocol`, `host` and `username` (the account of the module) as `key=value`
+// lines on stdin, and prints `password=<password>` among its output lines. Stderr of the helper is
+// passed through, so a helper can ask the user.
+//
+// Parameters:
+//   - ctx (context.Context): The context of the run. The run is limited to 30 seconds.
+//   - mod (*module.Module): The module the password is for.
+//
+// Returns:
+//   - string: The password.
+//   - error: If the helper fails or prints no password.
+func runCredentialHelper(ctx context.Context, mod *module.Module) (string, error) {
+	args, err := splitCommand(mod.CredentialHelper)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", errors.ErrCredentialHelper, err)
+	}
+	if len(args) == 0 {
+		return "", fmt.Errorf("%w: command is empty", errors.ErrCredentialHelper)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, credentialHelperTimeout)
+	defer cancel()
+
+	protocol, host := "https", ""
+	if u, err := mod.PortalURL(); err == nil {
+		protocol, host = u.Scheme, u.Host
+	}
+
+	var stdin, stdout bytes.Buffer
+	_, _ = fmt.Fprintf(&stdin, "protocol=%s\nhost=%s\nusername=%s\n\n", protocol, host, mod.Account)
+
+	//nolint:gosec
+	command := exec.CommandContext(ctx, args[0], append(args[1:], "get")...)
+	command.Stdin = &stdin
+	command.Stdout = &stdout
+	command.Stderr = os.Stderr
+
+	if err := command.Run(); err != nil {
+		return "", fmt.Errorf("%w: %s: %w", errors.ErrCredentialHelper, args[0], err)
+	}
+
+	for line := range strings.Lines(stdout.String()) {
+		if password, ok := strings.CutPrefix(strings.TrimRight(line, "\r\n"), "password="); ok {
+			return password, nil
+		}
+	}
+
+	return "", fmt.Errorf("%w: %s returned no password for %s", errors.ErrCredentialHelper, args[0], mod.Account)
+}
+
+// splitCommand splits the command line into arguments the way a POSIX shell does, without expansions
+// and variables: arguments are separated by whitespace, the text in single quotes is kept as is,
+// in double quotes a backslash escapes only `"`, `\`, `$` and the backtick, and outside quotes
+// a backslash escapes any character.
+//
+// Returns:
+//   - []string: The arguments.
+//   - error: If a quote is not closed or the command ends with a backslash.
+func splitCommand(s string) ([]string, error) {
+	var args []string
+	var arg strings.Builder
+	inArg := false
+
+	runes := []rune(s)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == '\\':
+			i++
+			if i == len(runes) {
+				return nil, fmt.Errorf("command ends with a backslash")
+			}
+			arg.WriteRune(runes[i])
+			inArg = true
+		case r == '\'':
+			end := slices.Index(runes[i+1:], '\'')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated single quote")
+			}
+			arg.WriteString(string(runes[i+1 : i+1+end]))
+			i += end + 1
+			inArg = true
+		case r == '"':
+			closed := false
+			for i++; i < len(runes); i++ {
+				if runes[i] == '"' {
+					closed = true
+					break
+				}
+				if runes[i] == '\\' && i+1 < len(runes) && strings.ContainsRune("\"\\$`", runes[i+1]) {
+					i++
+				}
+				arg.WriteRune(runes[i])
+			}
+			if !closed {
+				return nil, fmt.Errorf("unterminated double quote")
+			}
+			inArg = true
+		case unicode.IsSpace(r):
+			if inArg {
+				args = append(args, arg.String())
+				arg.Reset()
+				inArg = false
+			}
+		default:
+			arg.WriteRune(r)
+			inArg = true
+		}
+	}
+
+	if inArg {
+		args = append(args, arg.String())
+	}
+
+	return args, nil
+}
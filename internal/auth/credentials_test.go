@@ -0,0 +1,265 @@
+package auth
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pixel365/bx/internal/errors"
+	"github.com/pixel365/bx/internal/module"
+)
+
+func Test_readFirstLine(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"secret\n", "secret"},
+		{"secret\r\nignored\n", "secret"},
+		{"secret", "secret"},
+		{" se cret \n", " se cret "},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := readFirstLine(strings.NewReader(tt.input))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_readPasswordFile(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	private := filepath.Join(dir, "private")
+	require.NoError(t, os.WriteFile(private, []byte("secret\n"), 0o600))
+
+	password, err := readPasswordFile(private)
+	require.NoError(t, err)
+	assert.Equal(t, "secret", password)
+
+	_, err = readPasswordFile(filepath.Join(dir, "missing"))
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	empty := filepath.Join(dir, "empty")
+	require.NoError(t, os.WriteFile(empty, []byte("\nsecret\n"), 0o600))
+
+	_, err = readPasswordFile(empty)
+	require.ErrorIs(t, err, errors.ErrEmptyPassword, "an empty file does not fall through to the prompt")
+
+	if runtime.GOOS == "windows" {
+		return
+	}
+
+	shared := filepath.Join(dir, "shared")
+	require.NoError(t, os.WriteFile(shared, []byte("secret\n"), 0o600))
+	require.NoError(t, os.Chmod(shared, 0o644))
+
+	_, err = readPasswordFile(shared)
+	require.ErrorIs(t, err, errors.ErrInsecurePasswordFile)
+	assert.Contains(t, err.Error(), "0644")
+}
+
+func writeHelper(t *testing.T, script string) string {
+	t.Helper()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("credential helper scripts require a POSIX shell")
+	}
+
+	path := filepath.Join(t.TempDir(), "helper")
+	require.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\n"+script), 0o700))
+
+	return path
+}
+
+func Test_runCredentialHelper(t *testing.T) {
+	t.Parallel()
+
+	helper := writeHelper(t, `[ "$1" = "get" ] || exit 2
+while read -r line && [ -n "$line" ]; do
+  case "$line" in
+    username=*) user="${line#username=}" ;;
+    host=*) host="${line#host=}" ;;
+  esac
+done
+echo "username=$user"
+echo "password=$user@$host"
+`)
+
+	mod := &module.Module{Name: "test", Account: "partner", CredentialHelper: helper}
+	password, err := runCredentialHelper(context.Background(), mod)
+	require.NoError(t, err)
+	assert.Equal(t, "partner@partners.1c-bitrix.ru", password)
+
+	mod = &module.Module{Name: "test", Account: "other", Portal: "http://127.0.0.1:8080", CredentialHelper: helper}
+	password, err = runCredentialHelper(context.Background(), mod)
+	require.NoError(t, err)
+	assert.Equal(t, "other@127.0.0.1:8080", password, "the password is resolved per account and portal")
+}
+
+func Test_runCredentialHelper_quoting(t *testing.T) {
+	t.Parallel()
+
+	helper := writeHelper(t, `echo "password=$1|$2"
+`)
+	dir := filepath.Join(t.TempDir(), "credential helpers")
+	require.NoError(t, os.Mkdir(dir, 0o700))
+	path := filepath.Join(dir, "helper's")
+	require.NoError(t, os.Rename(helper, path))
+
+	mod := &module.Module{
+		Account:          "partner",
+		CredentialHelper: `"` + strings.ReplaceAll(path, `"`, `\"`) + `" 'vault entry'`,
+	}
+	password, err := runCredentialHelper(context.Background(), mod)
+	require.NoError(t, err)
+	assert.Equal(t, "vault entry|get", password)
+}
+
+func Test_splitCommand(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		command string
+		want    []string
+		wantErr bool
+	}{
+		{"/usr/local/bin/bx-credentials", []string{"/usr/local/bin/bx-credentials"}, false},
+		{"  helper   --store  vault ", []string{"helper", "--store", "vault"}, false},
+		{`"/opt/My Tools/helper" --entry 'bitrix partner'`, []string{"/opt/My Tools/helper", "--entry", "bitrix partner"}, false},
+		{`helper "say \"hi\"" 'it''s' a\ b`, []string{"helper", `say "hi"`, "its", "a b"}, false},
+		{`helper "a\nb" 'c\d'`, []string{"helper", `a\nb`, `c\d`}, false},
+		{`helper ""`, []string{"helper", ""}, false},
+		{"", nil, false},
+		{`helper "open`, nil, true},
+		{`helper 'open`, nil, true},
+		{`helper \`, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := splitCommand(tt.command)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_runCredentialHelper_errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		script string
+	}{
+		{"exit code", "exit 1\n"},
+		{"no password", "echo username=partner\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mod := &module.Module{Account: "partner", CredentialHelper: writeHelper(t, tt.script)}
+			_, err := runCredentialHelper(context.Background(), mod)
+			require.ErrorIs(t, err, errors.ErrCredentialHelper)
+		})
+	}
+
+	_, err := runCredentialHelper(context.Background(), &module.Module{CredentialHelper: " "})
+	require.ErrorIs(t, err, errors.ErrCredentialHelper)
+
+	_, err = runCredentialHelper(context.Background(), &module.Module{CredentialHelper: `"helper`})
+	require.ErrorIs(t, err, errors.ErrCredentialHelper)
+}
+
+func Test_readPassword(t *testing.T) {
+	originalFile := readPasswordFileFunc
+	originalHelper := credentialHelperFunc
+	defer func() {
+		readPasswordFileFunc = originalFile
+		credentialHelperFunc = originalHelper
+	}()
+
+	readPasswordFileFunc = func(path string) (string, error) {
+		return "from-file", nil
+	}
+	credentialHelperFunc = func(_ context.Context, mod *module.Module) (string, error) {
+		return "from-helper-" + mod.Account, nil
+	}
+
+	tests := []struct {
+		wantErr error
+		module  *module.Module
+		name    string
+		env     string
+		stdin   string
+		want    string
+		args    []string
+	}{
+		{nil, &module.Module{}, "flag", "from-env", "", "from-flag", []string{"--password", "from-flag"}},
+		{nil, &module.Module{}, "stdin", "from-env", "from-stdin\n", "from-stdin", []string{"--password-stdin"}},
+		{
+			errors.ErrInvalidArgument,
+			&module.Module{},
+			"flag and stdin",
+			"",
+			"from-stdin\n",
+			"",
+			[]string{"--password", "from-flag", "--password-stdin"},
+		},
+		{errors.ErrEmptyPassword, &module.Module{}, "empty stdin", "", "", "", []string{"--password-stdin"}},
+		{nil, &module.Module{PasswordFile: "password"}, "env", "from-env", "", "from-env", nil},
+		{nil, &module.Module{PasswordFile: "password", CredentialHelper: "helper"}, "file", "", "", "from-file", nil},
+		{
+			nil,
+			&module.Module{Account: "partner", CredentialHelper: "helper"},
+			"helper",
+			"",
+			"",
+			"from-helper-partner",
+			nil,
+		},
+		{nil, &module.Module{}, "none", "", "", "", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.module.Name = "bx.credentials.test"
+			t.Setenv(tt.module.PasswordEnv(), tt.env)
+
+			cmd := &cobra.Command{}
+			cmd.Flags().String("password", "", "")
+			cmd.Flags().Bool("password-stdin", false, "")
+			cmd.SetIn(strings.NewReader(tt.stdin))
+			require.NoError(t, cmd.ParseFlags(tt.args))
+
+			got, err := readPassword(cmd, tt.module)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
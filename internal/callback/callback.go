@@ -1,16 +1,20 @@
 package callback
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"os"
 	"strings"
 	"time"
 
 	"github.com/pixel365/bx/internal/errors"
 	"github.com/pixel365/bx/internal/helpers"
+	"github.com/pixel365/bx/internal/interfaces"
+	"github.com/pixel365/bx/internal/types"
 	"github.com/pixel365/bx/internal/validators"
 
 	"github.com/go-cmd/cmd"
@@ -21,6 +25,12 @@ const (
 	CommandType  = "command"
 )
 
+// Payload formats of external POST callbacks.
+const (
+	PayloadForm = "form"
+	PayloadJSON = "json"
+)
+
 // CallbackParameters defines the details of a callback action.
 //
 // Fields:
@@ -28,11 +38,29 @@ const (
 //   - Action:   The action to be triggered (e.g., a URL or command name).
 //   - Method:   Optional HTTP method or execution method (e.g., "GET", "POST").
 //   - Parameters: Optional list of arguments or parameters to pass during callback execution.
+//   - Payload:  The body format of external POST callbacks: "form" (default) or "json".
+//   - Sensitive: The parameters contain secrets and are masked in logs and errors.
+//   - Timeout:  The limit of a single attempt; 30 seconds by default.
+//   - Retries:  The number of retries after a failed attempt; none by default.
+//   - RetryDelay: The delay between the attempts; 1 second by default.
+//   - OnFailure: What a failure means: "fail" (default) stops the build, "warn" prints a warning,
+//     "ignore" drops the error.
+//   - SaveOutput: The full output of the command is saved to a file in the log directory.
+//
+// The action URL and the parameters may contain placeholders of the build context,
+// e.g. {version} or {zipPath}; commands also receive the context as BX_* environment variables.
 type CallbackParameters struct {
-	Type       string   `yaml:"type"`
-	Action     string   `yaml:"action"`
-	Method     string   `yaml:"method,omitempty"`
-	Parameters []string `yaml:"parameters,omitempty"`
+	Type       string        `yaml:"type"`
+	Action     string        `yaml:"action"`
+	Method     string        `yaml:"method,omitempty"`
+	Payload    string        `yaml:"payload,omitempty"`
+	OnFailure  string        `yaml:"onFailure,omitempty"`
+	Parameters []string      `yaml:"parameters,omitempty"`
+	Timeout    time.Duration `yaml:"timeout,omitempty"`
+	RetryDelay time.Duration `yaml:"retryDelay,omitempty"`
+	Retries    int           `yaml:"retries,omitempty"`
+	Sensitive  bool          `yaml:"sensitive,omitempty"`
+	SaveOutput bool          `yaml:"saveOutput,omitempty"`
 }
 
 // Callback represents a stage-specific callback definition.
@@ -50,30 +78,62 @@ type Callback struct {
 	Post  CallbackParameters `yaml:"post,omitempty"`
 }
 
-func (c Callback) PreRun(ctx context.Context) error {
+// PreRun runs the action before the stage.
+//
+// Parameters:
+//   - ctx: The context of the build.
+//   - info: The build context passed to the action.
+//   - logger: The log the output of the command is written to, prefixed by "stage <name> pre".
+func (c Callback) PreRun(ctx context.Context, info types.CallbackInfo, logger interfaces.Logger) error {
 	if err := c.Pre.IsValid(); err != nil {
 		return err
 	}
 
-	if err := c.Pre.Run(ctx); err != nil {
+	out := newOutput(logger, fmt.Sprintf("stage %s pre", c.Stage), info, fmt.Sprintf("stage.%s.pre", c.Stage))
+	if err := c.Pre.run(ctx, infoVars(info), nil, out); err != nil {
 		return fmt.Errorf("pre run callback failed for stage %s: %w", c.Stage, err)
 	}
 
 	return nil
 }
 
-func (c Callback) PostRun(ctx context.Context) error {
+// PostRun runs the action after the stage.
+//
+// Parameters:
+//   - ctx: The context of the build.
+//   - info: The build context passed to the action, with the number of files copied by the stage.
+//   - logger: The log the output of the command is written to, prefixed by "stage <name> post".
+func (c Callback) PostRun(ctx context.Context, info types.CallbackInfo, logger interfaces.Logger) error {
 	if err := c.Post.IsValid(); err != nil {
 		return err
 	}
 
-	if err := c.Post.Run(ctx); err != nil {
+	out := newOutput(logger, fmt.Sprintf("stage %s post", c.Stage), info, fmt.Sprintf("stage.%s.post", c.Stage))
+	if err := c.Post.run(ctx, infoVars(info), nil, out); err != nil {
 		return fmt.Errorf("post run callback failed for stage %s: %w", c.Stage, err)
 	}
 
 	return nil
 }
 
+// SensitiveValues returns the values of the parameters if they are marked sensitive:
+// the part after `=` of `key=value` parameters, or the whole parameter.
+func (c *CallbackParameters) SensitiveValues() []string {
+	if !c.Sensitive {
+		return nil
+	}
+
+	values := make([]string, 0, len(c.Parameters))
+	for _, param := range c.Parameters {
+		if _, value, found := strings.Cut(param, "="); found {
+			param = value
+		}
+		values = append(values, param)
+	}
+
+	return values
+}
+
 // IsValid checks if the Callback structure is valid.
 // It ensures that the stage name is provided and that either pre or post parameters exist.
 // Additionally, it validates the pre and post parameters if they are set.
@@ -126,29 +186,68 @@ func (c *CallbackParameters) IsValid() error {
 		return err
 	}
 
+	if err := c.validatePayload(); err != nil {
+		return err
+	}
+
+	if err := c.validatePolicy(); err != nil {
+		return err
+	}
+
+	if c.SaveOutput && c.Type != CommandType {
+		return fmt.Errorf("callback saveOutput requires the '%s' type", CommandType)
+	}
+
 	return nil
 }
 
-// Run executes the callback based on its type (external or command).
-// It first validates the callback parameters, then either runs an external HTTP request
-// or a system command depending on the `Type` of the callback.
+// run executes the callback with the values of the build context.
 //
-// Parameters:
-//   - ctx (context.Context): The context for the execution, used for cancellation and timeouts.
+// The values replace the placeholders in the action URL and the parameters. Commands also
+// receive them as BX_* environment variables, and external callbacks with the JSON payload
+// receive them in the body. The extra values are appended to the parameters of the other external callbacks.
 //
-// Returns:
-//   - error: Returns an error if validation fails or if execution of the callback fails.
-func (c *CallbackParameters) Run(ctx context.Context) error {
+// Failed attempts are retried, and the failure policy of the callback is applied to the error
+// of the last attempt. The output of commands is written to out.
+func (c *CallbackParameters) run(ctx context.Context, vars, extra map[string]string, out output) error {
 	if err := c.IsValid(); err != nil {
 		return err
 	}
 
+	closeOutput, err := out.open(c)
+	if err != nil {
+		return err
+	}
+	defer closeOutput()
+
+	return c.handleFailure(c.attempt(ctx, func(ctx context.Context) error {
+		return c.execute(ctx, vars, extra, out)
+	}))
+}
+
+// execute makes a single attempt to run the callback.
+func (c *CallbackParameters) execute(ctx context.Context, vars, extra map[string]string, out output) error {
 	if c.Type == ExternalType {
-		return c.runExternal(ctx)
+		escape := noEscape
+		if c.Method == http.MethodGet {
+			escape = url.QueryEscape
+		}
+
+		external := *c
+		external.Action = expand(c.Action, vars, url.QueryEscape)
+		external.Parameters = make([]string, 0, len(c.Parameters)+len(extra))
+		for _, param := range c.Parameters {
+			external.Parameters = append(external.Parameters, expand(param, vars, escape))
+		}
+		if c.Payload != PayloadJSON {
+			external.Parameters = append(external.Parameters, params(extra, c.Method == http.MethodGet)...)
+		}
+
+		return external.runExternal(ctx, vars)
 	}
 
 	if c.Type == CommandType {
-		return c.runCommand(ctx)
+		return c.runCommand(ctx, vars, out)
 	}
 
 	return nil
@@ -242,7 +341,7 @@ func (c *CallbackParameters) validateParameters() error {
 		}
 
 		if c.Type == CommandType {
-			if !validators.ValidateArgument(param) {
+			if !validators.ValidateArgument(stripPlaceholders(param)) {
 				return fmt.Errorf("callback parameter[%d] is invalid", i)
 			}
 		}
@@ -269,31 +368,72 @@ func (c *CallbackParameters) validateParameters() error {
 	return nil
 }
 
+// validatePayload checks the payload format. The JSON payload is sent by external POST callbacks only.
+//
+// Returns:
+//   - error: An error if the format is unknown or not supported by the callback, otherwise nil.
+func (c *CallbackParameters) validatePayload() error {
+	switch c.Payload {
+	case "", PayloadForm:
+		return nil
+	case PayloadJSON:
+		if c.Type != ExternalType || c.Method != http.MethodPost {
+			return fmt.Errorf("callback payload '%s' requires the '%s' method", PayloadJSON, http.MethodPost)
+		}
+		return nil
+	default:
+		return fmt.Errorf(
+			"callback payload is invalid. allowed values are '%s' or '%s'",
+			PayloadForm,
+			PayloadJSON,
+		)
+	}
+}
+
 // runExternal executes an external HTTP request based on the callback parameters.
 // It constructs the URL and body for the request, sends the request to the specified endpoint,
 // and checks for a successful HTTP response status code (200 OK).
-// The function has a timeout of 30 seconds for the request.
+// The request is limited by the deadline of ctx; exceeding it is reported as errors.ErrCallbackTimeout.
+//
+// With the JSON payload, the body is a JSON object with the values of the build context
+// and the parameters in the `parameters` object.
 //
 // Parameters:
 //   - ctx (context.Context): The context for the execution, used for cancellation and timeouts.
+//   - vars (map[string]string): The values of the build context.
 //
 // Returns:
 //   - error: Returns an error if the request fails or if the response status code is not OK.
-func (c *CallbackParameters) runExternal(ctx context.Context) error {
-	ttlCtx, cancelFunc := context.WithTimeout(ctx, 30*time.Second)
-	defer cancelFunc()
-
+func (c *CallbackParameters) runExternal(ctx context.Context, vars map[string]string) error {
 	u, body := c.buildUrlAndBody()
-	req, err := http.NewRequestWithContext(ttlCtx, c.Method, u, body)
+	contentType := "application/x-www-form-urlencoded"
+
+	if c.Payload == PayloadJSON {
+		data, err := jsonPayload(vars, c.Parameters)
+		if err != nil {
+			return err
+		}
+
+		u, body, contentType = c.Action, bytes.NewReader(data), "application/json"
+	}
+
+	req, err := http.NewRequestWithContext(ctx, c.Method, u, body)
 	if err != nil {
 		return err
 	}
 
+	if body != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+
 	client := &http.Client{}
 
 	//nolint:bodyclose
 	resp, err := client.Do(req)
 	if err != nil {
+		if ctx.Err() != nil {
+			return c.timeoutError(ctx)
+		}
 		return err
 	}
 
@@ -308,39 +448,63 @@ func (c *CallbackParameters) runExternal(ctx context.Context) error {
 
 // runCommand executes a command defined in the callback parameters.
 // It validates the command arguments, starts the command execution asynchronously,
-// and logs the output (stdout and stderr). The function has a 30-second deadline for execution.
-// If the command fails or the context is cancelled, it handles the errors accordingly.
+// and waits for it to exit. The stdout and stderr lines of the command are streamed to out,
+// and the exit code is recorded there. A non-zero exit code is reported as errors.ErrCallbackExitCode
+// with the last lines of stderr.
+// When the deadline of ctx is exceeded or ctx is cancelled, the command is stopped (and killed
+// if it does not exit), and errors.ErrCallbackTimeout or the error of ctx is returned.
 //
 // Parameters:
 //   - ctx (context.Context): The context for execution, used for cancellation and deadlines.
+//   - vars (map[string]string): The values of the build context. They replace the placeholders
+//     in the arguments and are added to the environment of the process as BX_* variables.
+//   - out (output): The log and the file the output of the command is written to.
 //
 // Returns:
 //   - error: Returns an error if the command execution fails, arguments are invalid, or the context is cancelled.
-func (c *CallbackParameters) runCommand(ctx context.Context) error {
-	ctx, cancelFunc := context.WithDeadline(ctx, time.Now().Add(30*time.Second))
-	defer cancelFunc()
-
+func (c *CallbackParameters) runCommand(ctx context.Context, vars map[string]string, out output) error {
 	rawCommand, _ := c.buildUrlAndBody()
 	args := strings.Fields(rawCommand)
 
-	for _, arg := range args[1:] {
-		if !validators.ValidateArgument(arg) {
+	for i, arg := range args[1:] {
+		if !validators.ValidateArgument(stripPlaceholders(arg)) {
 			return fmt.Errorf("invalid callback CommandType argument '%s'", arg)
 		}
+		args[i+1] = expand(arg, vars, noEscape)
 	}
 
-	com := cmd.NewCmd(args[0], args[1:]...)
+	com := cmd.NewCmdOptions(cmd.Options{Streaming: true}, args[0], args[1:]...)
+	if len(vars) > 0 {
+		com.Env = append(os.Environ(), envVars(vars)...)
+	}
 	statusChan := com.Start()
 
+	tailChan := make(chan []string, 1)
+	go func() {
+		tailChan <- out.stream(com.Stdout, com.Stderr)
+	}()
+
 	select {
 	case status := <-statusChan:
+		tail := <-tailChan
 		if status.Error != nil {
-			return fmt.Errorf("callback CommandType failed: %w", status.Error)
+			return fmt.Errorf("callback command %s failed: %w", args[0], status.Error)
+		}
+
+		out.exited(args[0], status.Exit)
+		if status.Exit != 0 {
+			if len(tail) > 0 {
+				return fmt.Errorf("%w: %d, stderr:\n%s", errors.ErrCallbackExitCode, status.Exit, strings.Join(tail, "\n"))
+			}
+			return fmt.Errorf("%w: %d", errors.ErrCallbackExitCode, status.Exit)
 		}
 	case <-ctx.Done():
-		if err := com.Stop(); err != nil {
-			return fmt.Errorf("callback CommandType failed: %w", err)
+		stopCommand(com, statusChan)
+		select {
+		case <-tailChan:
+		case <-time.After(stopGracePeriod):
 		}
+		return c.timeoutError(ctx)
 	}
 
 	return nil
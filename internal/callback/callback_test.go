@@ -2,14 +2,20 @@ package callback
 
 import (
 	"context"
+	"fmt"
 	"io"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/pixel365/bx/internal/types"
 )
 
 func TestCallback_IsValid(t *testing.T) {
@@ -203,6 +209,7 @@ func TestCallbackParameters_IsValid(t *testing.T) {
 		Type       string
 		Action     string
 		Method     string
+		Payload    string
 		Parameters []string
 	}
 	tests := []struct {
@@ -254,6 +261,46 @@ func TestCallbackParameters_IsValid(t *testing.T) {
 			},
 			true,
 		},
+		{
+			"placeholders",
+			fields{
+				Type:       ExternalType,
+				Action:     "https://example.com/{module}/{version}",
+				Method:     http.MethodGet,
+				Parameters: []string{"zip={zipPath}"},
+			},
+			false,
+		},
+		{
+			"command placeholders",
+			fields{Type: CommandType, Action: "cp", Parameters: []string{"{zipPath}", "/tmp/{module}-{version}.zip"}},
+			false,
+		},
+		{
+			"unknown command placeholder",
+			fields{Type: CommandType, Action: "cp", Parameters: []string{"{path}"}},
+			true,
+		},
+		{
+			"json payload",
+			fields{Type: ExternalType, Action: "https://example.com", Method: http.MethodPost, Payload: PayloadJSON},
+			false,
+		},
+		{
+			"json payload with GET",
+			fields{Type: ExternalType, Action: "https://example.com", Method: http.MethodGet, Payload: PayloadJSON},
+			true,
+		},
+		{
+			"json payload of command",
+			fields{Type: CommandType, Action: "ls", Payload: PayloadJSON},
+			true,
+		},
+		{
+			"invalid payload",
+			fields{Type: ExternalType, Action: "https://example.com", Method: http.MethodPost, Payload: "xml"},
+			true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -261,6 +308,7 @@ func TestCallbackParameters_IsValid(t *testing.T) {
 				Type:       tt.fields.Type,
 				Action:     tt.fields.Action,
 				Method:     tt.fields.Method,
+				Payload:    tt.fields.Payload,
 				Parameters: tt.fields.Parameters,
 			}
 			err := c.IsValid()
@@ -363,6 +411,14 @@ func TestCallbackParameters_buildUrlAndBody(t *testing.T) {
 	}
 }
 
+func TestCallbackParameters_SensitiveValues(t *testing.T) {
+	params := CallbackParameters{Parameters: []string{"token=abc123", "--verbose", "url=https://example.com/?a=b"}}
+	assert.Nil(t, params.SensitiveValues(), "parameters are not sensitive by default")
+
+	params.Sensitive = true
+	assert.Equal(t, []string{"abc123", "--verbose", "https://example.com/?a=b"}, params.SensitiveValues())
+}
+
 func TestValidateCallbacks(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -394,20 +450,118 @@ func TestValidateCallbacks(t *testing.T) {
 func TestInvalidCallbackParametersRun(t *testing.T) {
 	ctx := context.TODO()
 	cbp := CallbackParameters{}
-	err := cbp.Run(ctx)
+	err := cbp.run(ctx, nil, nil, output{})
 	require.Error(t, err)
 }
 
 func TestCallback_PreRun(t *testing.T) {
 	ctx := context.TODO()
 	cb := Callback{}
-	err := cb.PreRun(ctx)
+	err := cb.PreRun(ctx, types.CallbackInfo{}, nil)
 	require.Error(t, err)
 }
 
 func TestCallback_PostRun(t *testing.T) {
 	ctx := context.TODO()
 	cb := Callback{}
-	err := cb.PostRun(ctx)
+	err := cb.PostRun(ctx, types.CallbackInfo{}, nil)
 	require.Error(t, err)
 }
+
+func testInfo(t *testing.T) types.CallbackInfo {
+	t.Helper()
+
+	dir := t.TempDir()
+
+	return types.CallbackInfo{
+		Module:     "vendor.module",
+		Version:    "1.1.0",
+		Label:      "beta",
+		Stage:      "components",
+		VersionDir: filepath.Join(dir, "1.1.0"),
+		ZipPath:    filepath.Join(dir, "1.1.0.zip"),
+		Commit:     "0123abcd",
+		Files:      12,
+	}
+}
+
+func TestCallbackParameters_run_placeholders(t *testing.T) {
+	t.Parallel()
+
+	var got *url.URL
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.URL
+	}))
+	defer server.Close()
+
+	cb := CallbackParameters{
+		Type:       ExternalType,
+		Action:     server.URL + "/{module}/{version}",
+		Method:     http.MethodGet,
+		Parameters: []string{"label={label}", "files={files}", "commit={commit}", "custom={custom}"},
+	}
+	require.NoError(t, cb.run(context.Background(), infoVars(testInfo(t)), nil, output{}))
+
+	require.NotNil(t, got)
+	assert.Equal(t, "/vendor.module/1.1.0", got.Path)
+	assert.Equal(t, url.Values{
+		"label":  {"beta"},
+		"files":  {"12"},
+		"commit": {"0123abcd"},
+		"custom": {"{custom}"},
+	}, got.Query())
+}
+
+func TestCallbackParameters_run_json(t *testing.T) {
+	t.Parallel()
+
+	var contentType string
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer server.Close()
+
+	info := testInfo(t)
+	cb := CallbackParameters{
+		Type:       ExternalType,
+		Action:     server.URL,
+		Method:     http.MethodPost,
+		Payload:    PayloadJSON,
+		Parameters: []string{"text=built {module} {version}"},
+	}
+	require.NoError(t, cb.run(context.Background(), infoVars(info), nil, output{}))
+
+	assert.Equal(t, "application/json", contentType)
+	assert.JSONEq(t, fmt.Sprintf(`{
+		"module": "vendor.module",
+		"version": "1.1.0",
+		"label": "beta",
+		"stage": "components",
+		"versionDir": %q,
+		"zipPath": %q,
+		"commit": "0123abcd",
+		"files": 12,
+		"parameters": {"text": "built vendor.module 1.1.0"}
+	}`, info.VersionDir, info.ZipPath), string(body))
+}
+
+func TestCallbackParameters_run_command(t *testing.T) {
+	t.Parallel()
+
+	info := testInfo(t)
+	require.NoError(t, os.WriteFile(info.ZipPath, []byte("zip"), 0o600))
+	require.NoError(t, os.Mkdir(info.VersionDir, 0o750))
+
+	cb := CallbackParameters{
+		Type:       CommandType,
+		Action:     "cp",
+		Parameters: []string{"{zipPath}", "{versionDir}/{module}-{version}.zip"},
+	}
+	require.NoError(t, cb.run(context.Background(), infoVars(info), nil, output{}))
+
+	data, err := os.ReadFile(filepath.Join(info.VersionDir, "vendor.module-1.1.0.zip"))
+	require.NoError(t, err)
+	assert.Equal(t, "zip", string(data))
+}
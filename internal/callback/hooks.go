@@ -0,0 +1,184 @@
+package callback
+
+import (
+	"context"
+	errs "errors"
+	"fmt"
+	"maps"
+
+	"github.com/pixel365/bx/internal/interfaces"
+	"github.com/pixel365/bx/internal/redact"
+	"github.com/pixel365/bx/internal/types"
+)
+
+// Lifecycle hooks of a module.
+const (
+	BeforeBuild    = "beforeBuild"
+	AfterBuild     = "afterBuild"
+	OnBuildFailure = "onBuildFailure"
+	BeforePush     = "beforePush"
+	AfterPush      = "afterPush"
+	AfterLabel     = "afterLabel"
+)
+
+// Outcome statuses passed to the hooks.
+const (
+	StatusSuccess = "success"
+	StatusFailure = "failure"
+)
+
+// Hooks defines the actions run at the points of the module lifecycle.
+//
+// Unlike stage callbacks, hooks are bound to the whole build, push and label change.
+// Each hook is a list of actions of the same types as the stage callbacks, run in order.
+//
+// Fields:
+//   - BeforeBuild:    Run before the build. A failure cancels the build.
+//   - AfterBuild:     Run after the build, whatever its outcome.
+//   - OnBuildFailure: Run after a failed build.
+//   - BeforePush:     Run before the upload. A failure cancels the push.
+//   - AfterPush:      Run after the push, whatever its outcome.
+//   - AfterLabel:     Run after the labels of the versions are changed.
+type Hooks struct {
+	BeforeBuild    []CallbackParameters `yaml:"beforeBuild,omitempty"`
+	AfterBuild     []CallbackParameters `yaml:"afterBuild,omitempty"`
+	OnBuildFailure []CallbackParameters `yaml:"onBuildFailure,omitempty"`
+	BeforePush     []CallbackParameters `yaml:"beforePush,omitempty"`
+	AfterPush      []CallbackParameters `yaml:"afterPush,omitempty"`
+	AfterLabel     []CallbackParameters `yaml:"afterLabel,omitempty"`
+}
+
+// Event describes the point of the lifecycle the hook actions are run at.
+//
+// Fields:
+//   - Err:    The error of the operation; nil if it succeeded or has not run yet.
+//   - Logger: The log the output of the commands is written to; nil discards the output.
+//   - Hook:   The name of the hook, e.g. "afterBuild".
+//   - Info:   The build the operation is run for.
+type Event struct {
+	Err    error
+	Logger interfaces.Logger
+	Hook   string
+	Info   types.CallbackInfo
+}
+
+// Status returns the outcome status of the operation: "success" or "failure".
+func (e Event) Status() string {
+	if e.Err != nil {
+		return StatusFailure
+	}
+
+	return StatusSuccess
+}
+
+// outcome returns the name of the hook, the outcome status and, after a failure,
+// the masked error message.
+func (e Event) outcome() map[string]string {
+	outcome := map[string]string{
+		"hook":   e.Hook,
+		"status": e.Status(),
+	}
+
+	if e.Err != nil {
+		outcome["error"] = redact.String(e.Err.Error())
+	}
+
+	return outcome
+}
+
+// Actions returns the actions of the hook. It returns nil for an unknown hook or nil hooks.
+func (h *Hooks) Actions(hook string) []CallbackParameters {
+	if h == nil {
+		return nil
+	}
+
+	switch hook {
+	case BeforeBuild:
+		return h.BeforeBuild
+	case AfterBuild:
+		return h.AfterBuild
+	case OnBuildFailure:
+		return h.OnBuildFailure
+	case BeforePush:
+		return h.BeforePush
+	case AfterPush:
+		return h.AfterPush
+	case AfterLabel:
+		return h.AfterLabel
+	default:
+		return nil
+	}
+}
+
+// names returns the names of all hooks in the order of the lifecycle.
+func names() []string {
+	return []string{BeforeBuild, AfterBuild, OnBuildFailure, BeforePush, AfterPush, AfterLabel}
+}
+
+// SensitiveValues returns the values of the sensitive parameters of all hook actions.
+func (h *Hooks) SensitiveValues() []string {
+	var values []string
+	for _, hook := range names() {
+		for _, action := range h.Actions(hook) {
+			values = append(values, action.SensitiveValues()...)
+		}
+	}
+
+	return values
+}
+
+// IsValid validates the actions of all hooks.
+//
+// Returns:
+//   - error: An error naming the hook and the index of the first invalid action; otherwise nil.
+func (h *Hooks) IsValid() error {
+	for _, hook := range names() {
+		for i, action := range h.Actions(hook) {
+			if err := action.IsValid(); err != nil {
+				return fmt.Errorf("hook %s [%d]: %w", hook, i, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Run runs the actions of the hook of the event in order.
+//
+// Every action is run even if a previous one failed. The actions receive the build context
+// as stage callbacks do, together with the name of the hook, the outcome status and the error
+// message: commands in the BX_HOOK, BX_STATUS and BX_ERROR environment variables, external
+// actions in the hook, status and error parameters. The output of the commands is written
+// to the log of the event, prefixed by "hook <name> [<index>]".
+//
+// Parameters:
+//   - ctx: The context of the operation, used for cancellation.
+//   - event: The hook and the outcome of the operation.
+//
+// Returns:
+//   - error: The joined errors of the failed actions, or nil.
+func (h *Hooks) Run(ctx context.Context, event Event) error {
+	actions := h.Actions(event.Hook)
+	if len(actions) == 0 {
+		return nil
+	}
+
+	outcome := event.outcome()
+	vars := infoVars(event.Info)
+	maps.Copy(vars, outcome)
+
+	var failed []error
+	for i := range actions {
+		out := newOutput(
+			event.Logger,
+			fmt.Sprintf("hook %s [%d]", event.Hook, i),
+			event.Info,
+			fmt.Sprintf("hook.%s.%d", event.Hook, i),
+		)
+		if err := actions[i].run(ctx, vars, outcome, out); err != nil {
+			failed = append(failed, fmt.Errorf("hook %s [%d] failed: %w", event.Hook, i, err))
+		}
+	}
+
+	return errs.Join(failed...)
+}
@@ -0,0 +1,211 @@
+package callback
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pixel365/bx/internal/types"
+)
+
+// hookServer records the parameters of the requests it receives.
+type hookServer struct {
+	*httptest.Server
+	requests []url.Values
+	mu       sync.Mutex
+}
+
+func newHookServer(t *testing.T, status int) *hookServer {
+	t.Helper()
+
+	s := &hookServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+
+		s.mu.Lock()
+		s.requests = append(s.requests, r.Form)
+		s.mu.Unlock()
+
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+func (s *hookServer) received() []url.Values {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.requests
+}
+
+func TestHooks_Actions(t *testing.T) {
+	t.Parallel()
+
+	action := CallbackParameters{Type: CommandType, Action: "true"}
+	hooks := &Hooks{
+		BeforeBuild:    []CallbackParameters{action},
+		AfterBuild:     []CallbackParameters{action, action},
+		OnBuildFailure: []CallbackParameters{action},
+		BeforePush:     []CallbackParameters{action},
+		AfterPush:      []CallbackParameters{action},
+		AfterLabel:     []CallbackParameters{action},
+	}
+
+	for _, hook := range names() {
+		assert.NotEmpty(t, hooks.Actions(hook), hook)
+	}
+
+	assert.Len(t, hooks.Actions(AfterBuild), 2)
+	assert.Nil(t, hooks.Actions("beforeStage"))
+
+	var empty *Hooks
+	assert.Nil(t, empty.Actions(BeforeBuild))
+}
+
+func TestHooks_IsValid(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		hooks   *Hooks
+		name    string
+		wantErr string
+	}{
+		{nil, "nil hooks", ""},
+		{&Hooks{}, "no actions", ""},
+		{
+			&Hooks{AfterPush: []CallbackParameters{
+				{Type: ExternalType, Action: "https://example.com", Method: http.MethodPost},
+			}},
+			"valid",
+			"",
+		},
+		{
+			&Hooks{AfterLabel: []CallbackParameters{
+				{Type: CommandType, Action: "true"},
+				{Type: ExternalType, Action: "https://example.com"},
+			}},
+			"invalid action",
+			"hook afterLabel [1]: callback method is required",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := tt.hooks.IsValid()
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+
+			require.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestHooks_SensitiveValues(t *testing.T) {
+	t.Parallel()
+
+	hooks := &Hooks{
+		BeforePush: []CallbackParameters{{Parameters: []string{"token=abc123"}, Sensitive: true}},
+		AfterPush:  []CallbackParameters{{Parameters: []string{"chat=release"}}},
+	}
+
+	assert.Equal(t, []string{"abc123"}, hooks.SensitiveValues())
+
+	var empty *Hooks
+	assert.Empty(t, empty.SensitiveValues())
+}
+
+func TestHooks_Run(t *testing.T) {
+	t.Parallel()
+
+	server := newHookServer(t, http.StatusOK)
+	hooks := &Hooks{
+		AfterBuild: []CallbackParameters{
+			{Type: ExternalType, Action: server.URL, Method: http.MethodPost, Parameters: []string{"chat=release"}},
+			{Type: ExternalType, Action: server.URL + "?chat=builds", Method: http.MethodGet},
+		},
+	}
+
+	require.NoError(t, hooks.Run(context.Background(), Event{Hook: AfterBuild}))
+	require.NoError(t, hooks.Run(context.Background(), Event{Hook: AfterBuild, Err: errors.New("stage a & b failed")}))
+	require.NoError(t, hooks.Run(context.Background(), Event{Hook: AfterPush}), "no actions")
+
+	requests := server.received()
+	require.Len(t, requests, 4)
+
+	assert.Equal(t, url.Values{"chat": {"release"}, "hook": {"afterBuild"}, "status": {"success"}}, requests[0])
+	assert.Equal(t, url.Values{"chat": {"builds"}, "hook": {"afterBuild"}, "status": {"success"}}, requests[1])
+
+	for _, r := range requests[2:] {
+		assert.Equal(t, "failure", r.Get("status"))
+		assert.Equal(t, "stage a & b failed", r.Get("error"))
+	}
+}
+
+func TestHooks_Run_failure(t *testing.T) {
+	t.Parallel()
+
+	failing := newHookServer(t, http.StatusInternalServerError)
+	working := newHookServer(t, http.StatusOK)
+	hooks := &Hooks{
+		BeforePush: []CallbackParameters{
+			{Type: ExternalType, Action: failing.URL, Method: http.MethodPost},
+			{Type: ExternalType, Action: working.URL, Method: http.MethodPost},
+		},
+	}
+
+	err := hooks.Run(context.Background(), Event{Hook: BeforePush})
+	require.EqualError(t, err, "hook beforePush [0] failed: callback returned status code 500")
+	assert.Len(t, working.received(), 1, "the next actions are run after a failure")
+}
+
+func TestEvent_Status(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, StatusSuccess, Event{Hook: AfterPush}.Status())
+	assert.Equal(t, StatusFailure, Event{Hook: AfterPush, Err: errors.New("upload failed")}.Status())
+}
+
+func TestHooks_Run_json(t *testing.T) {
+	t.Parallel()
+
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer server.Close()
+
+	hooks := &Hooks{
+		AfterPush: []CallbackParameters{
+			{Type: ExternalType, Action: server.URL, Method: http.MethodPost, Payload: PayloadJSON},
+		},
+	}
+
+	event := Event{
+		Hook: AfterPush,
+		Err:  errors.New("upload failed"),
+		Info: types.CallbackInfo{Module: "vendor.module", Version: "1.1.0", Label: "beta"},
+	}
+	require.NoError(t, hooks.Run(context.Background(), event))
+
+	assert.JSONEq(t, `{
+		"module": "vendor.module",
+		"version": "1.1.0",
+		"label": "beta",
+		"hook": "afterPush",
+		"status": "failure",
+		"error": "upload failed"
+	}`, string(body))
+}
@@ -0,0 +1,130 @@
+package callback
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pixel365/bx/internal/helpers"
+	"github.com/pixel365/bx/internal/interfaces"
+	"github.com/pixel365/bx/internal/redact"
+	"github.com/pixel365/bx/internal/types"
+)
+
+// stderrTailSize is the number of the last stderr lines added to the error of a failed command.
+const stderrTailSize = 5
+
+// output receives the output of command callbacks.
+//
+// Fields:
+//   - logger: The log the output lines and the exit code are written to; nil discards them.
+//   - w:      The file the full output is saved to; nil if it is not saved.
+//   - prefix: The prefix of the log messages, e.g. "stage components pre" or "hook afterBuild [0]".
+//   - file:   The path of the file for the full output; empty without a log directory.
+type output struct {
+	logger interfaces.Logger
+	w      io.Writer
+	prefix string
+	file   string
+}
+
+// newOutput returns the output of a callback of the build. With a log directory in info,
+// the full output can be saved there to the file "<module>.<name>.log".
+func newOutput(logger interfaces.Logger, prefix string, info types.CallbackInfo, name string) output {
+	out := output{logger: logger, prefix: prefix}
+	if info.LogDir != "" {
+		out.file = filepath.Join(info.LogDir, fileName(info.Module+"."+name)+".log")
+	}
+
+	return out
+}
+
+// fileName replaces the characters that are not allowed in file names with underscores.
+func fileName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+}
+
+// open creates the file the full output of the callback is saved to, if the callback saves
+// its output. The file is truncated, so it holds the output of the last run of the callback.
+//
+// Returns:
+//   - func(): Closes the file.
+//   - error: An error if the file cannot be created.
+func (o *output) open(c *CallbackParameters) (func(), error) {
+	if !c.SaveOutput || c.Type != CommandType || o.file == "" {
+		return func() {}, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(o.file), 0750); err != nil {
+		return nil, fmt.Errorf("failed to create the callback output directory: %w", err)
+	}
+
+	file, err := os.Create(filepath.Clean(o.file))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the callback output file: %w", err)
+	}
+
+	o.w = file
+
+	return func() {
+		helpers.Cleanup(file, nil)
+	}, nil
+}
+
+// stream writes the lines of the command output to the log and the output file
+// until both channels are closed. Secrets in the lines are masked.
+//
+// Returns:
+//   - []string: The last lines of stderr.
+func (o *output) stream(stdout, stderr <-chan string) []string {
+	var tail []string
+	for stdout != nil || stderr != nil {
+		select {
+		case line, ok := <-stdout:
+			if !ok {
+				stdout = nil
+				continue
+			}
+			o.line(line, "")
+		case line, ok := <-stderr:
+			if !ok {
+				stderr = nil
+				continue
+			}
+			o.line(line, " [stderr]")
+			tail = append(tail, line)
+			if len(tail) > stderrTailSize {
+				tail = tail[1:]
+			}
+		}
+	}
+
+	return tail
+}
+
+// line writes a line of the command output.
+func (o *output) line(line, stream string) {
+	if o.logger != nil {
+		o.logger.Info("%s%s: %s", o.prefix, stream, line)
+	}
+
+	if o.w != nil {
+		_, _ = fmt.Fprintln(o.w, redact.String(line))
+	}
+}
+
+// exited records the exit code of the command.
+func (o *output) exited(command string, code int) {
+	if o.logger != nil {
+		o.logger.Info("%s: command %s exited with code %d", o.prefix, command, code)
+	}
+}
@@ -0,0 +1,167 @@
+package callback
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	errors2 "github.com/pixel365/bx/internal/errors"
+	"github.com/pixel365/bx/internal/types"
+)
+
+// testLogger records the messages it receives.
+type testLogger struct {
+	messages []string
+	mu       sync.Mutex
+}
+
+func (l *testLogger) Info(message string, args ...any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.messages = append(l.messages, fmt.Sprintf(message, args...))
+}
+
+func (l *testLogger) Error(message string, err error, args ...any) {
+	l.Info(fmt.Sprintf("%s: %v", message, err), args...)
+}
+
+func (l *testLogger) logs() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.messages
+}
+
+// writeScript writes an executable shell script to a temporary directory and returns its path.
+func writeScript(t *testing.T, script string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "callback.sh")
+	require.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\n"+script), 0700))
+
+	return path
+}
+
+func Test_newOutput(t *testing.T) {
+	t.Parallel()
+
+	info := types.CallbackInfo{Module: "vendor.module", LogDir: "/var/log/bx"}
+	out := newOutput(nil, "stage components pre", info, "stage.install/db files.pre")
+
+	assert.Equal(t, "stage components pre", out.prefix)
+	assert.Equal(t, "/var/log/bx/vendor.module.stage.install_db_files.pre.log", out.file)
+
+	out = newOutput(nil, "hook afterBuild [0]", types.CallbackInfo{Module: "vendor.module"}, "hook.afterBuild.0")
+	assert.Empty(t, out.file, "no log directory")
+}
+
+func TestCallback_PostRun_output(t *testing.T) {
+	t.Parallel()
+
+	script := writeScript(t, `echo "copied $BX_FILES files"
+echo "token=abc123"
+echo "warning: slow disk" >&2
+echo "cannot upload" >&2
+exit 3
+`)
+
+	logDir := t.TempDir()
+	logger := &testLogger{}
+	cb := Callback{
+		Stage: "components",
+		Post:  CallbackParameters{Type: CommandType, Action: script, SaveOutput: true},
+	}
+	info := types.CallbackInfo{Module: "vendor.module", Stage: "components", Files: 42, LogDir: logDir}
+
+	err := cb.PostRun(context.Background(), info, logger)
+	require.ErrorIs(t, err, errors2.ErrCallbackExitCode)
+	assert.EqualError(t, err, "post run callback failed for stage components: "+
+		"callback command exited with a non-zero code: 3, stderr:\nwarning: slow disk\ncannot upload")
+
+	logs := logger.logs()
+	assert.Contains(t, logs, "stage components post: copied 42 files")
+	assert.Contains(t, logs, "stage components post [stderr]: warning: slow disk")
+	assert.Contains(t, logs, "stage components post [stderr]: cannot upload")
+	assert.Equal(t, fmt.Sprintf("stage components post: command %s exited with code 3", script), logs[len(logs)-1])
+
+	data, err := os.ReadFile(filepath.Join(logDir, "vendor.module.stage.components.post.log"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "copied 42 files\n")
+	assert.Contains(t, string(data), "cannot upload\n")
+}
+
+func TestCallbackParameters_run_saveOutput(t *testing.T) {
+	t.Parallel()
+
+	logDir := t.TempDir()
+	out := newOutput(nil, "hook afterBuild [0]", types.CallbackInfo{Module: "vendor.module", LogDir: logDir},
+		"hook.afterBuild.0")
+
+	c := CallbackParameters{Type: CommandType, Action: writeScript(t, "echo done\n")}
+	require.NoError(t, c.run(context.Background(), nil, nil, out))
+	assert.NoFileExists(t, out.file, "the output is not saved without saveOutput")
+
+	c.SaveOutput = true
+	require.NoError(t, c.run(context.Background(), nil, nil, out))
+	require.NoError(t, c.run(context.Background(), nil, nil, out))
+
+	data, err := os.ReadFile(out.file)
+	require.NoError(t, err)
+	assert.Equal(t, "done\n", string(data), "the file holds the output of the last run")
+}
+
+func TestCallbackParameters_run_stderrTail(t *testing.T) {
+	t.Parallel()
+
+	c := CallbackParameters{
+		Type:   CommandType,
+		Action: writeScript(t, "for i in 1 2 3 4 5 6 7; do echo \"line $i\" >&2; done\nexit 1\n"),
+	}
+
+	err := c.run(context.Background(), nil, nil, output{})
+	require.ErrorIs(t, err, errors2.ErrCallbackExitCode)
+	assert.EqualError(t, err,
+		"callback command exited with a non-zero code: 1, stderr:\nline 3\nline 4\nline 5\nline 6\nline 7")
+}
+
+func TestCallbackParameters_run_notFound(t *testing.T) {
+	t.Parallel()
+
+	c := CallbackParameters{Type: CommandType, Action: "bx-callback-not-found"}
+
+	err := c.run(context.Background(), nil, nil, output{})
+	require.ErrorContains(t, err, "callback command bx-callback-not-found failed: ")
+	assert.False(t, errors.Is(err, errors2.ErrCallbackExitCode))
+}
+
+func TestCallbackParameters_IsValid_saveOutput(t *testing.T) {
+	t.Parallel()
+
+	c := CallbackParameters{Type: CommandType, Action: "true", SaveOutput: true}
+	require.NoError(t, c.IsValid())
+
+	c = CallbackParameters{Type: ExternalType, Action: "https://example.com", Method: "GET", SaveOutput: true}
+	require.EqualError(t, c.IsValid(), "callback saveOutput requires the 'command' type")
+}
+
+func TestHooks_Run_output(t *testing.T) {
+	t.Parallel()
+
+	logger := &testLogger{}
+	hooks := &Hooks{
+		AfterBuild: []CallbackParameters{
+			{Type: CommandType, Action: writeScript(t, "echo \"$BX_HOOK: $BX_STATUS\"\n")},
+		},
+	}
+
+	require.NoError(t, hooks.Run(context.Background(), Event{Hook: AfterBuild, Logger: logger}))
+	assert.Contains(t, logger.logs(), "hook afterBuild [0]: afterBuild: success")
+}
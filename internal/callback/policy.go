@@ -0,0 +1,167 @@
+package callback
+
+import (
+	"context"
+	errs "errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/go-cmd/cmd"
+
+	"github.com/pixel365/bx/internal/errors"
+	"github.com/pixel365/bx/internal/redact"
+)
+
+const (
+	// DefaultTimeout limits a single attempt of a callback.
+	DefaultTimeout = 30 * time.Second
+	// DefaultRetryDelay is the delay between the attempts of a callback.
+	DefaultRetryDelay = time.Second
+	// FinalHookTimeout limits the hooks run after an operation, e.g. afterBuild.
+	// They are not canceled with the operation, so a canceled build is still reported.
+	FinalHookTimeout = 5 * time.Minute
+)
+
+// Failure policies of callbacks.
+const (
+	OnFailureFail   = "fail"
+	OnFailureWarn   = "warn"
+	OnFailureIgnore = "ignore"
+)
+
+var (
+	// stopGracePeriod is the time a stopped command is given to exit before it is killed.
+	stopGracePeriod = 5 * time.Second
+	// warnWriter receives the warnings about the failed callbacks with the warn policy.
+	warnWriter io.Writer = os.Stderr
+)
+
+// timeout returns the limit of a single attempt of the callback.
+func (c *CallbackParameters) timeout() time.Duration {
+	if c.Timeout <= 0 {
+		return DefaultTimeout
+	}
+
+	return c.Timeout
+}
+
+// retryDelay returns the delay between the attempts of the callback.
+func (c *CallbackParameters) retryDelay() time.Duration {
+	if c.RetryDelay <= 0 {
+		return DefaultRetryDelay
+	}
+
+	return c.RetryDelay
+}
+
+// validatePolicy checks the timeout, the retries and the failure policy of the callback.
+//
+// Returns:
+//   - error: An error if a value is negative or the failure policy is unknown, otherwise nil.
+func (c *CallbackParameters) validatePolicy() error {
+	if c.Timeout < 0 {
+		return fmt.Errorf("callback timeout must not be negative")
+	}
+
+	if c.Retries < 0 {
+		return fmt.Errorf("callback retries must not be negative")
+	}
+
+	if c.RetryDelay < 0 {
+		return fmt.Errorf("callback retryDelay must not be negative")
+	}
+
+	switch c.OnFailure {
+	case "", OnFailureFail, OnFailureWarn, OnFailureIgnore:
+		return nil
+	default:
+		return fmt.Errorf(
+			"callback onFailure is invalid. allowed values are '%s', '%s' or '%s'",
+			OnFailureFail,
+			OnFailureWarn,
+			OnFailureIgnore,
+		)
+	}
+}
+
+// attempt runs fn up to 1 + Retries times, waiting RetryDelay between the attempts.
+// Every attempt is limited by the timeout of the callback. The attempts stop when ctx is done.
+//
+// Returns:
+//   - error: nil after a successful attempt, otherwise the error of the last attempt.
+func (c *CallbackParameters) attempt(ctx context.Context, fn func(ctx context.Context) error) error {
+	var err error
+	for i := 0; i <= c.Retries; i++ {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				return err
+			case <-time.After(c.retryDelay()):
+			}
+		}
+
+		attemptCtx, cancel := context.WithTimeout(ctx, c.timeout())
+		err = fn(attemptCtx)
+		cancel()
+
+		if err == nil || ctx.Err() != nil {
+			return err
+		}
+	}
+
+	if c.Retries > 0 {
+		return fmt.Errorf("%d attempts failed: %w", c.Retries+1, err)
+	}
+
+	return err
+}
+
+// handleFailure applies the failure policy of the callback to the error of its last attempt.
+// With the warn policy, the error is reported to warnWriter; with the ignore policy, it is dropped.
+// Cancellation of the build is never dropped.
+func (c *CallbackParameters) handleFailure(err error) error {
+	if err == nil || errs.Is(err, context.Canceled) {
+		return err
+	}
+
+	switch c.OnFailure {
+	case OnFailureWarn:
+		_, _ = fmt.Fprintf(warnWriter, "Warning: %v\n", redact.Error(err))
+		return nil
+	case OnFailureIgnore:
+		return nil
+	default:
+		return err
+	}
+}
+
+// timeoutError returns the error of an attempt that exceeded the timeout, or the error
+// of the parent context if the build was canceled.
+func (c *CallbackParameters) timeoutError(ctx context.Context) error {
+	if errs.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("%w after %s", errors.ErrCallbackTimeout, c.timeout())
+	}
+
+	return ctx.Err()
+}
+
+// stopCommand stops the command: its process group receives SIGTERM and is killed
+// if the command does not exit within stopGracePeriod. It waits for the command to be reaped.
+func stopCommand(com *cmd.Cmd, statusChan <-chan cmd.Status) {
+	_ = com.Stop()
+
+	select {
+	case <-statusChan:
+		return
+	case <-time.After(stopGracePeriod):
+	}
+
+	killProcessGroup(com.Status().PID)
+
+	select {
+	case <-statusChan:
+	case <-time.After(stopGracePeriod):
+	}
+}
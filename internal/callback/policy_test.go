@@ -0,0 +1,237 @@
+package callback
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-cmd/cmd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	errors2 "github.com/pixel365/bx/internal/errors"
+)
+
+func TestCallbackParameters_validatePolicy(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		params  CallbackParameters
+		wantErr bool
+	}{
+		{"defaults", CallbackParameters{}, false},
+		{"valid", CallbackParameters{Timeout: time.Minute, Retries: 2, RetryDelay: time.Second, OnFailure: OnFailureWarn}, false},
+		{"fail", CallbackParameters{OnFailure: OnFailureFail}, false},
+		{"ignore", CallbackParameters{OnFailure: OnFailureIgnore}, false},
+		{"negative timeout", CallbackParameters{Timeout: -time.Second}, true},
+		{"negative retries", CallbackParameters{Retries: -1}, true},
+		{"negative retry delay", CallbackParameters{RetryDelay: -time.Second}, true},
+		{"unknown policy", CallbackParameters{OnFailure: "retry"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := tt.params.validatePolicy()
+			if tt.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestCallbackParameters_defaults(t *testing.T) {
+	t.Parallel()
+
+	c := CallbackParameters{}
+	assert.Equal(t, DefaultTimeout, c.timeout())
+	assert.Equal(t, DefaultRetryDelay, c.retryDelay())
+
+	c = CallbackParameters{Timeout: time.Minute, RetryDelay: 10 * time.Millisecond}
+	assert.Equal(t, time.Minute, c.timeout())
+	assert.Equal(t, 10*time.Millisecond, c.retryDelay())
+}
+
+func TestCallbackParameters_attempt(t *testing.T) {
+	t.Parallel()
+
+	failure := errors.New("unavailable")
+
+	t.Run("retries", func(t *testing.T) {
+		t.Parallel()
+
+		var calls int
+		c := CallbackParameters{Retries: 2, RetryDelay: time.Millisecond}
+		err := c.attempt(context.Background(), func(ctx context.Context) error {
+			calls++
+			return failure
+		})
+
+		require.ErrorIs(t, err, failure)
+		assert.EqualError(t, err, "3 attempts failed: unavailable")
+		assert.Equal(t, 3, calls)
+	})
+
+	t.Run("success after a retry", func(t *testing.T) {
+		t.Parallel()
+
+		var calls int
+		c := CallbackParameters{Retries: 2, RetryDelay: time.Millisecond}
+		err := c.attempt(context.Background(), func(ctx context.Context) error {
+			calls++
+			if calls == 1 {
+				return failure
+			}
+			return nil
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("no retries", func(t *testing.T) {
+		t.Parallel()
+
+		c := CallbackParameters{}
+		err := c.attempt(context.Background(), func(ctx context.Context) error {
+			return failure
+		})
+
+		assert.Equal(t, failure, err)
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+
+		var calls int
+		c := CallbackParameters{Retries: 5, RetryDelay: time.Millisecond}
+		err := c.attempt(ctx, func(ctx context.Context) error {
+			calls++
+			cancel()
+			return ctx.Err()
+		})
+
+		require.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, 1, calls, "no retries after cancellation")
+	})
+
+	t.Run("timeout of an attempt", func(t *testing.T) {
+		t.Parallel()
+
+		c := CallbackParameters{Timeout: 10 * time.Millisecond}
+		err := c.attempt(context.Background(), func(ctx context.Context) error {
+			<-ctx.Done()
+			return c.timeoutError(ctx)
+		})
+
+		require.ErrorIs(t, err, errors2.ErrCallbackTimeout)
+		assert.EqualError(t, err, "callback timed out after 10ms")
+	})
+}
+
+func TestCallbackParameters_handleFailure(t *testing.T) {
+	var out bytes.Buffer
+	original := warnWriter
+	warnWriter = &out
+	defer func() {
+		warnWriter = original
+	}()
+
+	failure := errors.New("webhook failed with token=abc123")
+
+	require.NoError(t, (&CallbackParameters{}).handleFailure(nil))
+	require.ErrorIs(t, (&CallbackParameters{}).handleFailure(failure), failure)
+	require.ErrorIs(t, (&CallbackParameters{OnFailure: OnFailureFail}).handleFailure(failure), failure)
+	require.NoError(t, (&CallbackParameters{OnFailure: OnFailureIgnore}).handleFailure(failure))
+	assert.Empty(t, out.String())
+
+	require.NoError(t, (&CallbackParameters{OnFailure: OnFailureWarn}).handleFailure(failure))
+	assert.Equal(t, "Warning: webhook failed with token=******\n", out.String())
+
+	require.ErrorIs(t, (&CallbackParameters{OnFailure: OnFailureIgnore}).handleFailure(context.Canceled),
+		context.Canceled, "cancellation of the build is not ignored")
+}
+
+func TestCallbackParameters_run_timeout(t *testing.T) {
+	t.Parallel()
+
+	c := CallbackParameters{Type: CommandType, Action: "sleep", Parameters: []string{"10"}, Timeout: 100 * time.Millisecond}
+
+	start := time.Now()
+	err := c.run(context.Background(), nil, nil, output{})
+
+	require.ErrorIs(t, err, errors2.ErrCallbackTimeout)
+	assert.Less(t, time.Since(start), 5*time.Second, "the command is stopped")
+}
+
+func TestCallbackParameters_run_exitCode(t *testing.T) {
+	t.Parallel()
+
+	c := CallbackParameters{Type: CommandType, Action: "false"}
+	err := c.run(context.Background(), nil, nil, output{})
+
+	require.ErrorIs(t, err, errors2.ErrCallbackExitCode)
+	assert.EqualError(t, err, "callback command exited with a non-zero code: 1")
+
+	c.OnFailure = OnFailureIgnore
+	require.NoError(t, c.run(context.Background(), nil, nil, output{}))
+}
+
+func TestCallbackParameters_run_externalPolicy(t *testing.T) {
+	t.Parallel()
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch requests.Add(1) {
+		case 1:
+			time.Sleep(200 * time.Millisecond)
+		case 2:
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer server.Close()
+
+	c := CallbackParameters{
+		Type:       ExternalType,
+		Action:     server.URL,
+		Method:     http.MethodGet,
+		Timeout:    50 * time.Millisecond,
+		Retries:    2,
+		RetryDelay: time.Millisecond,
+	}
+	require.NoError(t, c.run(context.Background(), nil, nil, output{}))
+	assert.Equal(t, int32(3), requests.Load(), "a timeout and an error status are retried")
+
+	requests.Store(0)
+	c.Retries = 0
+	err := c.run(context.Background(), nil, nil, output{})
+	require.ErrorIs(t, err, errors2.ErrCallbackTimeout)
+}
+
+func Test_stopCommand(t *testing.T) {
+	original := stopGracePeriod
+	stopGracePeriod = 100 * time.Millisecond
+	defer func() {
+		stopGracePeriod = original
+	}()
+
+	com := cmd.NewCmd("sh", "-c", "trap '' TERM; sleep 10")
+	statusChan := com.Start()
+	time.Sleep(100 * time.Millisecond)
+
+	start := time.Now()
+	stopCommand(com, statusChan)
+
+	assert.Less(t, time.Since(start), 2*time.Second, "the command ignoring SIGTERM is killed")
+	assert.True(t, com.Status().StopTs > 0, "the command has exited")
+}
@@ -0,0 +1,11 @@
+//go:build !windows
+
+package callback
+
+import "syscall"
+
+// killProcessGroup kills the process and its children. go-cmd starts commands
+// in their own process group, so the group id is the pid of the command.
+func killProcessGroup(pid int) {
+	_ = syscall.Kill(-pid, syscall.SIGKILL)
+}
@@ -0,0 +1,10 @@
+package callback
+
+import "os"
+
+// killProcessGroup kills the process of the command.
+func killProcessGroup(pid int) {
+	if process, err := os.FindProcess(pid); err == nil {
+		_ = process.Kill()
+	}
+}
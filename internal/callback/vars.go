@@ -0,0 +1,139 @@
+package callback
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/pixel365/bx/internal/types"
+)
+
+// Placeholders replaced in the action URLs and the parameters of callbacks.
+var placeholders = []string{
+	"module", "version", "label", "stage", "versionDir", "zipPath", "commit", "files",
+	"hook", "status", "error",
+}
+
+var placeholderRegex = regexp.MustCompile(`\{([a-zA-Z]+)}`)
+
+// infoVars returns the values of the build passed to a callback, keyed by placeholder name.
+// Empty values are omitted; the number of files is set for stage callbacks only.
+func infoVars(info types.CallbackInfo) map[string]string {
+	vars := make(map[string]string, len(placeholders))
+	set := func(key, value string) {
+		if value != "" {
+			vars[key] = value
+		}
+	}
+
+	set("module", info.Module)
+	set("version", info.Version)
+	set("label", info.Label)
+	set("stage", info.Stage)
+	set("versionDir", info.VersionDir)
+	set("zipPath", info.ZipPath)
+	set("commit", info.Commit)
+
+	if info.Stage != "" {
+		vars["files"] = strconv.Itoa(info.Files)
+	}
+
+	return vars
+}
+
+// expand replaces the known placeholders in s with the escaped values from vars.
+// Known placeholders without a value are removed, unknown ones are kept as is.
+func expand(s string, vars map[string]string, escape func(string) string) string {
+	return placeholderRegex.ReplaceAllStringFunc(s, func(match string) string {
+		key := strings.Trim(match, "{}")
+		if !slices.Contains(placeholders, key) {
+			return match
+		}
+
+		return escape(vars[key])
+	})
+}
+
+// stripPlaceholders replaces the known placeholders in s with a neutral value,
+// so the rest of s can be validated.
+func stripPlaceholders(s string) string {
+	return expand(s, nil, func(string) string { return "x" })
+}
+
+func noEscape(s string) string {
+	return s
+}
+
+// envName converts a placeholder name into the name of an environment variable,
+// e.g. versionDir into BX_VERSION_DIR.
+func envName(key string) string {
+	var b strings.Builder
+	b.WriteString("BX_")
+	for i, r := range key {
+		if i > 0 && r >= 'A' && r <= 'Z' {
+			b.WriteByte('_')
+		}
+		b.WriteString(strings.ToUpper(string(r)))
+	}
+
+	return b.String()
+}
+
+// envVars converts the values passed to a callback into BX_* environment variables.
+func envVars(vars map[string]string) []string {
+	env := make([]string, 0, len(vars))
+	for key, value := range vars {
+		env = append(env, fmt.Sprintf("%s=%s", envName(key), value))
+	}
+
+	slices.Sort(env)
+
+	return env
+}
+
+// params converts the values passed to a callback into key=value parameters.
+// Values appended to a URL query are escaped.
+func params(vars map[string]string, escape bool) []string {
+	p := make([]string, 0, len(vars))
+	for key, value := range vars {
+		if escape {
+			value = url.QueryEscape(value)
+		}
+		p = append(p, fmt.Sprintf("%s=%s", key, value))
+	}
+
+	slices.Sort(p)
+
+	return p
+}
+
+// jsonPayload returns the JSON body of an external callback: the values passed to the callback
+// and its key=value parameters in the `parameters` object.
+func jsonPayload(vars map[string]string, parameters []string) ([]byte, error) {
+	payload := make(map[string]any, len(vars)+1)
+	for key, value := range vars {
+		payload[key] = value
+	}
+
+	if files, ok := vars["files"]; ok {
+		if n, err := strconv.Atoi(files); err == nil {
+			payload["files"] = n
+		}
+	}
+
+	if len(parameters) > 0 {
+		object := make(map[string]string, len(parameters))
+		for _, param := range parameters {
+			if key, value, found := strings.Cut(param, "="); found {
+				object[key] = value
+			}
+		}
+		payload["parameters"] = object
+	}
+
+	return json.Marshal(payload)
+}
@@ -0,0 +1,115 @@
+package callback
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pixel365/bx/internal/types"
+)
+
+func Test_infoVars(t *testing.T) {
+	t.Parallel()
+
+	info := types.CallbackInfo{
+		Module:     "vendor.module",
+		Version:    "1.1.0",
+		Label:      "beta",
+		Stage:      "components",
+		VersionDir: "/build/1.1.0",
+		ZipPath:    "/build/1.1.0.zip",
+		Files:      0,
+	}
+
+	assert.Equal(t, map[string]string{
+		"module":     "vendor.module",
+		"version":    "1.1.0",
+		"label":      "beta",
+		"stage":      "components",
+		"versionDir": "/build/1.1.0",
+		"zipPath":    "/build/1.1.0.zip",
+		"files":      "0",
+	}, infoVars(info))
+
+	assert.Equal(t, map[string]string{"module": "vendor.module", "commit": "abc123"},
+		infoVars(types.CallbackInfo{Module: "vendor.module", Commit: "abc123", Files: 3}),
+		"the number of files is passed to stage callbacks only")
+}
+
+func Test_expand(t *testing.T) {
+	t.Parallel()
+
+	vars := map[string]string{"module": "vendor.module", "version": "1.1.0", "error": "a & b"}
+
+	tests := []struct {
+		escape func(string) string
+		input  string
+		want   string
+	}{
+		{noEscape, "{module}-{version}.zip", "vendor.module-1.1.0.zip"},
+		{noEscape, "commit={commit}", "commit="},
+		{noEscape, `{"custom": "{value}"}`, `{"custom": "{value}"}`},
+		{noEscape, "{error}", "a & b"},
+		{url.QueryEscape, "https://example.com/{module}?error={error}", "https://example.com/vendor.module?error=a+%26+b"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, expand(tt.input, vars, tt.escape))
+		})
+	}
+}
+
+func Test_stripPlaceholders(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "x/x.zip", stripPlaceholders("{versionDir}/{version}.zip"))
+	assert.Equal(t, "{unknown}", stripPlaceholders("{unknown}"))
+}
+
+func Test_envName(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "BX_MODULE", envName("module"))
+	assert.Equal(t, "BX_VERSION_DIR", envName("versionDir"))
+	assert.Equal(t, "BX_ZIP_PATH", envName("zipPath"))
+}
+
+func Test_envVars(t *testing.T) {
+	t.Parallel()
+
+	env := envVars(map[string]string{"status": "failure", "hook": "afterBuild", "zipPath": "/build/1.0.0.zip"})
+	assert.Equal(t, []string{"BX_HOOK=afterBuild", "BX_STATUS=failure", "BX_ZIP_PATH=/build/1.0.0.zip"}, env)
+	assert.Empty(t, envVars(nil))
+}
+
+func Test_params(t *testing.T) {
+	t.Parallel()
+
+	vars := map[string]string{"status": "failure", "error": "a & b"}
+	assert.Equal(t, []string{"error=a & b", "status=failure"}, params(vars, false))
+	assert.Equal(t, []string{"error=a+%26+b", "status=failure"}, params(vars, true))
+}
+
+func Test_jsonPayload(t *testing.T) {
+	t.Parallel()
+
+	data, err := jsonPayload(
+		map[string]string{"module": "vendor.module", "stage": "components", "files": "12"},
+		[]string{"chat=release", "text=a=b"},
+	)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"module": "vendor.module",
+		"stage": "components",
+		"files": 12,
+		"parameters": {"chat": "release", "text": "a=b"}
+	}`, string(data))
+
+	data, err = jsonPayload(nil, nil)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{}`, string(data))
+}
@@ -5,6 +5,9 @@ import (
 	"net/http/cookiejar"
 	"net/url"
 	"time"
+
+	"github.com/pixel365/bx/internal/interfaces"
+	"github.com/pixel365/bx/internal/types"
 )
 
 type HTTPClient interface {
@@ -34,3 +37,9 @@ func NewClient(ttl time.Duration) HTTPClient {
 		c: client,
 	}
 }
+
+// NewPortalClient returns a client for the partner portal with the cookie jar of NewClient,
+// and the timeouts and retries of the policy (see RetryClient).
+func NewPortalClient(policy *types.HTTP, logger interfaces.Logger) HTTPClient {
+	return NewRetryClient(NewClient(0), policy, logger)
+}
@@ -0,0 +1,214 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/pixel365/bx/internal/interfaces"
+	"github.com/pixel365/bx/internal/types"
+)
+
+// RetryClient wraps an HTTPClient with the timeout and retry policy of the partner portal.
+//
+// The operation of a request is read from its context (see types.WithOperation).
+// Every attempt is limited by the timeout of the operation. Requests of idempotent
+// operations, and GET requests without an operation, are retried with exponential
+// backoff and jitter after network errors, timeouts, 429 and 5xx responses.
+// Other requests, including the release upload, are sent exactly once.
+//
+// Every attempt and its outcome are written to the logger.
+type RetryClient struct {
+	client HTTPClient
+	logger interfaces.Logger
+	policy *types.HTTP
+	jitter func(time.Duration) time.Duration
+	sleep  func(context.Context, time.Duration) error
+}
+
+// NewRetryClient returns a RetryClient sending requests through c.
+//
+// Parameters:
+//   - c: The client that sends the requests. It should have no timeout of its own.
+//   - policy: The timeouts and retries; nil means the defaults.
+//   - logger: Receives a record of every attempt; may be nil.
+func NewRetryClient(c HTTPClient, policy *types.HTTP, logger interfaces.Logger) *RetryClient {
+	return &RetryClient{
+		client: c,
+		policy: policy,
+		logger: logger,
+		jitter: jitter,
+		sleep:  sleep,
+	}
+}
+
+func (c *RetryClient) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	c.client.SetCookies(u, cookies)
+}
+
+// Do sends the request according to the policy.
+//
+// The response of the last attempt is returned as is, even with an error status.
+// The attempt timeout keeps running until the response body is closed.
+func (c *RetryClient) Do(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	op := types.OperationFromContext(ctx)
+	timeout := c.policy.RequestTimeout(op)
+
+	attempts := 1
+	if c.retryable(req, op) {
+		attempts += c.policy.MaxRetries()
+	}
+
+	name := string(op)
+	if name == "" {
+		name = req.Method
+	}
+
+	for attempt := 1; ; attempt++ {
+		c.info("portal %s: %s %s, attempt %d of %d", name, req.Method, req.URL.Path, attempt, attempts)
+
+		resp, cancel, err := c.send(req, attempt, timeout)
+		retry := attempt < attempts && ctx.Err() == nil && shouldRetry(resp, err)
+
+		if err != nil {
+			cancel()
+			c.error("portal %s: attempt %d of %d failed", err, name, attempt, attempts)
+			if !retry {
+				return nil, err
+			}
+		} else {
+			c.info("portal %s: %s", name, resp.Status)
+			if !retry {
+				resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+				return resp, nil
+			}
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			_ = resp.Body.Close()
+			cancel()
+		}
+
+		delay := c.jitter(c.policy.Backoff(attempt))
+		c.info("portal %s: retrying in %s", name, delay)
+
+		if err := c.sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// send performs a single attempt with its own timeout. From the second attempt on,
+// the body is recreated with GetBody.
+func (c *RetryClient) send(
+	req *http.Request,
+	attempt int,
+	timeout time.Duration,
+) (*http.Response, context.CancelFunc, error) {
+	ctx, cancel := context.WithTimeout(req.Context(), timeout)
+	r := req.Clone(ctx)
+
+	if attempt > 1 && req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, cancel, err
+		}
+		r.Body = body
+	}
+
+	//nolint:bodyclose
+	resp, err := c.client.Do(r)
+	if err != nil && ctx.Err() != nil && req.Context().Err() == nil {
+		err = &TimeoutError{Timeout: timeout, Err: err}
+	}
+
+	return resp, cancel, err
+}
+
+// retryable reports whether the request may be sent more than once.
+func (c *RetryClient) retryable(req *http.Request, op types.Operation) bool {
+	if op == "" {
+		if req.Method != http.MethodGet && req.Method != http.MethodHead {
+			return false
+		}
+	} else if !op.Idempotent() {
+		return false
+	}
+
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+func (c *RetryClient) info(message string, args ...any) {
+	if c.logger != nil {
+		c.logger.Info(message, args...)
+	}
+}
+
+func (c *RetryClient) error(message string, err error, args ...any) {
+	if c.logger != nil {
+		c.logger.Error(message, err, args...)
+	}
+}
+
+// TimeoutError is returned when an attempt exceeds the timeout of its operation.
+type TimeoutError struct {
+	Err     error
+	Timeout time.Duration
+}
+
+func (e *TimeoutError) Error() string {
+	return "request timed out after " + e.Timeout.String() + ": " + e.Err.Error()
+}
+
+func (e *TimeoutError) Unwrap() error {
+	return e.Err
+}
+
+// shouldRetry reports whether the outcome of an attempt is worth another attempt:
+// a network error or timeout, 429 Too Many Requests or a 5xx status.
+func shouldRetry(resp *http.Response, err error) bool {
+	if err != nil {
+		return !errors.Is(err, context.Canceled)
+	}
+
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError
+}
+
+// jitter returns a random delay between the half and the whole of d.
+func jitter(d time.Duration) time.Duration {
+	if d <= 1 {
+		return d
+	}
+
+	half := d / 2
+
+	return half + rand.N(d-half+1) //nolint:gosec
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// cancelBody releases the attempt context when the response body is closed.
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+
+	return err
+}
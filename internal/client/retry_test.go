@@ -0,0 +1,301 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pixel365/bx/internal/types"
+)
+
+type testLogger struct {
+	entries []string
+	mu      sync.Mutex
+}
+
+func (l *testLogger) Info(message string, args ...any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = append(l.entries, fmt.Sprintf(message, args...))
+}
+
+func (l *testLogger) Error(message string, err error, args ...any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = append(l.entries, fmt.Sprintf(message, args...)+": "+err.Error())
+}
+
+type attempt struct {
+	err    error
+	status int
+}
+
+// newTestClient returns a RetryClient whose attempts produce the given outcomes in order,
+// and records the request bodies and the delays between the attempts.
+func newTestClient(policy *types.HTTP, outcomes ...attempt) (*RetryClient, *[]string, *[]time.Duration, *testLogger) {
+	var bodies []string
+	var delays []time.Duration
+
+	mock := &MockHttpClient{DoFunc: func(req *http.Request) (*http.Response, error) {
+		body := ""
+		if req.Body != nil {
+			data, _ := io.ReadAll(req.Body)
+			body = string(data)
+		}
+		bodies = append(bodies, body)
+
+		o := outcomes[min(len(bodies), len(outcomes))-1]
+		if o.err != nil {
+			return nil, o.err
+		}
+
+		return &http.Response{
+			StatusCode: o.status,
+			Status:     http.StatusText(o.status),
+			Body:       io.NopCloser(strings.NewReader("ok")),
+		}, nil
+	}}
+
+	log := &testLogger{}
+	c := NewRetryClient(mock, policy, log)
+	c.jitter = func(d time.Duration) time.Duration { return d }
+	c.sleep = func(_ context.Context, d time.Duration) error {
+		delays = append(delays, d)
+		return nil
+	}
+
+	return c, &bodies, &delays, log
+}
+
+func newRequest(t *testing.T, op types.Operation, method, body string) *http.Request {
+	t.Helper()
+
+	ctx := context.Background()
+	if op != "" {
+		ctx = types.WithOperation(ctx, op)
+	}
+
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, "https://portal.example.com/personal/", reader)
+	require.NoError(t, err)
+
+	return req
+}
+
+func retries(n int) *int {
+	return &n
+}
+
+func TestRetryClient_Do(t *testing.T) {
+	t.Parallel()
+
+	networkErr := errors.New("connection reset by peer")
+	policy := &types.HTTP{Retries: retries(3), RetryDelay: time.Second, MaxRetryDelay: 3 * time.Second}
+
+	tests := []struct {
+		name       string
+		op         types.Operation
+		method     string
+		outcomes   []attempt
+		wantDelays []time.Duration
+		wantStatus int
+		wantErr    bool
+	}{
+		{
+			"versions recovers from 5xx", types.OperationVersions, http.MethodGet,
+			[]attempt{{status: 502}, {status: 503}, {status: 200}},
+			[]time.Duration{time.Second, 2 * time.Second}, 200, false,
+		},
+		{
+			"login recovers from network error", types.OperationLogin, http.MethodPost,
+			[]attempt{{err: networkErr}, {status: 200}},
+			[]time.Duration{time.Second}, 200, false,
+		},
+		{
+			"session retries 429", types.OperationSession, http.MethodGet,
+			[]attempt{{status: 429}, {status: 200}},
+			[]time.Duration{time.Second}, 200, false,
+		},
+		{
+			"retries exhausted", types.OperationVersions, http.MethodGet,
+			[]attempt{{err: networkErr}},
+			[]time.Duration{time.Second, 2 * time.Second, 3 * time.Second}, 0, true,
+		},
+		{
+			"last 5xx is returned", types.OperationVersions, http.MethodGet,
+			[]attempt{{status: 500}},
+			[]time.Duration{time.Second, 2 * time.Second, 3 * time.Second}, 500, false,
+		},
+		{
+			"upload is not retried", types.OperationUpload, http.MethodPost,
+			[]attempt{{status: 502}, {status: 200}},
+			nil, 502, false,
+		},
+		{
+			"upload network error is not retried", types.OperationUpload, http.MethodPost,
+			[]attempt{{err: networkErr}, {status: 200}},
+			nil, 0, true,
+		},
+		{
+			"labels are not retried", types.OperationLabels, http.MethodPost,
+			[]attempt{{err: networkErr}, {status: 200}},
+			nil, 0, true,
+		},
+		{
+			"4xx is not retried", types.OperationVersions, http.MethodGet,
+			[]attempt{{status: 404}, {status: 200}},
+			nil, 404, false,
+		},
+		{
+			"plain GET is retried", "", http.MethodGet,
+			[]attempt{{status: 500}, {status: 200}},
+			[]time.Duration{time.Second}, 200, false,
+		},
+		{
+			"plain POST is not retried", "", http.MethodPost,
+			[]attempt{{status: 500}, {status: 200}},
+			nil, 500, false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			c, bodies, delays, log := newTestClient(policy, tt.outcomes...)
+
+			body := ""
+			if tt.method == http.MethodPost {
+				body = "USER_LOGIN=partner"
+			}
+
+			resp, err := c.Do(newRequest(t, tt.op, tt.method, body))
+			if tt.wantErr {
+				require.Error(t, err)
+				require.ErrorIs(t, err, networkErr)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.wantStatus, resp.StatusCode)
+				require.NoError(t, resp.Body.Close())
+			}
+
+			assert.Equal(t, tt.wantDelays, *delays)
+			assert.Len(t, *bodies, len(tt.wantDelays)+1)
+			for _, b := range *bodies {
+				assert.Equal(t, body, b, "the body is sent in full on every attempt")
+			}
+
+			assert.Contains(t, log.entries[0], "attempt 1 of")
+		})
+	}
+}
+
+func TestRetryClient_Do_timeout(t *testing.T) {
+	t.Parallel()
+
+	calls := 0
+	mock := &MockHttpClient{DoFunc: func(req *http.Request) (*http.Response, error) {
+		calls++
+		<-req.Context().Done()
+		return nil, req.Context().Err()
+	}}
+
+	c := NewRetryClient(mock, &types.HTTP{Timeout: 10 * time.Millisecond, Retries: retries(1)}, nil)
+	c.sleep = func(context.Context, time.Duration) error { return nil }
+
+	_, err := c.Do(newRequest(t, types.OperationVersions, http.MethodGet, ""))
+
+	var timeout *TimeoutError
+	require.ErrorAs(t, err, &timeout)
+	assert.Equal(t, 10*time.Millisecond, timeout.Timeout)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, 2, calls)
+}
+
+func TestRetryClient_Do_uploadTimeout(t *testing.T) {
+	t.Parallel()
+
+	var deadline time.Duration
+	mock := &MockHttpClient{DoFunc: func(req *http.Request) (*http.Response, error) {
+		d, _ := req.Context().Deadline()
+		deadline = time.Until(d)
+		return &http.Response{StatusCode: 200, Body: http.NoBody}, nil
+	}}
+
+	c := NewRetryClient(mock, &types.HTTP{Timeout: time.Second, UploadTimeout: time.Hour}, nil)
+
+	resp, err := c.Do(newRequest(t, types.OperationUpload, http.MethodPost, "zip"))
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	assert.Greater(t, deadline, 59*time.Minute)
+}
+
+func TestRetryClient_Do_canceled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	calls := 0
+	mock := &MockHttpClient{DoFunc: func(req *http.Request) (*http.Response, error) {
+		calls++
+		cancel()
+		return nil, req.Context().Err()
+	}}
+
+	c := NewRetryClient(mock, nil, nil)
+
+	req, err := http.NewRequestWithContext(
+		types.WithOperation(ctx, types.OperationVersions), http.MethodGet, "https://portal.example.com/", nil)
+	require.NoError(t, err)
+
+	_, err = c.Do(req)
+	require.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 1, calls)
+}
+
+func TestRetryClient_Do_bodyKeepsContext(t *testing.T) {
+	t.Parallel()
+
+	var attemptCtx context.Context
+	mock := &MockHttpClient{DoFunc: func(req *http.Request) (*http.Response, error) {
+		attemptCtx = req.Context()
+		return &http.Response{StatusCode: 200, Body: io.NopCloser(strings.NewReader("page"))}, nil
+	}}
+
+	c := NewRetryClient(mock, nil, nil)
+
+	resp, err := c.Do(newRequest(t, types.OperationSession, http.MethodGet, ""))
+	require.NoError(t, err)
+	require.NoError(t, attemptCtx.Err(), "the body can be read after Do returns")
+
+	data, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "page", string(data))
+
+	require.NoError(t, resp.Body.Close())
+	require.ErrorIs(t, attemptCtx.Err(), context.Canceled)
+}
+
+func Test_jitter(t *testing.T) {
+	t.Parallel()
+
+	for range 100 {
+		d := jitter(time.Second)
+		assert.GreaterOrEqual(t, d, 500*time.Millisecond)
+		assert.LessOrEqual(t, d, time.Second)
+	}
+
+	assert.Equal(t, time.Duration(0), jitter(0))
+}
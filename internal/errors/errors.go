@@ -15,9 +15,18 @@ var (
 	ErrNoCommandSpecified      = errors.New("no command specified")
 	ErrNilCookie               = errors.New("cookie is nil")
 	ErrEmptySession            = errors.New("empty session")
+	ErrSessionNotFound         = errors.New("session is not cached")
+	ErrSessionExpired          = errors.New("cached session has expired")
+	ErrSessionInvalid          = errors.New("cached session is invalid")
 	ErrEmptyLogin              = errors.New("empty login")
 	ErrEmptyPassword           = errors.New("empty password")
 	ErrPasswordTooShort        = errors.New("password is too short")
+	ErrInsecurePasswordFile    = errors.New("password file is accessible by other users")
+	ErrCredentialHelper        = errors.New("credential helper failed")
+	ErrSecretKeyNotFound       = errors.New("secret key file not found")
+	ErrSecretKeyInvalid        = errors.New("secret key is invalid")
+	ErrInsecureKeyFile         = errors.New("secret key file is accessible by other users")
+	ErrSecretInvalid           = errors.New("cannot decrypt secret")
 	ErrAuthentication          = errors.New("authentication failed")
 	ErrEmptyModuleName         = errors.New("empty module name")
 	ErrNameContainsSpace       = errors.New("name must not contain spaces")
@@ -42,6 +51,8 @@ var (
 	ErrCallbackActionScheme    = errors.New(
 		"callback action url scheme is invalid. allowed values are 'http' or 'https'",
 	)
+	ErrCallbackTimeout          = errors.New("callback timed out")
+	ErrCallbackExitCode         = errors.New("callback command exited with a non-zero code")
 	ErrNilCmd                   = errors.New("cmd is nil")
 	ErrNilClient                = errors.New("client is nil")
 	ErrNilContext               = errors.New("nil context is prohibited")
@@ -49,4 +60,14 @@ var (
 	ErrInvalidArgument          = errors.New("invalid argument")
 	ErrDescriptionDoesNotExists = errors.New("description does not exist")
 	ErrInvalidLabel             = errors.New("invalid label")
+	ErrVersionExists            = errors.New("version already exists")
+	ErrVersionNotUploaded       = errors.New("version is missing after upload")
+	ErrVersionLabelMismatch     = errors.New("version label does not match")
+	ErrVersionNotFound          = errors.New("version not found")
+	ErrCanceled                 = errors.New("canceled by user")
+	ErrPortalSessionExpired     = errors.New("portal session has expired")
+	ErrPortalAccessDenied       = errors.New("access denied by the portal")
+	ErrPortalValidation         = errors.New("portal rejected the request")
+	ErrPortalMaintenance        = errors.New("portal is under maintenance")
+	ErrPortalUnexpectedPage     = errors.New("unexpected portal page")
 )
@@ -0,0 +1,47 @@
+package errors
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PortalError describes a page of the partner portal that is not the expected result of a request:
+// the login form after the session has expired, an access denied or maintenance page,
+// a validation message, or a page of unknown layout.
+//
+// Err is one of ErrPortalSessionExpired, ErrPortalAccessDenied, ErrPortalValidation,
+// ErrPortalMaintenance or ErrPortalUnexpectedPage, so the kind can be checked with errors.Is.
+type PortalError struct {
+	Err error
+	// Message is the message shown by the portal, if any.
+	Message string
+	// Excerpt is the beginning of the visible text of the page, to tell what the portal returned.
+	Excerpt string
+	// Status is the HTTP status of the response, if it is not 200 OK.
+	Status int
+}
+
+func (e *PortalError) Error() string {
+	var b strings.Builder
+
+	b.WriteString(e.Err.Error())
+
+	if e.Message != "" {
+		b.WriteString(": ")
+		b.WriteString(e.Message)
+	}
+
+	if e.Status != 0 {
+		_, _ = fmt.Fprintf(&b, " (HTTP %d)", e.Status)
+	}
+
+	if e.Message == "" && e.Excerpt != "" {
+		_, _ = fmt.Fprintf(&b, "; page: %q", e.Excerpt)
+	}
+
+	return b.String()
+}
+
+func (e *PortalError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,33 @@
+package errors
+
+import "fmt"
+
+// VersionError reports a problem with a particular version of a module on the partner portal.
+//
+// Err is one of ErrVersionExists, ErrVersionNotUploaded, ErrVersionLabelMismatch or ErrVersionNotFound,
+// so the kind of the problem can be checked with errors.Is, and the details
+// can be read with errors.As.
+type VersionError struct {
+	Err      error
+	Module   string
+	Version  string
+	Label    string
+	Expected string
+}
+
+func (e *VersionError) Error() string {
+	switch {
+	case e.Module == "":
+		return fmt.Sprintf("%s: %s", e.Version, e.Err)
+	case e.Expected != "" && e.Label != "":
+		return fmt.Sprintf("%s %s: %s: expected %s, got %s", e.Module, e.Version, e.Err, e.Expected, e.Label)
+	case e.Label != "":
+		return fmt.Sprintf("%s %s: %s (%s)", e.Module, e.Version, e.Err, e.Label)
+	default:
+		return fmt.Sprintf("%s %s: %s", e.Module, e.Version, e.Err)
+	}
+}
+
+func (e *VersionError) Unwrap() error {
+	return e.Err
+}
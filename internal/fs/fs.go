@@ -22,6 +22,7 @@ import (
 
 // PathProcessing walks through the source directory specified in `path.From`
 // and submits file copy tasks to the provided `filesCh` channel.
+// The directory is read from `path.Source`, or from the local file system if it is not set.
 //
 // It applies ignore rules, change tracking (if enabled), and pattern-based filtering
 // before sending any file to be processed.
@@ -49,10 +50,13 @@ func PathProcessing(
 
 	var changes *types.Changes
 	if !cfg.IsLastVersion() {
-		changes = cfg.GetChanges()
+		var err error
+		if changes, err = cfg.GetChanges(); err != nil {
+			return err
+		}
 	}
 
-	err := filepath.Walk(path.From, visitor(
+	err := sourceOf(path).Walk(path.From, visitor(
 		ctx, filesCh, cfg, path, filterRules, changes,
 	))
 
@@ -110,7 +114,7 @@ func visitor(
 			return skip(info)
 		}
 
-		isDir, err := helpers.IsDir(absFrom)
+		isDir, err := sourceOf(path).IsDir(absFrom)
 		if err != nil {
 			return err
 		}
@@ -135,6 +139,7 @@ func visitor(
 		}
 
 		newPath := types.Path{
+			Source:         path.Source,
 			From:           absFrom,
 			To:             absTo,
 			ActionIfExists: path.ActionIfExists,
@@ -161,6 +166,7 @@ func skip(info os.FileInfo) error {
 
 // CopyFile copies a file from the source path to the destination path, taking into account the context cancellation,
 // existing file handling mode (`existsMode`), and optional conversion of file content.
+// The file is read from `file.Source`, or from the local file system if it is not set.
 //
 // The function checks if the destination file exists and takes action based on the specified `existsMode`:
 //   - If `Skip`, it does nothing if the file already exists.
@@ -179,15 +185,15 @@ func skip(info os.FileInfo) error {
 //   - file (types.Path): Path params.
 //
 // Returns:
-//   - None: Errors are sent to the error channel `errCh`, and no return value is provided.
+//   - bool: true if the file was written; false if it was skipped or an error was sent to `errCh`.
 func CopyFile(
 	ctx context.Context,
 	errCh chan<- error,
 	file types.Path,
-) {
+) bool {
 	if err := helpers.CheckContext(ctx); err != nil {
 		errCh <- err
-		return
+		return false
 	}
 
 	fileName := strings.LastIndex(file.From, "/")
@@ -201,37 +207,31 @@ func CopyFile(
 	if err == nil {
 		existingFile = stat
 		if file.ActionIfExists == types.Skip {
-			return
+			return false
 		}
 	}
 
-	in, err := os.Open(file.From)
+	in, info, err := sourceOf(file).Open(file.From)
 	if err != nil {
 		errCh <- err
-		return
+		return false
 	}
 
 	defer helpers.Cleanup(in, errCh)
 
-	info, err := in.Stat()
-	if err != nil {
-		errCh <- err
-		return
-	}
-
 	allowWrite := true
 	if existingFile != nil && file.ActionIfExists == types.ReplaceIfNewer {
 		allowWrite = info.ModTime().After(existingFile.ModTime())
 	}
 
 	if !allowWrite {
-		return
+		return false
 	}
 
 	out, err := os.OpenFile(file.To, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, info.Mode())
 	if err != nil {
 		errCh <- err
-		return
+		return false
 	}
 
 	defer helpers.Cleanup(out, errCh)
@@ -247,14 +247,16 @@ func CopyFile(
 	_, err = io.Copy(writer, in)
 	if err != nil {
 		errCh <- err
-		return
+		return false
 	}
 
 	err = os.Chtimes(file.To, info.ModTime(), info.ModTime())
 	if err != nil {
 		errCh <- err
-		return
+		return false
 	}
+
+	return true
 }
 
 // shouldSkip checks if a given file path should be skipped based on a list of glob patterns.
@@ -30,8 +30,8 @@ func (f FakeModuleConfig) GetIgnore() []string {
 		"**/*some*/*",
 	}
 }
-func (f FakeModuleConfig) GetChanges() *types.Changes { return nil }
-func (f FakeModuleConfig) IsLastVersion() bool        { return false }
+func (f FakeModuleConfig) GetChanges() (*types.Changes, error) { return nil, nil }
+func (f FakeModuleConfig) IsLastVersion() bool                 { return false }
 
 type FakeFileInfo struct {
 	Dir bool
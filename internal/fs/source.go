@@ -0,0 +1,48 @@
+package fs
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/pixel365/bx/internal/helpers"
+	"github.com/pixel365/bx/internal/types"
+)
+
+// DiskSource is the default Source that reads stage sources from the local file system.
+type DiskSource struct{}
+
+// Walk walks the directory tree rooted at root using `filepath.Walk`.
+func (DiskSource) Walk(root string, fn filepath.WalkFunc) error {
+	return filepath.Walk(root, fn)
+}
+
+// IsDir reports whether the path is a directory, following symbolic links.
+func (DiskSource) IsDir(path string) (bool, error) {
+	return helpers.IsDir(path)
+}
+
+// Open opens the file for reading and returns its info.
+func (DiskSource) Open(path string) (io.ReadCloser, os.FileInfo, error) {
+	f, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return nil, nil, err
+	}
+
+	return f, info, nil
+}
+
+// sourceOf returns the source of the path, falling back to the local file system.
+func sourceOf(path types.Path) types.Source {
+	if path.Source != nil {
+		return path.Source
+	}
+
+	return DiskSource{}
+}
@@ -0,0 +1,133 @@
+package fs
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pixel365/bx/internal/types"
+)
+
+// fakeSource is an in-memory Source with files keyed by their path.
+type fakeSource struct {
+	files map[string]string
+}
+
+func (s fakeSource) Walk(root string, fn filepath.WalkFunc) error {
+	if err := fn(root, FakeFileInfo{Dir: true}, nil); err != nil {
+		if errors.Is(err, filepath.SkipDir) {
+			return nil
+		}
+		return err
+	}
+
+	paths := make([]string, 0, len(s.files))
+	for p := range s.files {
+		if strings.HasPrefix(p, root+"/") {
+			paths = append(paths, p)
+		}
+	}
+	sort.Strings(paths)
+
+	for _, p := range paths {
+		if err := fn(p, FakeFileInfo{}, nil); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s fakeSource) IsDir(path string) (bool, error) {
+	if _, ok := s.files[path]; ok {
+		return false, nil
+	}
+
+	return true, nil
+}
+
+func (s fakeSource) Open(path string) (io.ReadCloser, os.FileInfo, error) {
+	content, ok := s.files[path]
+	if !ok {
+		return nil, nil, os.ErrNotExist
+	}
+
+	return io.NopCloser(strings.NewReader(content)), fakeFileInfo{size: int64(len(content))}, nil
+}
+
+type fakeFileInfo struct {
+	size int64
+}
+
+func (f fakeFileInfo) Name() string       { return "" }
+func (f fakeFileInfo) Size() int64        { return f.size }
+func (f fakeFileInfo) Mode() os.FileMode  { return 0644 }
+func (f fakeFileInfo) ModTime() time.Time { return time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC) }
+func (f fakeFileInfo) Sys() any           { return nil }
+func (f fakeFileInfo) IsDir() bool        { return false }
+
+func TestPathProcessing_source(t *testing.T) {
+	t.Parallel()
+
+	source := fakeSource{files: map[string]string{
+		"/src/a.php":         "a",
+		"/src/debug.log":     "log",
+		"/src/lang/ru/a.php": "lang",
+	}}
+	to := t.TempDir()
+
+	filesCh := make(chan types.Path, 10)
+	err := PathProcessing(context.Background(), filesCh, FakeModuleConfig{}, types.Path{
+		Source: source,
+		From:   "/src",
+		To:     to,
+	}, nil)
+	require.NoError(t, err)
+	close(filesCh)
+
+	var got []string
+	for p := range filesCh {
+		assert.Equal(t, source, p.Source)
+		got = append(got, p.From)
+	}
+
+	assert.Equal(t, []string{"/src/a.php", "/src/lang/ru/a.php"}, got)
+}
+
+func TestCopyFile_source(t *testing.T) {
+	t.Parallel()
+
+	source := fakeSource{files: map[string]string{"/src/lang/ru/a.php": "Привет"}}
+	to := filepath.Join(t.TempDir(), "a.php")
+
+	errCh := make(chan error, 2)
+	CopyFile(context.Background(), errCh, types.Path{
+		Source:         source,
+		From:           "/src/lang/ru/a.php",
+		To:             to,
+		ActionIfExists: types.Replace,
+		Convert:        true,
+	})
+	close(errCh)
+
+	for err := range errCh {
+		require.NoError(t, err)
+	}
+
+	content, err := os.ReadFile(to)
+	require.NoError(t, err)
+	assert.Equal(t, "\xcf\xf0\xe8\xe2\xe5\xf2", string(content))
+
+	info, err := os.Stat(to)
+	require.NoError(t, err)
+	assert.True(t, info.ModTime().Equal(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)))
+}
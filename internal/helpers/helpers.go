@@ -73,6 +73,27 @@ func Choose(items *[]string, value *string, title string) error {
 		Run()
 }
 
+// Confirm asks the user a yes/no question.
+//
+// Parameters:
+//   - title: The question shown to the user.
+//
+// Returns:
+//   - bool: True if the user confirmed.
+//   - error: An error if the prompt fails to run, e.g. when it is aborted.
+func Confirm(title string) (bool, error) {
+	confirmed := false
+
+	err := huh.NewConfirm().
+		Title(title).
+		Affirmative("Yes").
+		Negative("No").
+		Value(&confirmed).
+		Run()
+
+	return confirmed, err
+}
+
 // CaptureOutput captures and returns the standard output (stdout) generated by the execution of the provided function.
 //
 // It temporarily redirects os.Stdout to a pipe, executes the given function `f`,
@@ -396,13 +417,20 @@ func Spinner(title string, action func(context.Context) error) error {
 // Versions that do not start with a 'v' prefix are normalized automatically (e.g., "1.2.3" becomes "v1.2.3").
 // The returned slice is a new sorted copy; the input is not modified.
 func SortSemanticVersions(versions iter.Seq[string]) []string {
-	result := slices.SortedFunc(versions, func(a string, b string) int {
-		return semver.Compare(normalizeVersion(a), normalizeVersion(b))
-	})
+	result := slices.SortedFunc(versions, CompareSemanticVersions)
 
 	return result
 }
 
+// CompareSemanticVersions compares two versions according to semantic versioning rules.
+// Versions without the 'v' prefix are normalized, as in SortSemanticVersions.
+//
+// Returns:
+//   - -1 if a < b, 0 if a == b, +1 if a > b.
+func CompareSemanticVersions(a, b string) int {
+	return semver.Compare(normalizeVersion(a), normalizeVersion(b))
+}
+
 // normalizeVersion ensures that a version string has a 'v' prefix,
 // as required by the semver.Compare function.
 // If the version already starts with 'v', it is returned unchanged.
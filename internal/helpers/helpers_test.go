@@ -407,6 +407,26 @@ func TestSortSemanticVersions(t *testing.T) {
 	}
 }
 
+func TestCompareSemanticVersions(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		a    string
+		b    string
+		want int
+	}{
+		{"less", "1.9.0", "1.10.0", -1},
+		{"equal", "2.0.0", "v2.0.0", 0},
+		{"greater", "2.0.0", "2.0.0-beta", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, CompareSemanticVersions(tt.a, tt.b))
+		})
+	}
+}
+
 func Test_normalizeVersion(t *testing.T) {
 	t.Parallel()
 	type args struct {
@@ -0,0 +1,141 @@
+package helpers
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	progressWidth    = 30
+	progressInterval = 100 * time.Millisecond
+	progressStep     = 10
+)
+
+// ProgressBar renders the progress of a transfer of known size as a single terminal line:
+//
+//	Uploading module [###########-------------------]  37%  12.4 MiB / 33.5 MiB
+//
+// On a terminal, the line is redrawn at most every 100ms. Otherwise (e.g. in CI logs),
+// a new line is printed every 10 percent. ProgressBar is safe for concurrent use.
+type ProgressBar struct {
+	last     time.Time
+	out      io.Writer
+	now      func() time.Time
+	title    string
+	percent  int
+	mu       sync.Mutex
+	terminal bool
+	done     bool
+}
+
+// NewProgressBar returns a progress bar writing to out.
+// Redrawing in place is used if out is a terminal.
+func NewProgressBar(out io.Writer, title string) *ProgressBar {
+	return &ProgressBar{
+		out:      out,
+		title:    title,
+		terminal: isTerminal(out),
+		now:      time.Now,
+		percent:  -1,
+	}
+}
+
+// Update reports that done of total bytes have been transferred.
+func (p *ProgressBar) Update(done, total int64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.done {
+		return
+	}
+
+	percent := 100
+	if total > 0 {
+		percent = int(min(done, total) * 100 / total)
+	}
+
+	now := p.now()
+
+	if p.terminal {
+		if percent == p.percent || (percent < 100 && now.Sub(p.last) < progressInterval) {
+			return
+		}
+		_, _ = fmt.Fprintf(p.out, "\r%s", p.line(done, total, percent))
+	} else {
+		if p.percent >= 0 && percent/progressStep == p.percent/progressStep {
+			return
+		}
+		_, _ = fmt.Fprintln(p.out, p.line(done, total, percent))
+	}
+
+	p.percent = percent
+	p.last = now
+}
+
+// Done finishes the bar. If err is not nil, the failure is reported on the bar line.
+func (p *ProgressBar) Done(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.done {
+		return
+	}
+	p.done = true
+
+	switch {
+	case err != nil && p.terminal:
+		_, _ = fmt.Fprintf(p.out, "\r%s: failed\n", p.title)
+	case err != nil:
+		_, _ = fmt.Fprintf(p.out, "%s: failed\n", p.title)
+	case p.terminal:
+		_, _ = fmt.Fprintln(p.out)
+	}
+}
+
+func (p *ProgressBar) line(done, total int64, percent int) string {
+	filled := percent * progressWidth / 100
+
+	return fmt.Sprintf("%s [%s%s] %3d%%  %s / %s",
+		p.title,
+		strings.Repeat("#", filled),
+		strings.Repeat("-", progressWidth-filled),
+		percent,
+		FormatBytes(done),
+		FormatBytes(total),
+	)
+}
+
+// FormatBytes formats a size in bytes with binary units, e.g. `512 B`, `1.5 KiB`, `12.4 MiB`.
+func FormatBytes(n int64) string {
+	const unit = 1024
+
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+
+	div, exp := int64(unit), 0
+	for v := n / unit; v >= unit && exp < 4; v /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTP"[exp])
+}
+
+func isTerminal(out io.Writer) bool {
+	f, ok := out.(*os.File)
+	if !ok {
+		return false
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+
+	return info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,74 @@
+package helpers
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatBytes(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		want string
+		n    int64
+	}{
+		{"zero", "0 B", 0},
+		{"bytes", "512 B", 512},
+		{"kibibytes", "1.5 KiB", 1536},
+		{"mebibytes", "12.4 MiB", 13002342},
+		{"gibibytes", "2.0 GiB", 2 << 30},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, FormatBytes(tt.n))
+		})
+	}
+}
+
+func TestProgressBar_notTerminal(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+	bar := NewProgressBar(&out, "Uploading module")
+
+	for done := int64(0); done <= 1000; done += 25 {
+		bar.Update(done, 1000)
+	}
+	bar.Done(nil)
+	bar.Update(1000, 1000)
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 11, "a line every 10 percent")
+	assert.Equal(t, "Uploading module [------------------------------]   0%  0 B / 1000 B", lines[0])
+	assert.Equal(t, "Uploading module [##############################] 100%  1000 B / 1000 B", lines[10])
+}
+
+func TestProgressBar_terminal(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+	now := time.Now()
+	bar := &ProgressBar{out: &out, title: "Uploading module", terminal: true, percent: -1,
+		now: func() time.Time { return now }}
+
+	bar.Update(0, 100)
+	bar.Update(10, 100)
+	now = now.Add(time.Second)
+	bar.Update(50, 100)
+	bar.Update(100, 100)
+	bar.Done(errors.New("boom"))
+
+	got := out.String()
+	assert.Equal(t, 4, strings.Count(got, "\r"), "throttled redraws, the final one and the failure")
+	assert.NotContains(t, got, " 10%")
+	assert.Contains(t, got, " 50%")
+	assert.True(t, strings.HasSuffix(got, "\rUploading module: failed\n"))
+}
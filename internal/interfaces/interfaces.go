@@ -36,14 +36,14 @@ type Builder interface {
 //   - GetRun: Returns the ordered map of run commands.
 //   - GetStages: Returns the defined execution stages.
 //   - GetIgnore: Returns file paths or patterns to ignore.
-//   - GetChanges: Returns changelog-related metadata.
+//   - GetChanges: Returns the files changed since the previous release, or an error if their scope is invalid.
 //   - IsLastVersion: Indicates whether the module represents the latest version.
 type ModuleConfig interface {
 	GetVariables() map[string]string
 	GetRun() map[string][]string
 	GetStages() []types.Stage
 	GetIgnore() []string
-	GetChanges() *types.Changes
+	GetChanges() (*types.Changes, error)
 	IsLastVersion() bool
 }
 
@@ -60,11 +60,13 @@ type Logger interface {
 // Runnable defines hooks for executing logic before and after a build stage.
 //
 // Methods:
-//   - PreRun: Executes logic before the main run phase; receives context.
-//   - PostRun: Executes logic after the run phase completes.
+//   - PreRun: Executes logic before the main run phase; receives context, the build context
+//     and the log the output of the logic is written to.
+//   - PostRun: Executes logic after the run phase completes; the build context includes
+//     the number of files copied by the stage.
 type Runnable interface {
-	PreRun(ctx context.Context) error
-	PostRun(ctx context.Context) error
+	PreRun(ctx context.Context, info types.CallbackInfo, logger Logger) error
+	PostRun(ctx context.Context, info types.CallbackInfo, logger Logger) error
 }
 
 // Prompter abstracts user input collection and validation.
@@ -0,0 +1,192 @@
+// Package labels plans label changes of module versions on the partner portal:
+// the selection of versions by lists and ranges for `bx label`, the promotion rules
+// of `bx promote`, and the diff shown before the changes are submitted.
+package labels
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/pixel365/bx/internal/errors"
+	"github.com/pixel365/bx/internal/helpers"
+	"github.com/pixel365/bx/internal/types"
+	"github.com/pixel365/bx/internal/validators"
+)
+
+// Change is a new label of a version.
+type Change struct {
+	Version string
+	From    types.VersionLabel
+	To      types.VersionLabel
+}
+
+// Select returns the versions of the module matched by the specs and the upper bound, in semver order.
+//
+// Parameters:
+//   - current: The versions of the module on the portal.
+//   - specs: Exact versions, or inclusive ranges of two X.Y.Z versions separated by `-` or `..`,
+//     e.g. `2.1.0-2.1.4` or `2.1.0..2.1.4`. A version with a pre-release suffix, e.g. `2.1.0-beta`,
+//     is taken as an exact version.
+//   - below: If set, all versions lower than this X.Y.Z version are selected too.
+//
+// Returns:
+//   - []string: The selected versions, without duplicates.
+//   - error: If a spec is invalid, an exact version is not on the portal, or nothing is selected.
+func Select(current types.Versions, specs []string, below string) ([]string, error) {
+	selected := make(map[string]struct{})
+
+	if below != "" {
+		if err := validators.ValidateVersion(below); err != nil {
+			return nil, err
+		}
+
+		for version := range current {
+			if helpers.CompareSemanticVersions(version, below) < 0 {
+				selected[version] = struct{}{}
+			}
+		}
+	}
+
+	for _, spec := range specs {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+
+		if low, high, ok := parseRange(spec); ok {
+			if helpers.CompareSemanticVersions(low, high) > 0 {
+				return nil, fmt.Errorf("%w: range %s is reversed", errors.ErrInvalidArgument, spec)
+			}
+
+			for version := range current {
+				if helpers.CompareSemanticVersions(version, low) >= 0 &&
+					helpers.CompareSemanticVersions(version, high) <= 0 {
+					selected[version] = struct{}{}
+				}
+			}
+			continue
+		}
+
+		if _, ok := current[spec]; !ok {
+			return nil, &errors.VersionError{Err: errors.ErrVersionNotFound, Version: spec}
+		}
+		selected[spec] = struct{}{}
+	}
+
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("%w: no versions selected", errors.ErrNoItems)
+	}
+
+	return helpers.SortSemanticVersions(maps.Keys(selected)), nil
+}
+
+// parseRange splits a range spec into its bounds.
+// It reports false if the spec is not a range of two X.Y.Z versions.
+func parseRange(spec string) (string, string, bool) {
+	if low, high, found := strings.Cut(spec, ".."); found {
+		return low, high, validRange(low, high)
+	}
+
+	for i := strings.IndexByte(spec, '-'); i >= 0; {
+		if low, high := spec[:i], spec[i+1:]; validRange(low, high) {
+			return low, high, true
+		}
+
+		next := strings.IndexByte(spec[i+1:], '-')
+		if next < 0 {
+			break
+		}
+		i += next + 1
+	}
+
+	return "", "", false
+}
+
+func validRange(low, high string) bool {
+	return validators.ValidateVersion(low) == nil && validators.ValidateVersion(high) == nil
+}
+
+// Plan returns the changes that set the label on the versions, skipping the versions
+// that already have it.
+func Plan(current types.Versions, versions []string, label types.VersionLabel) []Change {
+	changes := make([]Change, 0, len(versions))
+
+	for _, version := range helpers.SortSemanticVersions(slices.Values(versions)) {
+		if from := current[version]; from != label {
+			changes = append(changes, Change{Version: version, From: from, To: label})
+		}
+	}
+
+	return changes
+}
+
+// Promote returns the changes made by the promotion rules.
+//
+// Every rule moves the newest version with its From label to its To label. The rules are applied
+// to the current labels, not to the result of the previous rules, so a version is promoted
+// at most one step. A version older than the newest version that already has the To label
+// is not promoted.
+//
+// Parameters:
+//   - current: The versions of the module on the portal.
+//   - rules: The promotion rules.
+//   - from: If set, only the rule for this label is applied.
+func Promote(current types.Versions, rules []types.PromotionRule, from types.VersionLabel) []Change {
+	newest := make(map[types.VersionLabel]string)
+	for _, version := range helpers.SortSemanticVersions(maps.Keys(current)) {
+		newest[current[version]] = version
+	}
+
+	var changes []Change
+	for _, rule := range rules {
+		if from != "" && rule.From != from {
+			continue
+		}
+
+		version, ok := newest[rule.From]
+		if !ok {
+			continue
+		}
+
+		if top, ok := newest[rule.To]; ok && helpers.CompareSemanticVersions(version, top) < 0 {
+			continue
+		}
+
+		changes = append(changes, Change{Version: version, From: rule.From, To: rule.To})
+	}
+
+	slices.SortFunc(changes, func(a, b Change) int {
+		return helpers.CompareSemanticVersions(a.Version, b.Version)
+	})
+
+	return changes
+}
+
+// Versions converts the changes to the argument of request.ChangeLabels.
+func Versions(changes []Change) types.Versions {
+	versions := make(types.Versions, len(changes))
+	for _, change := range changes {
+		versions[change.Version] = change.To
+	}
+
+	return versions
+}
+
+// WriteDiff prints the changes as aligned `version  from -> to` lines.
+func WriteDiff(w io.Writer, changes []Change) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	for _, change := range changes {
+		from := string(change.From)
+		if from == "" {
+			from = "-"
+		}
+		_, _ = fmt.Fprintf(tw, "  %s\t%s\t-> %s\n", change.Version, from, change.To)
+	}
+
+	return tw.Flush()
+}
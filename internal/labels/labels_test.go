@@ -0,0 +1,186 @@
+package labels
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pixel365/bx/internal/errors"
+	"github.com/pixel365/bx/internal/types"
+)
+
+var current = types.Versions{
+	"1.9.0":      types.Stable,
+	"2.0.0":      types.Stable,
+	"2.1.0":      types.Beta,
+	"2.1.1":      types.Beta,
+	"2.1.2-beta": types.Alpha,
+	"2.1.4":      types.Alpha,
+	"2.2.0":      types.Alpha,
+}
+
+func TestSelect(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		wantErr error
+		name    string
+		below   string
+		specs   []string
+		want    []string
+	}{
+		{nil, "exact", "", []string{"2.1.0"}, []string{"2.1.0"}},
+		{nil, "pre-release", "", []string{"2.1.2-beta"}, []string{"2.1.2-beta"}},
+		{nil, "range", "", []string{"2.1.0-2.1.4"}, []string{"2.1.0", "2.1.1", "2.1.2-beta", "2.1.4"}},
+		{nil, "dotted range", "", []string{"2.1.1..2.2.0"}, []string{"2.1.1", "2.1.2-beta", "2.1.4", "2.2.0"}},
+		{nil, "below", "2.1.0", nil, []string{"1.9.0", "2.0.0"}},
+		{nil, "combined", "2.0.0", []string{"2.2.0", "2.1.0-2.1.1", "2.1.0"}, []string{"1.9.0", "2.1.0", "2.1.1", "2.2.0"}},
+		{errors.ErrVersionNotFound, "unknown", "", []string{"3.0.0"}, nil},
+		{errors.ErrInvalidArgument, "reversed range", "", []string{"2.1.4-2.1.0"}, nil},
+		{errors.ErrNoItems, "empty range", "", []string{"3.0.0-3.1.0"}, nil},
+		{errors.ErrNoItems, "nothing", "", nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := Select(current, tt.specs, tt.below)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	_, err := Select(current, nil, "two")
+	require.Error(t, err)
+}
+
+func Test_parseRange(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		spec string
+		low  string
+		high string
+		ok   bool
+	}{
+		{"2.1.0-2.1.4", "2.1.0", "2.1.4", true},
+		{"2.1.0..2.1.4", "2.1.0", "2.1.4", true},
+		{"2.1.0-beta", "", "", false},
+		{"2.1.0-rc-2.1.4", "", "", false},
+		{"2.1.0", "", "", false},
+		{"2.1.0..beta", "2.1.0", "beta", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			t.Parallel()
+
+			low, high, ok := parseRange(tt.spec)
+			assert.Equal(t, tt.ok, ok)
+			if ok {
+				assert.Equal(t, tt.low, low)
+				assert.Equal(t, tt.high, high)
+			}
+		})
+	}
+}
+
+func TestPlan(t *testing.T) {
+	t.Parallel()
+
+	changes := Plan(current, []string{"2.1.1", "2.0.0", "1.9.0", "2.1.0"}, types.Stable)
+	assert.Equal(t, []Change{
+		{Version: "2.1.0", From: types.Beta, To: types.Stable},
+		{Version: "2.1.1", From: types.Beta, To: types.Stable},
+	}, changes)
+
+	assert.Empty(t, Plan(current, []string{"2.0.0"}, types.Stable))
+}
+
+func TestPromote(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		versions types.Versions
+		name     string
+		from     types.VersionLabel
+		rules    []types.PromotionRule
+		want     []Change
+	}{
+		{
+			current,
+			"default rules",
+			"",
+			types.DefaultPromotionRules(),
+			[]Change{
+				{Version: "2.1.1", From: types.Beta, To: types.Stable},
+				{Version: "2.2.0", From: types.Alpha, To: types.Beta},
+			},
+		},
+		{
+			current,
+			"from beta",
+			types.Beta,
+			types.DefaultPromotionRules(),
+			[]Change{{Version: "2.1.1", From: types.Beta, To: types.Stable}},
+		},
+		{
+			current,
+			"custom rule",
+			"",
+			[]types.PromotionRule{{From: types.Alpha, To: types.Stable}},
+			[]Change{{Version: "2.2.0", From: types.Alpha, To: types.Stable}},
+		},
+		{
+			types.Versions{"1.0.0": types.Beta, "2.0.0": types.Stable},
+			"older than the newest stable",
+			"",
+			types.DefaultPromotionRules(),
+			nil,
+		},
+		{
+			types.Versions{"1.0.0": types.Stable},
+			"nothing to promote",
+			"",
+			types.DefaultPromotionRules(),
+			nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, Promote(tt.versions, tt.rules, tt.from))
+		})
+	}
+}
+
+func TestVersions(t *testing.T) {
+	t.Parallel()
+
+	changes := []Change{
+		{Version: "2.1.1", From: types.Beta, To: types.Stable},
+		{Version: "2.2.0", From: types.Alpha, To: types.Beta},
+	}
+
+	assert.Equal(t, types.Versions{"2.1.1": types.Stable, "2.2.0": types.Beta}, Versions(changes))
+}
+
+func TestWriteDiff(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+	require.NoError(t, WriteDiff(&out, []Change{
+		{Version: "2.1.1", From: types.Beta, To: types.Stable},
+		{Version: "10.0.0", From: types.Alpha, To: types.Beta},
+		{Version: "3.0.0", To: types.Beta},
+	}))
+
+	assert.Equal(t, "  2.1.1   beta   -> stable\n  10.0.0  alpha  -> beta\n  3.0.0   -      -> beta\n", out.String())
+}
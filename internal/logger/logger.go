@@ -6,9 +6,11 @@
 package logger
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 
+	"github.com/pixel365/bx/internal/redact"
 	"github.com/pixel365/bx/internal/types"
 
 	"github.com/rs/zerolog"
@@ -27,6 +29,7 @@ type ZeroLogger struct {
 // Info logs an informational message using zerolog.
 //
 // If formatting arguments are provided, the message is formatted using fmt-style verbs.
+// Secrets in the message are masked (see the redact package).
 // Logging is skipped if the log file is not initialized.
 //
 // Parameters:
@@ -34,15 +37,15 @@ type ZeroLogger struct {
 //   - args: Optional format arguments for the message.
 func (l *ZeroLogger) Info(message string, args ...any) {
 	if len(args) > 0 {
-		l.logger.Info().Msgf(message, args...)
-		return
+		message = fmt.Sprintf(message, args...)
 	}
-	l.logger.Info().Msg(message)
+	l.logger.Info().Msg(redact.String(message))
 }
 
 // Error logs an error message along with an associated error.
 //
 // If formatting arguments are provided, the message is formatted accordingly.
+// Secrets in the message and the error are masked (see the redact package).
 // Logging is skipped if the log file is not initialized.
 //
 // Parameters:
@@ -51,10 +54,9 @@ func (l *ZeroLogger) Info(message string, args ...any) {
 //   - args: Optional format arguments for the message.
 func (l *ZeroLogger) Error(message string, err error, args ...any) {
 	if len(args) > 0 {
-		l.logger.Error().Err(err).Msgf(message, args...)
-		return
+		message = fmt.Sprintf(message, args...)
 	}
-	l.logger.Error().Err(err).Msg(message)
+	l.logger.Error().Err(redact.Error(err)).Msg(redact.String(message))
 }
 
 // NewFileLogger creates and returns a new instance of ZeroLogger,
@@ -6,12 +6,14 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/pixel365/bx/internal/redact"
 	"github.com/pixel365/bx/internal/types"
 )
 
@@ -192,6 +194,36 @@ func TestZeroLogger_Error_With_Args(t *testing.T) {
 	assert.Equal(t, test, record.Error)
 }
 
+func TestZeroLogger_redacts(t *testing.T) {
+	log := types.Log{
+		Dir:        t.TempDir(),
+		MaxSize:    1,
+		MaxBackups: 1,
+		MaxAge:     1,
+	}
+	logger := NewFileLogger(&log, "redact")
+
+	redact.Add("logger-secret-value")
+	logger.Info("callback %s?token=abc", "https://example.com/hook")
+	logger.Error("login with %s", errors.New("rejected logger-secret-value"), "logger-secret-value")
+
+	data, err := os.ReadFile(filepath.Join(log.Dir, "redact.log"))
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 2)
+
+	var info InfoRecord
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &info))
+	assert.Equal(t, "callback https://example.com/hook?token=******", info.Message)
+
+	var record ErrorRecord
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &record))
+	assert.Equal(t, "login with ******", record.Message)
+	assert.Equal(t, "rejected ******", record.Error)
+	assert.NotContains(t, string(data), "logger-secret-value")
+}
+
 func TestNewFileLogger_PanicOnInvalidDir(t *testing.T) {
 	log := &types.Log{
 		Dir: string([]byte{0x00}),
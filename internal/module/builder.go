@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/pixel365/bx/internal/callback"
 	"github.com/pixel365/bx/internal/interfaces"
 
 	"github.com/pixel365/bx/internal/errors"
@@ -28,6 +29,11 @@ func NewModuleBuilder(m *Module, logger interfaces.Logger) interfaces.Builder {
 // It logs the progress of each phase, such as preparation, collection, and Cleanup.
 // If any of these phases fails, the build will be rolled back to ensure a clean state.
 //
+// The beforeBuild hook of the module is run first; its failure cancels the build.
+// After the build, the afterBuild hook is run, and after a failed build the onBuildFailure
+// hook too. These hooks are run even if ctx is canceled. Their failures are logged and do not
+// change the result of the build.
+//
 // The method returns an error if any of the steps (Prepare, Collect, or Cleanup) fail.
 func (m *ModuleBuilder) Build(ctx context.Context) error {
 	if m.module == nil {
@@ -38,8 +44,34 @@ func (m *ModuleBuilder) Build(ctx context.Context) error {
 		return err
 	}
 
+	if err := m.module.RunHook(ctx, callback.BeforeBuild, nil, m.log); err != nil {
+		m.log.Error("Build canceled by the beforeBuild hook", err)
+		return err
+	}
+
+	err := m.build(ctx)
+
+	if err != nil {
+		if hookErr := m.module.RunFinalHook(ctx, callback.OnBuildFailure, err, m.log); hookErr != nil {
+			m.log.Error("Failed to run the onBuildFailure hook", hookErr)
+		}
+	}
+
+	if hookErr := m.module.RunFinalHook(ctx, callback.AfterBuild, err, m.log); hookErr != nil {
+		m.log.Error("Failed to run the afterBuild hook", hookErr)
+	}
+
+	return err
+}
+
+// build prepares the build and collects the files, rolling back on failure.
+func (m *ModuleBuilder) build(ctx context.Context) error {
 	m.log.Info("Building module")
 
+	if m.module.UsesWorktree() {
+		m.log.Info(WorktreeWarning)
+	}
+
 	if err := m.Prepare(); err != nil {
 		m.log.Error("Failed to prepare build", err)
 		if rollbackErr := m.Rollback(); rollbackErr != nil {
@@ -64,7 +96,8 @@ func (m *ModuleBuilder) Build(ctx context.Context) error {
 }
 
 // Prepare sets up the environment for the build process.
-// It validates the module, checks the stages, and creates the necessary directories for the build output and logs.
+// It opens the Git ref if the module is built from one, validates the module, checks the stages,
+// and creates the necessary directories for the build output and logs.
 // If any validation or directory creation fails, an error will be returned.
 //
 // The method returns an error if the module is invalid or if directories cannot be created.
@@ -73,6 +106,15 @@ func (m *ModuleBuilder) Prepare() error {
 		return errors.ErrNilModule
 	}
 
+	if err := m.module.openSource(); err != nil {
+		m.log.Error("Prepare: failed to open ref", err)
+		return err
+	}
+
+	if m.module.Ref != "" {
+		m.log.Info("Reading sources from ref %s", m.module.Ref)
+	}
+
 	if err := CheckStages(m.module); err != nil {
 		m.log.Error("Prepare: check stages failed", err)
 		return err
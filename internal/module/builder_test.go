@@ -2,12 +2,20 @@ package module
 
 import (
 	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/pixel365/bx/internal/callback"
 	"github.com/pixel365/bx/internal/interfaces"
+	"github.com/pixel365/bx/internal/redact"
+	"github.com/pixel365/bx/internal/types"
 
 	errors2 "github.com/pixel365/bx/internal/errors"
 )
@@ -158,3 +166,117 @@ func TestModuleBuilder_Collect(t *testing.T) {
 		})
 	}
 }
+
+func TestModuleBuilder_Build_hooks(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	var calls []url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		mu.Lock()
+		calls = append(calls, r.Form)
+		mu.Unlock()
+
+		if r.Form.Get("hook") == callback.BeforeBuild && r.Form.Get("cancel") != "" {
+			w.WriteHeader(http.StatusConflict)
+		}
+	}))
+	defer server.Close()
+
+	action := func(params ...string) []callback.CallbackParameters {
+		return []callback.CallbackParameters{
+			{Type: callback.ExternalType, Action: server.URL, Method: http.MethodPost, Parameters: params},
+		}
+	}
+
+	mod := &Module{
+		Name:           "test",
+		Version:        "1.0.0",
+		BuildDirectory: t.TempDir(),
+		Stages: []types.Stage{
+			{Name: "missing", To: "install", From: []string{filepath.Join(t.TempDir(), "missing")}},
+		},
+		Hooks: &callback.Hooks{
+			BeforeBuild:    action("step=before"),
+			AfterBuild:     action("step=after"),
+			OnBuildFailure: action("step=failure"),
+		},
+	}
+
+	err := NewModuleBuilder(mod, &FakeBuildLogger{}).Build(context.Background())
+	require.Error(t, err)
+
+	require.Len(t, calls, 3)
+	assert.Equal(t, []string{"before", "failure", "after"},
+		[]string{calls[0].Get("step"), calls[1].Get("step"), calls[2].Get("step")})
+	assert.Equal(t, "success", calls[0].Get("status"))
+	assert.Equal(t, "failure", calls[1].Get("status"))
+	assert.Equal(t, "failure", calls[2].Get("status"))
+	assert.Equal(t, redact.String(err.Error()), calls[2].Get("error"))
+
+	calls = nil
+	mod.Hooks.BeforeBuild = action("cancel=1")
+
+	err = NewModuleBuilder(mod, &FakeBuildLogger{}).Build(context.Background())
+	require.ErrorContains(t, err, "hook beforeBuild [0] failed: callback returned status code 409")
+	assert.Len(t, calls, 1, "a failed beforeBuild hook cancels the build and the other hooks")
+}
+
+// cancelLogger cancels the build when it logs the message.
+type cancelLogger struct {
+	FakeBuildLogger
+	cancel  context.CancelFunc
+	message string
+}
+
+func (l *cancelLogger) Info(msg string, args ...any) {
+	l.FakeBuildLogger.Info(msg, args...)
+	if msg == l.message {
+		l.cancel()
+	}
+}
+
+func TestModuleBuilder_Build_canceled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var mu sync.Mutex
+	var calls []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		mu.Lock()
+		calls = append(calls, r.Form.Get("hook")+":"+r.Form.Get("status"))
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	action := []callback.CallbackParameters{
+		{Type: callback.ExternalType, Action: server.URL, Method: http.MethodPost},
+	}
+
+	mod := &Module{
+		Name:           "test",
+		Version:        "1.0.0",
+		BuildDirectory: t.TempDir(),
+		Stages: []types.Stage{
+			{Name: "missing", To: "install", From: []string{filepath.Join(t.TempDir(), "missing")}},
+		},
+		Hooks: &callback.Hooks{
+			BeforeBuild:    action,
+			AfterBuild:     action,
+			OnBuildFailure: action,
+		},
+	}
+
+	// the build is interrupted after the beforeBuild hook
+	logger := &cancelLogger{cancel: cancel, message: "Building module"}
+
+	err := NewModuleBuilder(mod, logger).Build(ctx)
+	require.Error(t, err)
+	require.ErrorIs(t, ctx.Err(), context.Canceled)
+	assert.Equal(t, []string{"beforeBuild:success", "onBuildFailure:failure", "afterBuild:failure"}, calls,
+		"the hooks after the build are run with a canceled context")
+}
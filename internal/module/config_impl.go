@@ -1,11 +1,18 @@
 package module
 
 import (
+	"fmt"
+
 	"github.com/pixel365/bx/internal/repo"
 	"github.com/pixel365/bx/internal/types"
 )
 
-var changesListFunc = repo.ChangesList
+var (
+	changesListFunc    = repo.ChangesList
+	newTreeSourceFunc  = repo.NewTreeSource
+	repositoryRootFunc = repo.Root
+	headCommitFunc     = repo.HeadCommit
+)
 
 func (m *Module) GetVariables() map[string]string {
 	return m.Variables
@@ -23,24 +30,90 @@ func (m *Module) GetIgnore() []string {
 	return m.Ignore
 }
 
-func (m *Module) GetChanges() *types.Changes {
+// GetChanges returns the files changed since the previous release, limited by the changelog scope.
+// The changes are read from the repository once and cached.
+//
+// Returns:
+//   - *types.Changes: The changed files, or nil if they cannot be read from the repository
+//     (e.g. without a changelog range); then all files are built.
+//   - error: An error if the changelog scope cannot be resolved.
+func (m *Module) GetChanges() (*types.Changes, error) {
 	if m.Repository == "" {
-		return nil
+		return nil, nil
 	}
 
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
 	if m.changes == nil {
-		changes, err := changesListFunc(m.Repository, m.Changelog)
+		scope, err := m.ChangelogScope()
 		if err != nil {
-			return nil
+			return nil, err
+		}
+
+		changes, err := changesListFunc(m.Repository, m.Changelog, scope)
+		if err != nil {
+			return nil, nil
 		}
 
 		m.changes = changes
 	}
 
-	return m.changes
+	return m.changes, nil
+}
+
+// openSource sets the source the stage files are read from.
+//
+// If Ref is set, the files are read from the Git tree at the ref, without touching
+// the working directory. Otherwise, the local file system is used.
+//
+// Returns an error if Ref is set without a repository, together with a worktree changelog,
+// or cannot be resolved.
+func (m *Module) openSource() error {
+	if m.Ref == "" {
+		m.source = nil
+		return nil
+	}
+
+	if m.Repository == "" {
+		return fmt.Errorf("ref [%s]: repository is required", m.Ref)
+	}
+
+	if m.UsesWorktree() {
+		return fmt.Errorf("ref [%s]: cannot be used with changelog to type %s", m.Ref, types.Worktree)
+	}
+
+	source, err := newTreeSourceFunc(m.Repository, m.Ref)
+	if err != nil {
+		return err
+	}
+
+	m.source = source
+
+	return nil
+}
+
+// commit returns the hash of the commit the module is built from: the commit of Ref,
+// or the HEAD of the repository. It returns an empty string if the module has no repository
+// or the commit cannot be resolved.
+//
+// The HEAD is resolved once per build, on first use, as the callbacks of all stages need it.
+func (m *Module) commit() string {
+	if source, ok := m.source.(interface{ Commit() string }); ok {
+		return source.Commit()
+	}
+
+	m.headOnce.Do(func() {
+		if m.Repository == "" {
+			return
+		}
+
+		if hash, err := headCommitFunc(m.Repository); err == nil {
+			m.head = hash
+		}
+	})
+
+	return m.head
 }
 
 func (m *Module) IsLastVersion() bool {
@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/pixel365/bx/internal/types/changelog"
 
@@ -56,7 +57,8 @@ func TestModule_GetChanges(t *testing.T) {
 	mod := Module{
 		Repository: "../../",
 	}
-	changes := mod.GetChanges()
+	changes, err := mod.GetChanges()
+	require.NoError(t, err)
 	assert.Nil(t, changes)
 }
 
@@ -71,18 +73,34 @@ func TestModule_GetChanges2(t *testing.T) {
 		changesListFunc = origChangesListFunc
 	}()
 
-	changesListFunc = func(_ string, _ changelog.Changelog) (*types.Changes, error) {
+	changesListFunc = func(_ string, _ changelog.Changelog, _ []string) (*types.Changes, error) {
 		return &types.Changes{}, nil
 	}
 
-	changes := mod.GetChanges()
+	changes, err := mod.GetChanges()
+	require.NoError(t, err)
 	assert.NotNil(t, changes)
 }
 
+func TestModule_GetChanges_scopeError(t *testing.T) {
+	t.Parallel()
+	mod := Module{
+		Repository: "../../",
+		Stages:     []types.Stage{{Name: "outside", From: []string{"/tmp/other"}}},
+		Builds:     types.Builds{Release: []string{"outside"}},
+		Changelog:  changelog.Changelog{Scope: types.StagesScope},
+	}
+
+	changes, err := mod.GetChanges()
+	require.ErrorContains(t, err, "changelog scope: no stage sources inside repository")
+	assert.Nil(t, changes)
+}
+
 func TestGetChanges_empty_repository(t *testing.T) {
 	t.Parallel()
 	mod := Module{}
-	changes := mod.GetChanges()
+	changes, err := mod.GetChanges()
+	require.NoError(t, err)
 	assert.Nil(t, changes)
 }
 
@@ -105,3 +123,66 @@ func TestModule_SourceCount(t *testing.T) {
 	count := mod.SourceCount()
 	assert.Equal(t, 1, count)
 }
+
+func TestModule_openSource(t *testing.T) {
+	t.Parallel()
+	worktree := changelog.Changelog{To: types.TypeValue[types.ChangelogType, string]{Type: types.Worktree}}
+
+	tests := []struct {
+		m          *Module
+		name       string
+		wantSource bool
+		wantErr    bool
+	}{
+		{&Module{Repository: "../../"}, "no ref", false, false},
+		{&Module{Ref: "HEAD"}, "no repository", false, true},
+		{&Module{Ref: "HEAD", Repository: "../../", Changelog: worktree}, "worktree", false, true},
+		{&Module{Ref: "HEAD", Repository: "../../"}, "head", true, false},
+		{&Module{Ref: "no-such-ref", Repository: "../../"}, "unknown ref", false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := tt.m.openSource()
+			if tt.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantSource, tt.m.source != nil)
+		})
+	}
+}
+
+func TestModule_commit_once(t *testing.T) {
+	original := headCommitFunc
+	defer func() {
+		headCommitFunc = original
+	}()
+
+	calls := 0
+	headCommitFunc = func(repository string) (string, error) {
+		calls++
+		return "0123456789abcdef0123456789abcdef01234567", nil
+	}
+
+	mod := &Module{Name: "vendor.module", Repository: "../../"}
+	for range 3 {
+		assert.Equal(t, "0123456789abcdef0123456789abcdef01234567", mod.CallbackInfo().Commit)
+	}
+	assert.Equal(t, 1, calls, "the repository is opened once")
+}
+
+func TestModule_commit(t *testing.T) {
+	t.Parallel()
+
+	assert.Empty(t, (&Module{}).commit(), "no repository")
+	assert.Empty(t, (&Module{Repository: t.TempDir()}).commit(), "not a repository")
+
+	head := (&Module{Repository: "../../"}).commit()
+	assert.Len(t, head, 40)
+
+	mod := &Module{Ref: "HEAD", Repository: "../../"}
+	require.NoError(t, mod.openSource())
+	assert.Equal(t, head, mod.commit(), "the commit of the ref")
+}
@@ -4,12 +4,15 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
-	"golang.org/x/text/encoding/charmap"
-
+	"github.com/pixel365/bx/internal/redact"
 	"github.com/pixel365/bx/internal/repo"
+	"github.com/pixel365/bx/internal/secret"
+	"github.com/pixel365/bx/internal/types"
+	"github.com/pixel365/bx/internal/types/changelog"
 
 	"github.com/pixel365/bx/internal/errors"
 
@@ -21,8 +24,9 @@ import (
 )
 
 var (
-	checkPathsFunc = helpers.CheckPaths
-	copyFileFunc   = fs.CopyFile
+	checkPathsFunc    = helpers.CheckPaths
+	copyFileFunc      = fs.CopyFile
+	loadSecretKeyFunc = secret.DefaultKey
 )
 
 // ReadModule reads a module from a YAML file or directory path and returns a Module object.
@@ -37,9 +41,13 @@ var (
 //   - file (bool): Flag indicating whether the `path` is a direct file path or a directory where
 //     a module file should be looked for.
 //
+// Values tagged `!secret` are decrypted with the key from secret.DefaultKey (see the secret package).
+// The key file is only needed if the module has such values. The decrypted values and the parameters
+// of sensitive callbacks are registered in the redact package.
+//
 // Returns:
 //   - *Module: A pointer to a `Module` object if the file can be successfully read and unmarshalled.
-//   - error: An error if reading or unmarshalling the file fails.
+//   - error: An error if reading, decrypting or unmarshalling the file fails.
 func ReadModule(path, name string, file bool) (*Module, error) {
 	var filePath string
 	var err error
@@ -63,14 +71,42 @@ func ReadModule(path, name string, file bool) (*Module, error) {
 		return nil, err
 	}
 
-	var m Module
-	if err := yaml.Unmarshal(data, &m); err != nil {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
 		return nil, err
 	}
 
+	secrets, err := secret.Decrypt(&doc, loadSecretKeyFunc)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filepath.Base(filePath), err)
+	}
+
+	var m Module
+	if doc.Kind != 0 {
+		if err := doc.Decode(&m); err != nil {
+			return nil, err
+		}
+	}
+	m.secrets = secrets
+	registerSecrets(&m)
+	m.Changelog.Compile()
+
 	return &m, nil
 }
 
+// registerSecrets registers the decrypted `!secret` values and the parameters of the sensitive
+// callbacks and hooks of the module in the redact package, so they are masked in logs and errors.
+func registerSecrets(m *Module) {
+	redact.Add(m.Secrets()...)
+
+	for _, c := range m.Callbacks {
+		redact.Add(c.Pre.SensitiveValues()...)
+		redact.Add(c.Post.SensitiveValues()...)
+	}
+
+	redact.Add(m.Hooks.SensitiveValues()...)
+}
+
 func ReadModuleFromFlags(cmd *cobra.Command) (*Module, error) {
 	if cmd == nil {
 		return nil, errors.ErrNilCmd
@@ -116,12 +152,46 @@ func ReadModuleFromFlags(cmd *cobra.Command) (*Module, error) {
 	}
 
 	if description != "" {
-		module.Description = description
+		module.Description = types.Description{Text: description}
+		delete(module.Descriptions, types.Ru)
 	}
 
+	applyHTTPFlags(cmd, module)
+
 	return module, module.IsValid()
 }
 
+// applyHTTPFlags overrides the `http` section of the module with the --timeout,
+// --upload-timeout and --retries flags, if the command has them and they are set.
+func applyHTTPFlags(cmd *cobra.Command, module *Module) {
+	flags := cmd.Flags()
+	changed := func(name string) bool {
+		f := flags.Lookup(name)
+		return f != nil && f.Changed
+	}
+
+	if !changed("timeout") && !changed("upload-timeout") && !changed("retries") {
+		return
+	}
+
+	if module.HTTP == nil {
+		module.HTTP = &types.HTTP{}
+	}
+
+	if changed("timeout") {
+		module.HTTP.Timeout, _ = flags.GetDuration("timeout")
+	}
+
+	if changed("upload-timeout") {
+		module.HTTP.UploadTimeout, _ = flags.GetDuration("upload-timeout")
+	}
+
+	if changed("retries") {
+		retries, _ := flags.GetInt("retries")
+		module.HTTP.Retries = &retries
+	}
+}
+
 // AllModules returns a list of module names found in the specified directory.
 //
 // The function reads the directory, checks for files (skipping directories), and attempts to read
@@ -144,18 +214,36 @@ func AllModules(directory string) *[]string {
 	for _, file := range files {
 		if !file.IsDir() {
 			filePath := filepath.Join(directory, file.Name())
-			module, err := ReadModule(filePath, "", true)
+			name, err := readModuleName(filePath)
 			if err != nil {
 				continue
 			}
 
-			modules = append(modules, module.Name)
+			modules = append(modules, name)
 		}
 	}
 
 	return &modules
 }
 
+// readModuleName returns the name of the module in the file without reading the rest of it,
+// so the modules can be listed without the key of their secrets.
+func readModuleName(path string) (string, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return "", err
+	}
+
+	var m struct {
+		Name string `yaml:"name"`
+	}
+	if err := yaml.Unmarshal(data, &m); err != nil {
+		return "", err
+	}
+
+	return m.Name, nil
+}
+
 func makeVersionDirectory(module *Module) (string, error) {
 	if module == nil || module.BuildDirectory == "" {
 		return "", errors.ErrNilModule
@@ -219,6 +307,14 @@ func writeFileForVersion(builder *ModuleBuilder, path, content string) error {
 	return nil
 }
 
+// makeVersionDescription writes a `description.<locale>` file for every supported locale
+// that has a description.
+//
+// For each locale, the manually set description is used if present. Otherwise, if the
+// repository is set and the locale is listed in `changelog.locales` (Russian by default),
+// the description is generated from commits, using `Changelog-<Locale>:` trailers when
+// available. The footer template of the locale is appended, and the file is encoded in
+// the locale charset.
 func makeVersionDescription(builder *ModuleBuilder) error {
 	// If the full latest version is being built, then the version description file is not needed.
 	// However, it may be present when copying if specified in the configuration, at the discretion of the developer.
@@ -226,55 +322,94 @@ func makeVersionDescription(builder *ModuleBuilder) error {
 		return nil
 	}
 
-	descriptionRu := strings.Builder{}
-	encoder := charmap.Windows1251.NewEncoder()
+	var commits []changelog.Commit
+	commitsLoaded := false
 
-	if builder.module.Description != "" {
-		encodedDescriptionRu, err := encoder.String(builder.module.Description + "\n")
+	for _, locale := range types.Locales {
+		description, err := builder.module.LocalDescription(locale)
 		if err != nil {
-			return fmt.Errorf("encoding description [%s]: %w", builder.module.Description, err)
+			return err
 		}
 
-		_, _ = descriptionRu.WriteString(encodedDescriptionRu)
-	} else {
-		if builder.module.Repository == "" {
-			return nil
+		if description == "" {
+			if builder.module.Repository == "" ||
+				!slices.Contains(builder.module.Changelog.DescriptionLocales(), locale) {
+				continue
+			}
+
+			if !commitsLoaded {
+				commits, err = versionCommits(builder.module)
+				if err != nil {
+					return err
+				}
+				commitsLoaded = true
+			}
+
+			if len(commits) == 0 {
+				continue
+			}
 		}
 
-		commits, err := repo.ChangelogList(builder.module.Repository, builder.module.Changelog)
+		content, err := localeDescription(builder.module, locale, description, commits)
 		if err != nil {
 			return err
 		}
 
-		if len(commits) == 0 {
-			return nil
+		err = writeFileForVersion(builder, "description."+string(locale), content)
+		if err != nil {
+			return fmt.Errorf("failed to make description file: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// versionCommits returns the changelog commits of the module limited by its changelog scope.
+func versionCommits(module *Module) ([]changelog.Commit, error) {
+	scope, err := module.ChangelogScope()
+	if err != nil {
+		return nil, err
+	}
+
+	return repo.ChangelogList(module.Repository, module.Changelog, scope)
+}
+
+// localeDescription builds the encoded content of `description.<locale>` from the manual
+// description or, if it is empty, from the commits, followed by the footer of the locale.
+func localeDescription(
+	module *Module,
+	locale types.Locale,
+	description string,
+	commits []changelog.Commit,
+) (string, error) {
+	content := strings.Builder{}
+	encoder := locale.Charmap().NewEncoder()
+
+	if description != "" {
+		encoded, err := encoder.String(description + "\n")
+		if err != nil {
+			return "", fmt.Errorf("encoding description.%s [%s]: %w", locale, description, err)
 		}
 
+		_, _ = content.WriteString(encoded)
+	} else {
 		for _, commit := range commits {
-			encodedLine, err := encoder.String(commit + "<br>")
+			message := module.Changelog.LocalizedMessage(commit, locale)
+			encodedLine, err := encoder.String(message + "<br>")
 			if err != nil {
-				return fmt.Errorf("encoding commit [%s]: %w", commit, err)
+				return "", fmt.Errorf("encoding commit [%s] for description.%s: %w", message, locale, err)
 			}
-			_, _ = descriptionRu.WriteString(encodedLine)
+			_, _ = content.WriteString(encodedLine)
 		}
 	}
 
-	footer, err := builder.module.Changelog.EncodedFooter()
+	footer, err := module.Changelog.EncodedFooter(locale)
 	if err != nil {
-		return fmt.Errorf(
-			"encoding footer template [%s]: %w",
-			builder.module.Changelog.FooterTemplate,
-			err,
-		)
+		return "", fmt.Errorf("encoding footer template for description.%s: %w", locale, err)
 	}
-	_, _ = descriptionRu.WriteString(footer)
+	_, _ = content.WriteString(footer)
 
-	err = writeFileForVersion(builder, "description.ru", descriptionRu.String())
-	if err != nil {
-		return fmt.Errorf("failed to make description file: %w", err)
-	}
-
-	return nil
+	return content.String(), nil
 }
 
 func makeVersionFile(builder *ModuleBuilder) error {
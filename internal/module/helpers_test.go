@@ -17,6 +17,10 @@ import (
 
 	errors2 "github.com/pixel365/bx/internal/errors"
 	"github.com/pixel365/bx/internal/helpers"
+	"github.com/pixel365/bx/internal/redact"
+	"github.com/pixel365/bx/internal/secret"
+	"github.com/pixel365/bx/internal/types"
+	"github.com/pixel365/bx/internal/types/changelog"
 )
 
 type FakeBuildLogger struct {
@@ -57,6 +61,41 @@ func TestReadModuleFromFlags_File(t *testing.T) {
 	require.Error(t, err)
 }
 
+func Test_applyHTTPFlags(t *testing.T) {
+	t.Parallel()
+
+	newCmd := func(args ...string) *cobra.Command {
+		cmd := &cobra.Command{}
+		cmd.Flags().Duration("timeout", 0, "")
+		cmd.Flags().Duration("upload-timeout", 0, "")
+		cmd.Flags().Int("retries", types.DefaultRetries, "")
+		require.NoError(t, cmd.Flags().Parse(args))
+		return cmd
+	}
+
+	m := &Module{}
+	applyHTTPFlags(newCmd(), m)
+	assert.Nil(t, m.HTTP, "unset flags keep the module config")
+
+	retries := 5
+	m = &Module{HTTP: &types.HTTP{Timeout: time.Minute, Retries: &retries, RetryDelay: time.Second}}
+	applyHTTPFlags(newCmd("--upload-timeout", "20m", "--retries", "0"), m)
+	assert.Equal(t, time.Minute, m.HTTP.Timeout)
+	assert.Equal(t, 20*time.Minute, m.HTTP.UploadTimeout)
+	assert.Equal(t, 0, *m.HTTP.Retries)
+	assert.Equal(t, time.Second, m.HTTP.RetryDelay)
+
+	m = &Module{}
+	applyHTTPFlags(newCmd("--timeout", "5s"), m)
+	require.NotNil(t, m.HTTP)
+	assert.Equal(t, 5*time.Second, m.HTTP.Timeout)
+	assert.Nil(t, m.HTTP.Retries)
+
+	m = &Module{}
+	applyHTTPFlags(&cobra.Command{}, m)
+	assert.Nil(t, m.HTTP, "commands without the flags")
+}
+
 func TestAllModules(t *testing.T) {
 	name := fmt.Sprintf("%s_%d", "testing", time.Now().Unix())
 	filePath, err := filepath.Abs(fmt.Sprintf("./%s/%s.yaml", ".", name))
@@ -95,6 +134,59 @@ func TestAllModules(t *testing.T) {
 	}
 }
 
+func TestReadModule_secrets(t *testing.T) {
+	key, err := secret.GenerateKey()
+	require.NoError(t, err)
+
+	original := loadSecretKeyFunc
+	defer func() {
+		loadSecretKeyFunc = original
+	}()
+
+	loads := 0
+	loadSecretKeyFunc = func() (secret.Key, error) {
+		loads++
+		return key, nil
+	}
+
+	token, err := secret.Seal(key, "callback-token")
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "test.yaml")
+	data := "name: test\naccount: partner\nvariables:\n  token: !secret " + token + "\n" +
+		"callbacks:\n  - stage: build\n    post:\n      type: command\n      action: notify\n" +
+		"      sensitive: true\n      parameters:\n        - webhook=callback-webhook\n"
+	require.NoError(t, os.WriteFile(path, []byte(data), 0600))
+
+	m, err := ReadModule(path, "", true)
+	require.NoError(t, err)
+	assert.Equal(t, "callback-token", m.Variables["token"])
+	assert.Equal(t, []string{"callback-token"}, m.Secrets())
+	assert.Equal(t, "token "+redact.Mask, redact.String("token callback-token"), "secrets are masked in logs")
+	assert.Equal(t, "notify "+redact.Mask, redact.String("notify callback-webhook"), "sensitive parameters are masked")
+
+	out, err := m.ToYAML()
+	require.NoError(t, err)
+	assert.NotContains(t, string(out), "callback-token")
+	assert.Contains(t, string(out), "token: !secret "+token)
+
+	assert.Equal(t, &[]string{"test"}, AllModules(dir))
+	assert.Equal(t, 1, loads, "modules are listed without the key")
+
+	loadSecretKeyFunc = func() (secret.Key, error) {
+		return nil, errors2.ErrSecretKeyNotFound
+	}
+	_, err = ReadModule(path, "", true)
+	require.ErrorIs(t, err, errors2.ErrSecretKeyNotFound)
+
+	plain := filepath.Join(dir, "plain.yaml")
+	require.NoError(t, os.WriteFile(plain, []byte("name: plain\n"), 0600))
+	m, err = ReadModule(plain, "", true)
+	require.NoError(t, err, "the key is not needed without secrets")
+	assert.Empty(t, m.Secrets())
+}
+
 func Test_makeVersionDirectory(t *testing.T) {
 	mod1 := &Module{
 		BuildDirectory: "testdata",
@@ -250,7 +342,7 @@ func Test_makeVersionDescription(t *testing.T) {
 			module: &Module{
 				BuildDirectory: "testdata",
 				Version:        "1.0.0",
-				Description:    "some description",
+				Description:    types.Description{Text: "some description"},
 			},
 			log: nil,
 		}}, "has description", false},
@@ -276,6 +368,45 @@ func Test_makeVersionDescription(t *testing.T) {
 	}
 }
 
+func Test_makeVersionDescription_locales(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	builder := &ModuleBuilder{module: &Module{
+		BuildDirectory: dir,
+		Version:        "1.0.0",
+		Descriptions: map[types.Locale]string{
+			types.Ru: "Исправления",
+			types.En: "Fixes, café",
+		},
+		Changelog: changelog.Changelog{
+			FooterTemplates: map[types.Locale]string{types.En: "Backup first"},
+		},
+	}}
+
+	require.NoError(t, makeVersionDescription(builder))
+
+	ru, err := os.ReadFile(filepath.Join(dir, "1.0.0", "description.ru"))
+	require.NoError(t, err)
+	assert.Equal(t, "\xc8\xf1\xef\xf0\xe0\xe2\xeb\xe5\xed\xe8\xff\n", string(ru))
+
+	en, err := os.ReadFile(filepath.Join(dir, "1.0.0", "description.en"))
+	require.NoError(t, err)
+	assert.Equal(t, "Fixes, caf\xe9\n<br>Backup first", string(en))
+}
+
+func Test_makeVersionDescription_invalid_en(t *testing.T) {
+	t.Parallel()
+
+	builder := &ModuleBuilder{module: &Module{
+		BuildDirectory: t.TempDir(),
+		Version:        "1.0.0",
+		Descriptions:   map[types.Locale]string{types.En: "Исправления"},
+	}}
+
+	require.Error(t, makeVersionDescription(builder))
+}
+
 func Test_versionPhpContent(t *testing.T) {
 	t.Parallel()
 	date, err := time.Parse(time.RFC3339, "2025-05-20T23:00:00Z")
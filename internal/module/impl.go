@@ -1,8 +1,12 @@
 package module
 
 import (
+	"context"
 	"fmt"
+	"net/url"
+	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/pixel365/bx/internal/interfaces"
@@ -16,6 +20,7 @@ import (
 	"github.com/pixel365/bx/internal/callback"
 	"github.com/pixel365/bx/internal/helpers"
 	"github.com/pixel365/bx/internal/repo"
+	"github.com/pixel365/bx/internal/secret"
 )
 
 // IsValid validates the fields of the Module struct.
@@ -43,6 +48,10 @@ func (m *Module) IsValid() error {
 		return err
 	}
 
+	if err := validateDescriptions(m); err != nil {
+		return err
+	}
+
 	if err := validateStages(m.Stages); err != nil {
 		return err
 	}
@@ -59,6 +68,10 @@ func (m *Module) IsValid() error {
 		return err
 	}
 
+	if err := m.Hooks.IsValid(); err != nil {
+		return err
+	}
+
 	if m.Repository != "" {
 		if _, err := repo.OpenRepository(m.Repository); err != nil {
 			return err
@@ -87,12 +100,29 @@ func (m *Module) IsValid() error {
 		return err
 	}
 
+	if err := validateSession(m); err != nil {
+		return err
+	}
+
+	if _, err := m.PortalURL(); err != nil {
+		return err
+	}
+
+	if err := validateHTTP(m); err != nil {
+		return err
+	}
+
+	if err := validatePromotion(m); err != nil {
+		return err
+	}
+
 	return nil
 }
 
 // ToYAML converts the Module struct to its YAML representation.
 //
 // It uses the `yaml.Marshal` function to serialize the `Module` struct into a YAML format.
+// Values decrypted from `!secret` fields are written in their encrypted form, never in plain text.
 // If the conversion is successful, it returns the resulting YAML as a byte slice.
 // If an error occurs during marshaling, it returns the error.
 //
@@ -100,7 +130,14 @@ func (m *Module) IsValid() error {
 //   - []byte: The YAML representation of the Module struct.
 //   - error: Any error that occurred during the marshaling process.
 func (m *Module) ToYAML() ([]byte, error) {
-	return yaml.Marshal(m)
+	var doc yaml.Node
+	if err := doc.Encode(m); err != nil {
+		return nil, err
+	}
+
+	secret.Encrypt(&doc, m.secrets)
+
+	return yaml.Marshal(&doc)
 }
 
 // NormalizeStages processes and normalizes the stages in the Module by replacing any variables
@@ -162,6 +199,37 @@ func (m *Module) ZipPath() (string, error) {
 	return path, nil
 }
 
+// PortalURL returns the base URL of the partner portal the module is published to.
+//
+// The URL is taken, in order of priority, from the BX_PORTAL_URL environment variable,
+// the `portal` field of the module, or DefaultPortalURL. A trailing slash is removed.
+//
+// Returns an error if the URL is not an absolute http or https URL.
+func (m *Module) PortalURL() (*url.URL, error) {
+	raw := strings.TrimSpace(os.Getenv(PortalEnv))
+	if raw == "" {
+		raw = strings.TrimSpace(m.Portal)
+	}
+	if raw == "" {
+		raw = DefaultPortalURL
+	}
+
+	u, err := url.Parse(strings.TrimRight(raw, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("portal url [%s]: %w", raw, err)
+	}
+
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("portal url [%s]: must be an absolute http or https url", raw)
+	}
+
+	if u.RawQuery != "" || u.Fragment != "" {
+		return nil, fmt.Errorf("portal url [%s]: query and fragment are not allowed", raw)
+	}
+
+	return u, nil
+}
+
 // PasswordEnv returns the environment variable name
 // that stores the password for the module.
 //
@@ -196,6 +264,67 @@ func (m *Module) StageCallback(stageName string) (interfaces.Runnable, error) {
 	return nil, errors.ErrStageCallbackNotFound
 }
 
+// RunHook runs the actions of the lifecycle hook of the module.
+// It does nothing if the module has no actions for the hook.
+//
+// Parameters:
+//   - ctx: The context of the operation.
+//   - hook: The name of the hook, e.g. callback.AfterBuild.
+//   - err: The error of the operation the hook is run after, or nil; it defines the outcome status.
+//   - logger: The log the output of the commands is written to; nil discards the output.
+//
+// Returns:
+//   - error: The errors of the failed actions, or nil.
+func (m *Module) RunHook(ctx context.Context, hook string, err error, logger interfaces.Logger) error {
+	if len(m.Hooks.Actions(hook)) == 0 {
+		return nil
+	}
+
+	return m.Hooks.Run(ctx, callback.Event{Hook: hook, Err: err, Logger: logger, Info: m.CallbackInfo()})
+}
+
+// RunFinalHook runs the actions of a hook that follows an operation, e.g. afterBuild or onBuildFailure.
+// Unlike RunHook, the actions are run even if ctx is canceled, so an interrupted operation is reported too;
+// they are limited by callback.FinalHookTimeout instead.
+//
+// Parameters:
+//   - ctx: The context of the operation.
+//   - hook: The name of the hook, e.g. callback.AfterBuild.
+//   - err: The error of the operation, or nil; it defines the outcome status.
+//   - logger: The log the output of the commands is written to; nil discards the output.
+//
+// Returns:
+//   - error: The errors of the failed actions, or nil.
+func (m *Module) RunFinalHook(ctx context.Context, hook string, err error, logger interfaces.Logger) error {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), callback.FinalHookTimeout)
+	defer cancel()
+
+	return m.RunHook(ctx, hook, err, logger)
+}
+
+// CallbackInfo returns the build context passed to the callbacks and the hooks of the module:
+// the name, version and label of the module, the version directory, the path of the release
+// archive, the Git commit and the log directory. The stage and the number of files are left empty.
+func (m *Module) CallbackInfo() types.CallbackInfo {
+	info := types.CallbackInfo{
+		Module:  m.Name,
+		Version: m.Version,
+		Label:   string(m.GetLabel()),
+		Commit:  m.commit(),
+	}
+
+	if m.Log != nil {
+		info.LogDir = m.Log.Dir
+	}
+
+	if m.BuildDirectory != "" {
+		info.VersionDir, _ = makeVersionDirectory(m)
+		info.ZipPath, _ = makeZipFilePath(m)
+	}
+
+	return info
+}
+
 // ValidateChangelog validates the changelog configuration of the module.
 // It checks for the presence and correctness of required fields:
 //   - Ensures 'repository' is specified if 'from' or 'to' types are defined.
@@ -211,7 +340,97 @@ func (m *Module) ValidateChangelog() error {
 		return nil
 	}
 
-	return m.Changelog.IsValid()
+	if err := m.Changelog.IsValid(); err != nil {
+		return err
+	}
+
+	_, err := m.ChangelogScope()
+
+	return err
+}
+
+// ChangelogScope returns the repository-relative paths that limit the changelog and the list
+// of changed files.
+//
+// If `changelog.paths` is set, these paths are returned as is.
+// If `changelog.scope` is `stages`, the `From` roots of the release stages are made relative to
+// the root of the repository working tree; roots outside the repository are ignored.
+// Relative roots are resolved as the build reads them, see stageSourcePath.
+// Otherwise, nil is returned, which means that the whole repository is used.
+//
+// Returns:
+//   - []string: The scope paths using forward slashes.
+//   - error: An error if the repository path cannot be resolved or no stage root lies inside it.
+func (m *Module) ChangelogScope() ([]string, error) {
+	if len(m.Changelog.Paths) > 0 {
+		scope := make([]string, 0, len(m.Changelog.Paths))
+		for _, path := range m.Changelog.Paths {
+			scope = append(scope, filepath.ToSlash(filepath.Clean(strings.TrimSpace(path))))
+		}
+		return scope, nil
+	}
+
+	if m.Changelog.Scope != types.StagesScope {
+		return nil, nil
+	}
+
+	root, err := repositoryRootFunc(m.Repository)
+	if err != nil {
+		return nil, err
+	}
+
+	var scope []string
+	for _, name := range m.Builds.Release {
+		stage, err := m.FindStage(name)
+		if err != nil {
+			continue
+		}
+
+		for _, from := range stage.From {
+			abs, err := stageSourcePath(from)
+			if err != nil {
+				return nil, err
+			}
+
+			rel, ok := repositoryPath(root, abs)
+			if !ok {
+				continue
+			}
+
+			if !slices.Contains(scope, rel) {
+				scope = append(scope, rel)
+			}
+		}
+	}
+
+	if len(scope) == 0 {
+		return nil, fmt.Errorf("changelog scope: no stage sources inside repository [%s]", m.Repository)
+	}
+
+	return scope, nil
+}
+
+// stageSourcePath returns the absolute path of a stage `From` root. Relative roots are resolved
+// against the working directory, as the build reads them: fs.PathProcessing walks them as is,
+// and repo.TreeSource resolves them the same way before looking them up in the tree.
+func stageSourcePath(from string) (string, error) {
+	return filepath.Abs(from)
+}
+
+// repositoryPath returns the slash-separated path of abs relative to the repository root.
+// It returns false if the path is outside the repository or on another volume.
+func repositoryPath(root, abs string) (string, bool) {
+	rel, err := filepath.Rel(root, abs)
+	if err != nil {
+		return "", false
+	}
+
+	rel = filepath.ToSlash(rel)
+	if rel == ".." || strings.HasPrefix(rel, "../") {
+		return "", false
+	}
+
+	return rel, true
 }
 
 // FindStage searches for a stage with the specified name in the module.
@@ -1,6 +1,8 @@
 package module
 
 import (
+	"context"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -463,6 +465,40 @@ func TestModule_PasswordEnv(t *testing.T) {
 	}
 }
 
+func TestModule_PortalURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     string
+		portal  string
+		want    string
+		wantErr bool
+	}{
+		{"default", "", "", DefaultPortalURL, false},
+		{"module", "", "http://127.0.0.1:8080/", "http://127.0.0.1:8080", false},
+		{"module path", "", "https://portal.example.com/partners", "https://portal.example.com/partners", false},
+		{"env overrides module", "https://staging.example.com", "http://127.0.0.1:8080", "https://staging.example.com", false},
+		{"relative", "", "portal.example.com", "", true},
+		{"scheme", "", "ftp://portal.example.com", "", true},
+		{"query", "", "https://portal.example.com/?a=b", "", true},
+		{"invalid env", "://", "https://portal.example.com", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(PortalEnv, tt.env)
+
+			m := &Module{Portal: tt.portal}
+			got, err := m.PortalURL()
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got.String())
+		})
+	}
+}
+
 func TestModule_ValidateChangelog(t *testing.T) {
 	t.Parallel()
 	type fields struct {
@@ -814,3 +850,145 @@ func TestModule_StageCallback_found(t *testing.T) {
 	_, err := mod.StageCallback("stage_1")
 	require.NoError(t, err)
 }
+
+func TestModule_RunHook(t *testing.T) {
+	t.Parallel()
+
+	mod := Module{}
+	require.NoError(t, mod.RunHook(context.Background(), callback.AfterBuild, nil, nil), "no hooks")
+
+	mod.Hooks = &callback.Hooks{
+		AfterPush: []callback.CallbackParameters{{Type: callback.CommandType, Action: "bx-missing-command"}},
+	}
+	require.NoError(t, mod.RunHook(context.Background(), callback.AfterBuild, nil, nil), "no actions of the hook")
+	require.ErrorContains(t, mod.RunHook(context.Background(), callback.AfterPush, nil, nil), "hook afterPush [0] failed")
+}
+
+func TestModule_ChangelogScope(t *testing.T) {
+	t.Parallel()
+
+	stages := []types.Stage{
+		{Name: "inside", From: []string{"./lib", "./lib/sub", "./lib"}},
+		{Name: "outside", From: []string{"/tmp/other"}},
+		{Name: "unused", From: []string{"./unused"}},
+	}
+
+	tests := []struct {
+		name      string
+		changelog changelog.Changelog
+		want      []string
+		release   []string
+		wantErr   bool
+	}{
+		{"no scope", changelog.Changelog{}, nil, []string{"inside"}, false},
+		{
+			"explicit paths",
+			changelog.Changelog{Paths: []string{"modules/a/", " modules/b "}},
+			[]string{"modules/a", "modules/b"},
+			[]string{"inside"},
+			false,
+		},
+		{
+			"stages",
+			changelog.Changelog{Scope: types.StagesScope},
+			[]string{"internal/module/lib", "internal/module/lib/sub"},
+			[]string{"inside", "outside"},
+			false,
+		},
+		{
+			"stages outside repository",
+			changelog.Changelog{Scope: types.StagesScope},
+			nil,
+			[]string{"outside"},
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			m := &Module{
+				Repository: ".",
+				Stages:     stages,
+				Builds:     types.Builds{Release: tt.release},
+				Changelog:  tt.changelog,
+			}
+
+			got, err := m.ChangelogScope()
+			if tt.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestModule_ChangelogScope_workingDirectory(t *testing.T) {
+	root, err := filepath.Abs("../../")
+	require.NoError(t, err)
+
+	// relative stage roots are read from the working directory, and so is the scope
+	t.Chdir(filepath.Join(root, "internal"))
+
+	m := &Module{
+		Repository: root,
+		Stages:     []types.Stage{{Name: "lib", From: []string{"module/lib"}}},
+		Builds:     types.Builds{Release: []string{"lib"}},
+		Changelog:  changelog.Changelog{Scope: types.StagesScope},
+	}
+
+	scope, err := m.ChangelogScope()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"internal/module/lib"}, scope)
+}
+
+func Test_repositoryPath(t *testing.T) {
+	t.Parallel()
+
+	root := filepath.FromSlash("/repo")
+	tests := []struct {
+		abs  string
+		want string
+		ok   bool
+	}{
+		{"/repo", ".", true},
+		{"/repo/lib/sub", "lib/sub", true},
+		{"/repo/..lib", "..lib", true},
+		{"/other", "", false},
+		{"/repository", "", false},
+		{"/", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.abs, func(t *testing.T) {
+			t.Parallel()
+
+			got, ok := repositoryPath(root, filepath.FromSlash(tt.abs))
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestModule_CallbackInfo(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	mod := Module{Name: "vendor.module", Version: "1.1.0", BuildDirectory: dir}
+
+	assert.Equal(t, types.CallbackInfo{
+		Module:     "vendor.module",
+		Version:    "1.1.0",
+		Label:      "alpha",
+		VersionDir: filepath.Join(dir, "1.1.0"),
+		ZipPath:    filepath.Join(dir, "1.1.0.zip"),
+	}, mod.CallbackInfo())
+
+	mod = Module{Name: "vendor.module", Version: "1.1.0", Label: types.Stable}
+	assert.Equal(t, types.CallbackInfo{Module: "vendor.module", Version: "1.1.0", Label: "stable"},
+		mod.CallbackInfo(), "no build directory")
+
+	mod.Log = &types.Log{Dir: "./logs"}
+	assert.Equal(t, "./logs", mod.CallbackInfo().LogDir)
+}
@@ -1,6 +1,9 @@
 package module
 
 import (
+	"fmt"
+	"os"
+	"path/filepath"
 	"sync"
 
 	"github.com/pixel365/bx/internal/types/changelog"
@@ -8,27 +11,51 @@ import (
 	"github.com/pixel365/bx/internal/types"
 
 	"github.com/pixel365/bx/internal/callback"
+	"github.com/pixel365/bx/internal/secret"
 )
 
+// WorktreeWarning is reported when a build includes uncommitted changes.
+const WorktreeWarning = "Warning: the release includes uncommitted working tree changes " +
+	"(changelog.to.type: worktree) and cannot be reproduced from a commit"
+
+// DefaultPortalURL is the base URL of the partner portal used when none is configured.
+const DefaultPortalURL = "https://partners.1c-bitrix.ru"
+
+// PortalEnv is the environment variable that overrides the partner portal base URL of all modules.
+const PortalEnv = "BX_PORTAL_URL"
+
 type Module struct {
-	Variables      map[string]string   `yaml:"variables,omitempty"`
-	Run            map[string][]string `yaml:"run,omitempty"`
-	changes        *types.Changes      `yaml:"-"`
-	Log            *types.Log          `yaml:"log,omitempty"`
-	Name           string              `yaml:"name"`
-	Version        string              `yaml:"version"`
-	Description    string              `yaml:"description,omitempty"`
-	Repository     string              `yaml:"repository,omitempty"`
-	Account        string              `yaml:"account"`
-	BuildDirectory string              `yaml:"buildDirectory,omitempty"`
-	Label          types.VersionLabel  `yaml:"label,omitempty"`
-	Builds         types.Builds        `yaml:"builds"`
-	Ignore         []string            `yaml:"ignore"`
-	Stages         []types.Stage       `yaml:"stages"`
-	Callbacks      []callback.Callback `yaml:"callbacks,omitempty"`
-	Changelog      changelog.Changelog `yaml:"changelog,omitempty"`
-	mu             sync.Mutex          `yaml:"-"`
-	LastVersion    bool                `yaml:"-"`
+	Variables        map[string]string       `yaml:"variables,omitempty"`
+	Run              map[string][]string     `yaml:"run,omitempty"`
+	Descriptions     map[types.Locale]string `yaml:"descriptions,omitempty"`
+	changes          *types.Changes          `yaml:"-"`
+	source           types.Source            `yaml:"-"`
+	secrets          []secret.Value          `yaml:"-"`
+	Log              *types.Log              `yaml:"log,omitempty"`
+	Session          *types.Session          `yaml:"session,omitempty"`
+	HTTP             *types.HTTP             `yaml:"http,omitempty"`
+	Name             string                  `yaml:"name"`
+	Version          string                  `yaml:"version"`
+	Description      types.Description       `yaml:"description,omitempty"`
+	Repository       string                  `yaml:"repository,omitempty"`
+	Account          string                  `yaml:"account"`
+	Portal           string                  `yaml:"portal,omitempty"`
+	PasswordFile     string                  `yaml:"passwordFile,omitempty"`
+	CredentialHelper string                  `yaml:"credentialHelper,omitempty"`
+	BuildDirectory   string                  `yaml:"buildDirectory,omitempty"`
+	Ref              string                  `yaml:"-"`
+	Label            types.VersionLabel      `yaml:"label,omitempty"`
+	Builds           types.Builds            `yaml:"builds"`
+	Ignore           []string                `yaml:"ignore"`
+	Promotion        []types.PromotionRule   `yaml:"promotion,omitempty"`
+	Stages           []types.Stage           `yaml:"stages"`
+	Callbacks        []callback.Callback     `yaml:"callbacks,omitempty"`
+	Hooks            *callback.Hooks         `yaml:"hooks,omitempty"`
+	Changelog        changelog.Changelog     `yaml:"changelog,omitempty"`
+	head             string                  `yaml:"-"`
+	headOnce         sync.Once               `yaml:"-"`
+	mu               sync.Mutex              `yaml:"-"`
+	LastVersion      bool                    `yaml:"-"`
 }
 
 func (m *Module) GetVersion() string {
@@ -38,6 +65,45 @@ func (m *Module) GetVersion() string {
 	return m.Version
 }
 
+// LocalDescription returns the manually set release description for the locale.
+//
+// For Russian, Description is used unless Descriptions contains the `ru` key.
+// If Description refers to a Markdown file, the section of the current version
+// is read from it and converted to HTML.
+//
+// Returns an error if the file cannot be read or has no section for the version.
+func (m *Module) LocalDescription(locale types.Locale) (string, error) {
+	if d := m.Descriptions[locale]; d != "" {
+		return d, nil
+	}
+
+	if locale != types.Ru {
+		return "", nil
+	}
+
+	if m.Description.FromFile == "" {
+		return m.Description.Text, nil
+	}
+
+	data, err := os.ReadFile(filepath.Clean(m.Description.FromFile))
+	if err != nil {
+		return "", fmt.Errorf("description.fromFile: %w", err)
+	}
+
+	section, err := changelog.VersionSection(string(data), m.Version)
+	if err != nil {
+		return "", fmt.Errorf("description.fromFile [%s]: %w", m.Description.FromFile, err)
+	}
+
+	return changelog.MarkdownToHTML(section), nil
+}
+
+// UsesWorktree reports whether the release includes uncommitted working tree changes.
+// It is never the case for the full latest version, which does not use the changelog.
+func (m *Module) UsesWorktree() bool {
+	return !m.LastVersion && m.Repository != "" && m.Changelog.UsesWorktree()
+}
+
 func (m *Module) GetLabel() types.VersionLabel {
 	switch m.Label {
 	case types.Alpha, types.Beta, types.Stable:
@@ -46,3 +112,22 @@ func (m *Module) GetLabel() types.VersionLabel {
 		return types.Alpha
 	}
 }
+
+// Secrets returns the decrypted values of the `!secret` fields of the module configuration.
+func (m *Module) Secrets() []string {
+	values := make([]string, 0, len(m.secrets))
+	for _, v := range m.secrets {
+		values = append(values, v.Plain)
+	}
+
+	return values
+}
+
+// PromotionRules returns the promotion rules of the module, or types.DefaultPromotionRules if none are set.
+func (m *Module) PromotionRules() []types.PromotionRule {
+	if len(m.Promotion) == 0 {
+		return types.DefaultPromotionRules()
+	}
+
+	return m.Promotion
+}
@@ -1,11 +1,15 @@
 package module
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/pixel365/bx/internal/types"
+	"github.com/pixel365/bx/internal/types/changelog"
 )
 
 func TestModule_GetVersion(t *testing.T) {
@@ -62,3 +66,73 @@ func TestModule_GetLabel(t *testing.T) {
 		})
 	}
 }
+
+func TestModule_LocalDescription(t *testing.T) {
+	t.Parallel()
+
+	file := filepath.Join(t.TempDir(), "CHANGELOG.md")
+	content := "# Changelog\n\n## [1.1.0] - 2025-02-01\n### Fixed\n- Cache reset\n\n## [1.0.0]\n- Initial\n"
+	require.NoError(t, os.WriteFile(file, []byte(content), 0600))
+
+	tests := []struct {
+		m       *Module
+		name    string
+		locale  types.Locale
+		want    string
+		wantErr bool
+	}{
+		{&Module{Description: types.Description{Text: "text"}}, "text", types.Ru, "text", false},
+		{&Module{Description: types.Description{Text: "text"}}, "text en", types.En, "", false},
+		{&Module{
+			Description:  types.Description{Text: "text"},
+			Descriptions: map[types.Locale]string{types.En: "notes"},
+		}, "descriptions en", types.En, "notes", false},
+		{&Module{
+			Version:     "1.1.0",
+			Description: types.Description{FromFile: file},
+		}, "from file", types.Ru, "<b>Fixed</b><br><ul><li>Cache reset</li></ul>", false},
+		{&Module{
+			Version:     "2.0.0",
+			Description: types.Description{FromFile: file},
+		}, "missing section", types.Ru, "", true},
+		{&Module{
+			Version:     "1.1.0",
+			Description: types.Description{FromFile: file + ".missing"},
+		}, "missing file", types.Ru, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := tt.m.LocalDescription(tt.locale)
+			if tt.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestModule_UsesWorktree(t *testing.T) {
+	t.Parallel()
+	worktree := changelog.Changelog{To: types.TypeValue[types.ChangelogType, string]{Type: types.Worktree}}
+	tag := changelog.Changelog{To: types.TypeValue[types.ChangelogType, string]{Type: types.Tag, Value: "v1"}}
+
+	tests := []struct {
+		m    *Module
+		name string
+		want bool
+	}{
+		{&Module{Repository: ".", Changelog: worktree}, "worktree", true},
+		{&Module{Repository: ".", Changelog: tag}, "tag", false},
+		{&Module{Changelog: worktree}, "no repository", false},
+		{&Module{Repository: ".", Changelog: worktree, LastVersion: true}, "last version", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, tt.m.UsesWorktree())
+		})
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"runtime"
 	"sync"
+	"sync/atomic"
 
 	"github.com/pixel365/bx/internal/errors"
 	"github.com/pixel365/bx/internal/fs"
@@ -88,7 +89,8 @@ func HandleStages(
 // CheckStages validates the paths in the stages of the given module.
 //
 // This function iterates over the stages in the provided module and concurrently
-// checks the paths defined in each stage using goroutines. If any errors are encountered,
+// checks the paths defined in each stage using goroutines. If the module is built from a Git ref,
+// the paths are checked in the tree at the ref instead of the file system. If any errors are encountered,
 // they are collected in a channel and returned as a combined error.
 //
 // Parameters:
@@ -108,7 +110,11 @@ func CheckStages(module *Module) error {
 
 	for _, item := range module.Stages {
 		wg.Go(func() {
-			checkPathsFunc(item, errCh)
+			if module.source != nil {
+				checkSourcePaths(module.source, item, errCh)
+			} else {
+				checkPathsFunc(item, errCh)
+			}
 		})
 	}
 
@@ -130,13 +136,15 @@ func CheckStages(module *Module) error {
 //
 // The function performs the following steps:
 //  1. Logs the start and completion of the stage via `logCh`.
-//  2. Resolves a `Runnable` using the provided callback `cb` and executes its `PreRun` hook.
+//  2. Resolves a `Runnable` using the provided callback `cb` and executes its `PreRun` hook;
+//     the output of the hook is sent to `logCh`.
 //  3. Validates the context and resolves the target directory for the stage output.
 //  4. Create the target directory (recursively if needed).
 //  5. For each input path in `stage.From`, spawns a goroutine that validates the context,
 //     builds a copy `Path` struct, and sends it to `filesCh` to be processed by `copyWorkers`.
 //  6. Wait for all spawned copy goroutines to finish.
-//  7. If the runner was initialized, execute the `PostRun` hook.
+//  7. If the runner was initialized, execute the `PostRun` hook with the number of files
+//     submitted by the stage.
 //
 // Errors from directory creation, pre/post run hooks, or file copy failures are sent to `errCh`.
 // If the context is canceled at any point, the function exits early.
@@ -167,8 +175,10 @@ func handleStage(
 
 	runner, cbErr := cb(stage.Name)
 
+	var info types.CallbackInfo
 	if cbErr == nil {
-		if err := runner.PreRun(ctx); err != nil {
+		info = stageCallbackInfo(module, stage, rootDir)
+		if err := runner.PreRun(ctx, info, channelLogger(logCh)); err != nil {
 			errCh <- fmt.Errorf("pre-run callback failed for stage %s: %w", stage.Name, err)
 			return
 		}
@@ -196,10 +206,14 @@ func handleStage(
 		return
 	}
 
+	stageFilesCh, copied := countFiles(ctx, filesCh)
+
 	var wg sync.WaitGroup
+	interrupted := false
 	for _, from := range stage.From {
-		if err = helpers.CheckContext(ctx); err != nil {
-			return
+		if helpers.CheckContext(ctx) != nil {
+			interrupted = true
+			break
 		}
 
 		fromCopy := from
@@ -210,6 +224,7 @@ func handleStage(
 			}
 
 			path := types.Path{
+				Source:         module.source,
 				From:           fromCopy,
 				To:             to,
 				ActionIfExists: stage.ActionIfFileExists,
@@ -218,7 +233,7 @@ func handleStage(
 
 			if err := fs.PathProcessing(
 				ctx,
-				filesCh,
+				stageFilesCh,
 				module,
 				path,
 				stage.Filter,
@@ -229,14 +244,80 @@ func handleStage(
 	}
 
 	wg.Wait()
+	info.Files = copied()
+
+	if interrupted {
+		return
+	}
 
 	if runner != nil {
-		if err = runner.PostRun(ctx); err != nil {
+		if err = runner.PostRun(ctx, info, channelLogger(logCh)); err != nil {
 			errCh <- fmt.Errorf("post-run callback failed for stage %s: %w", stage.Name, err)
 		}
 	}
 }
 
+// stageCallbackInfo returns the build context passed to the callbacks of the stage.
+// Without a root directory (custom commands), there is no version directory and no archive.
+func stageCallbackInfo(module *Module, stage types.Stage, rootDir string) types.CallbackInfo {
+	info := module.CallbackInfo()
+	info.Stage = stage.Name
+
+	if rootDir == "" {
+		info.VersionDir, info.ZipPath = "", ""
+	}
+
+	return info
+}
+
+// countFiles forwards the copy tasks sent to the returned channel to filesCh and counts
+// the files the copy workers write. The returned function closes the channel, waits until
+// the copy workers process the forwarded tasks and returns the number of written files;
+// it must be called after all tasks are sent.
+func countFiles(ctx context.Context, filesCh chan<- types.Path) (chan<- types.Path, func() int) {
+	ch := make(chan types.Path)
+	forwarded := make(chan struct{})
+
+	var processed sync.WaitGroup
+	var copied atomic.Int64
+
+	go func() {
+		defer close(forwarded)
+		for path := range ch {
+			path.Done = func(ok bool) {
+				if ok {
+					copied.Add(1)
+				}
+				processed.Done()
+			}
+
+			processed.Add(1)
+			select {
+			case filesCh <- path:
+			case <-ctx.Done():
+				processed.Done()
+			}
+		}
+	}()
+
+	return ch, func() int {
+		close(ch)
+		<-forwarded
+		processed.Wait()
+
+		return int(copied.Load())
+	}
+}
+
+// checkSourcePaths checks that every `From` path of the stage exists in the source.
+func checkSourcePaths(source types.Source, stage types.Stage, ch chan<- error) {
+	for _, path := range stage.From {
+		if _, err := source.IsDir(path); err != nil {
+			ch <- err
+		}
+	}
+}
+
 func workersQty(n int) int {
 	minWorkers := runtime.NumCPU() * 2
 	cnt := n
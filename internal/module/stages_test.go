@@ -2,13 +2,20 @@ package module
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"runtime"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/pixel365/bx/internal/callback"
 	"github.com/pixel365/bx/internal/interfaces"
 
 	errors2 "github.com/pixel365/bx/internal/errors"
@@ -112,3 +119,73 @@ func Test_workersQty(t *testing.T) {
 	n := cnt * 2
 	assert.Equal(t, n, workersQty(n))
 }
+
+func Test_countFiles(t *testing.T) {
+	t.Parallel()
+
+	filesCh := make(chan types.Path, 3)
+	go func() {
+		for path := range filesCh {
+			path.Done(path.From != "skipped")
+		}
+	}()
+	defer close(filesCh)
+
+	ch, copied := countFiles(context.Background(), filesCh)
+
+	ch <- types.Path{From: "a"}
+	ch <- types.Path{From: "skipped"}
+	ch <- types.Path{From: "b"}
+	assert.Equal(t, 2, copied(), "only the written files are counted")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	ch, copied = countFiles(ctx, make(chan types.Path))
+	ch <- types.Path{From: "a"}
+	assert.Equal(t, 0, copied(), "tasks are dropped after cancellation")
+}
+
+func TestHandleStages_callbackInfo(t *testing.T) {
+	t.Parallel()
+
+	from := t.TempDir()
+	for _, name := range []string{"a.php", "b.php", "lang/ru/a.php"} {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(from, name)), 0o750))
+		require.NoError(t, os.WriteFile(filepath.Join(from, name), []byte("<?php"), 0o600))
+	}
+
+	var mu sync.Mutex
+	var payloads []map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		mu.Lock()
+		payloads = append(payloads, payload)
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	action := callback.CallbackParameters{
+		Type: callback.ExternalType, Action: server.URL, Method: http.MethodPost, Payload: callback.PayloadJSON,
+	}
+	m := &Module{
+		Name:           "vendor.module",
+		Version:        "1.1.0",
+		BuildDirectory: t.TempDir(),
+		Stages: []types.Stage{
+			{Name: "components", To: "install", From: []string{from}, ActionIfFileExists: types.Replace},
+		},
+		Callbacks: []callback.Callback{{Stage: "components", Pre: action, Post: action}},
+	}
+
+	err := HandleStages(context.Background(), []string{"components"}, m, &FakeBuildLogger{}, false)
+	require.NoError(t, err)
+
+	require.Len(t, payloads, 2)
+	assert.Equal(t, "components", payloads[0]["stage"])
+	assert.InDelta(t, 0, payloads[0]["files"], 0)
+	assert.InDelta(t, 3, payloads[1]["files"], 0)
+	assert.Equal(t, filepath.Join(m.BuildDirectory, "1.1.0"), payloads[1]["versionDir"])
+	assert.Equal(t, "vendor.module", payloads[1]["module"])
+}
@@ -135,6 +135,24 @@ func validateMainFields(m *Module) error {
 	return nil
 }
 
+func validateDescriptions(m *Module) error {
+	for locale := range m.Descriptions {
+		if !locale.IsValid() {
+			return fmt.Errorf("descriptions [%s]: locale must be %s or %s", locale, types.Ru, types.En)
+		}
+	}
+
+	if m.Description.Text != "" && m.Description.FromFile != "" {
+		return e.New("description text and fromFile cannot be used together")
+	}
+
+	if !m.Description.IsEmpty() && m.Descriptions[types.Ru] != "" {
+		return e.New("description and descriptions.ru cannot be used together")
+	}
+
+	return nil
+}
+
 func validateLog(m *Module) error {
 	if m.Log == nil {
 		return nil
@@ -159,6 +177,67 @@ func validateLog(m *Module) error {
 	return nil
 }
 
+func validateSession(m *Module) error {
+	if m.Session == nil {
+		return nil
+	}
+
+	if m.Session.TTL < 0 {
+		return e.New("session ttl must not be negative")
+	}
+
+	return nil
+}
+
+func validateHTTP(m *Module) error {
+	if m.HTTP == nil {
+		return nil
+	}
+
+	if m.HTTP.Timeout < 0 || m.HTTP.UploadTimeout < 0 {
+		return e.New("http timeouts must not be negative")
+	}
+
+	if m.HTTP.RetryDelay < 0 || m.HTTP.MaxRetryDelay < 0 {
+		return e.New("http retry delays must not be negative")
+	}
+
+	if m.HTTP.Retries != nil && *m.HTTP.Retries < 0 {
+		return e.New("http retries must not be negative")
+	}
+
+	if m.HTTP.RetryDelay > 0 && m.HTTP.MaxRetryDelay > 0 && m.HTTP.MaxRetryDelay < m.HTTP.RetryDelay {
+		return e.New("http maxRetryDelay must not be less than retryDelay")
+	}
+
+	return nil
+}
+
+func validatePromotion(m *Module) error {
+	from := make(map[types.VersionLabel]struct{}, len(m.Promotion))
+
+	for index, rule := range m.Promotion {
+		for _, label := range []types.VersionLabel{rule.From, rule.To} {
+			switch label {
+			case types.Alpha, types.Beta, types.Stable:
+			default:
+				return fmt.Errorf("promotion [%d]: %w: %q", index, errors.ErrInvalidLabel, label)
+			}
+		}
+
+		if rule.From == rule.To {
+			return fmt.Errorf("promotion [%d]: from and to must differ", index)
+		}
+
+		if _, ok := from[rule.From]; ok {
+			return fmt.Errorf("promotion [%d]: duplicate rule for %s", index, rule.From)
+		}
+		from[rule.From] = struct{}{}
+	}
+
+	return nil
+}
+
 func validateStagesList(
 	stages []string,
 	name string,
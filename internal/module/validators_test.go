@@ -2,6 +2,7 @@ package module
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -556,6 +557,134 @@ func TestValidateLog(t *testing.T) {
 	}
 }
 
+func Test_validateSession(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		m       *Module
+		name    string
+		wantErr bool
+	}{
+		{&Module{}, "empty", false},
+		{&Module{Session: &types.Session{Cache: true}}, "default ttl", false},
+		{&Module{Session: &types.Session{Cache: true, TTL: time.Hour}}, "ttl", false},
+		{&Module{Session: &types.Session{Cache: true, TTL: -time.Hour}}, "negative ttl", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := validateSession(tt.m)
+			if tt.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func Test_validateHTTP(t *testing.T) {
+	t.Parallel()
+	negative := -1
+	zero := 0
+	tests := []struct {
+		m       *Module
+		name    string
+		wantErr bool
+	}{
+		{&Module{}, "empty", false},
+		{&Module{HTTP: &types.HTTP{
+			Timeout:       time.Second,
+			UploadTimeout: time.Minute,
+			Retries:       &zero,
+			RetryDelay:    time.Second,
+			MaxRetryDelay: time.Minute,
+		}}, "valid", false},
+		{&Module{HTTP: &types.HTTP{Timeout: -time.Second}}, "negative timeout", true},
+		{&Module{HTTP: &types.HTTP{UploadTimeout: -time.Second}}, "negative upload timeout", true},
+		{&Module{HTTP: &types.HTTP{RetryDelay: -time.Second}}, "negative delay", true},
+		{&Module{HTTP: &types.HTTP{Retries: &negative}}, "negative retries", true},
+		{&Module{HTTP: &types.HTTP{RetryDelay: time.Minute, MaxRetryDelay: time.Second}}, "max below delay", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := validateHTTP(tt.m)
+			if tt.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func Test_validatePromotion(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		m       *Module
+		name    string
+		wantErr bool
+	}{
+		{&Module{}, "empty", false},
+		{&Module{Promotion: types.DefaultPromotionRules()}, "default rules", false},
+		{&Module{Promotion: []types.PromotionRule{{From: types.Alpha, To: types.Stable}}}, "skip beta", false},
+		{&Module{Promotion: []types.PromotionRule{{From: types.Alpha, To: "gamma"}}}, "invalid label", true},
+		{&Module{Promotion: []types.PromotionRule{{From: types.Beta, To: types.Beta}}}, "same labels", true},
+		{&Module{Promotion: []types.PromotionRule{
+			{From: types.Alpha, To: types.Beta},
+			{From: types.Alpha, To: types.Stable},
+		}}, "duplicate from", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := validatePromotion(tt.m)
+			if tt.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func Test_validateDescriptions(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		m       *Module
+		name    string
+		wantErr bool
+	}{
+		{&Module{}, "empty", false},
+		{&Module{Description: types.Description{Text: "описание"}}, "description", false},
+		{&Module{Descriptions: map[types.Locale]string{types.Ru: "описание", types.En: "notes"}}, "locales", false},
+		{&Module{Descriptions: map[types.Locale]string{"de": "notes"}}, "invalid locale", true},
+		{&Module{
+			Description:  types.Description{Text: "описание"},
+			Descriptions: map[types.Locale]string{types.Ru: "описание"},
+		}, "duplicate ru", true},
+		{&Module{Description: types.Description{FromFile: "CHANGELOG.md"}}, "from file", false},
+		{&Module{
+			Description: types.Description{Text: "описание", FromFile: "CHANGELOG.md"},
+		}, "text and file", true},
+		{&Module{
+			Description:  types.Description{FromFile: "CHANGELOG.md"},
+			Descriptions: map[types.Locale]string{types.Ru: "описание"},
+		}, "file and ru", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := validateDescriptions(tt.m)
+			if tt.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
 func Test_validateMainFields(t *testing.T) {
 	t.Parallel()
 	tests := []struct {
@@ -2,6 +2,7 @@ package module
 
 import (
 	"context"
+	"fmt"
 	"sync"
 
 	"github.com/pixel365/bx/internal/interfaces"
@@ -42,7 +43,7 @@ func cleanupWorker(
 // copyWorkers launches a fixed number of worker goroutines to process file copy tasks.
 //
 // Each worker reads from the `filesCh` channel and invokes the `copyFileFunc` function
-// to handle the file copying.
+// to handle the file copying, then reports the result to the `Done` function of the task, if any.
 // Workers exit when the channel is closed.
 //
 // Parameters:
@@ -56,7 +57,10 @@ func copyWorkers(ctx context.Context, wg *sync.WaitGroup, filesCh chan types.Pat
 	for i := 0; i < workersCount; i++ {
 		wg.Go(func() {
 			for file := range filesCh {
-				copyFileFunc(ctx, errCh, file)
+				copied := copyFileFunc(ctx, errCh, file)
+				if file.Done != nil {
+					file.Done(copied)
+				}
 			}
 		})
 	}
@@ -96,3 +100,23 @@ func logWorker(ch <-chan string, logger interfaces.Logger) {
 		logger.Info(msg)
 	}
 }
+
+// channelLogger is a Logger that sends the messages to the logging worker through the channel,
+// so the messages of the stages keep their order.
+type channelLogger chan<- string
+
+// Info sends an informational message, formatted with the optional arguments.
+func (l channelLogger) Info(message string, args ...any) {
+	if len(args) > 0 {
+		message = fmt.Sprintf(message, args...)
+	}
+	l <- message
+}
+
+// Error sends an error message, formatted with the optional arguments, followed by the error.
+func (l channelLogger) Error(message string, err error, args ...any) {
+	if len(args) > 0 {
+		message = fmt.Sprintf(message, args...)
+	}
+	l <- fmt.Sprintf("%s: %v", message, err)
+}
@@ -16,10 +16,16 @@ func TestCopyWorkers(t *testing.T) {
 	var mu sync.Mutex
 	var called []types.Path
 
-	copyFileFunc = func(ctx context.Context, errCh chan<- error, path types.Path) {
+	original := copyFileFunc
+	defer func() {
+		copyFileFunc = original
+	}()
+
+	copyFileFunc = func(ctx context.Context, errCh chan<- error, path types.Path) bool {
 		mu.Lock()
 		called = append(called, path)
 		mu.Unlock()
+		return path.From == "a.txt"
 	}
 
 	filesCh := make(chan types.Path, 3)
@@ -30,13 +36,19 @@ func TestCopyWorkers(t *testing.T) {
 	var wg sync.WaitGroup
 	copyWorkers(ctx, &wg, filesCh, errCh, 2)
 
-	filesCh <- types.Path{From: "a.txt", To: "x"}
+	var done []bool
+	filesCh <- types.Path{From: "a.txt", To: "x", Done: func(copied bool) {
+		mu.Lock()
+		done = append(done, copied)
+		mu.Unlock()
+	}}
 	filesCh <- types.Path{From: "b.txt", To: "y"}
 	close(filesCh)
 
 	wg.Wait()
 
 	assert.Len(t, called, 2)
+	assert.Equal(t, []bool{true}, done, "the result is reported to the task")
 }
 
 func TestErrorWorker(t *testing.T) {
@@ -71,6 +83,29 @@ func TestLogWorker(t *testing.T) {
 	assert.Equal(t, "world", mock.Logs[1])
 }
 
+func TestChannelLogger(t *testing.T) {
+	t.Parallel()
+
+	logCh := make(chan string, 3)
+	logger := channelLogger(logCh)
+
+	logger.Info("done")
+	logger.Info("stage %s: %s", "components", "100% copied")
+	logger.Error("callback of %s failed", errors.New("exit code 1"), "components")
+	close(logCh)
+
+	var logs []string
+	for msg := range logCh {
+		logs = append(logs, msg)
+	}
+
+	assert.Equal(t, []string{
+		"done",
+		"stage components: 100% copied",
+		"callback of components failed: exit code 1",
+	}, logs)
+}
+
 func TestCleanupWorker(t *testing.T) {
 	var stageWg, copyWg sync.WaitGroup
 	stageWg.Add(1)
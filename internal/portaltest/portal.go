@@ -0,0 +1,408 @@
+// Package portaltest provides an in-process fake of the 1C-Bitrix partner portal for tests.
+//
+// The fake serves the same pages the request package talks to, with the markup the parsers
+// expect: the login form, the module edit page with the sessid input, the versions table
+// and the deploy form. It keeps accounts, modules, versions and uploads in memory, so the
+// push, list and label commands can be exercised end-to-end without network access.
+//
+// Example:
+//
+//	portal := portaltest.NewPortal()
+//	defer portal.Close()
+//
+//	portal.AddAccount("partner", "secret")
+//	portal.AddModule("partner", "vendor.module", types.Versions{"1.0.0": types.Stable})
+//
+//	mod := &module.Module{Name: "vendor.module", Account: "partner", Portal: portal.URL()}
+package portaltest
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"html/template"
+	"io"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/pixel365/bx/internal/helpers"
+	"github.com/pixel365/bx/internal/types"
+)
+
+const (
+	sessionCookie = "PHPSESSID"
+	loginCookie   = "BITRIX_SM_LOGIN"
+	maxUploadSize = 64 << 20
+)
+
+// Upload is a release archive received by the deploy page.
+type Upload struct {
+	Module   string
+	Version  string
+	FileName string
+	Data     []byte
+}
+
+// Portal is a fake partner portal served by an httptest.Server.
+// It is safe for concurrent use.
+type Portal struct {
+	server      *httptest.Server
+	accounts    map[string]string
+	modules     map[string]*fakeModule
+	sessions    map[string]*fakeSession
+	uploads     []Upload
+	logins      int
+	mu          sync.Mutex
+	maintenance bool
+}
+
+type fakeModule struct {
+	versions types.Versions
+	owner    string
+}
+
+type fakeSession struct {
+	login  string
+	sessid string
+}
+
+// NewPortal starts a fake portal. The caller must call Close when done.
+func NewPortal() *Portal {
+	p := &Portal{
+		accounts: make(map[string]string),
+		modules:  make(map[string]*fakeModule),
+		sessions: make(map[string]*fakeSession),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/personal/", p.handleLogin)
+	mux.HandleFunc("/personal/modules/edit.php", p.handleEdit)
+	mux.HandleFunc("/personal/modules/update.php", p.handleUpdate)
+	mux.HandleFunc("/personal/modules/deploy.php", p.handleDeploy)
+
+	p.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p.mu.Lock()
+		maintenance := p.maintenance
+		p.mu.Unlock()
+
+		if maintenance {
+			w.Header().Set("Content-Type", "text/html; charset=UTF-8")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_ = maintenanceTemplate.Execute(w, page{Title: "Сайт временно недоступен"})
+			return
+		}
+
+		mux.ServeHTTP(w, r)
+	}))
+
+	return p
+}
+
+// URL returns the base URL of the portal, to be used as the `portal` field of a module.
+func (p *Portal) URL() string {
+	return p.server.URL
+}
+
+// Close shuts the portal down.
+func (p *Portal) Close() {
+	p.server.Close()
+}
+
+// AddAccount registers an account that can log in with the password.
+func (p *Portal) AddAccount(login, password string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.accounts[login] = password
+}
+
+// AddModule registers a module owned by the account, with the given versions and labels.
+func (p *Portal) AddModule(account, name string, versions types.Versions) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.modules[name] = &fakeModule{owner: account, versions: maps.Clone(versions)}
+	if p.modules[name].versions == nil {
+		p.modules[name].versions = make(types.Versions)
+	}
+}
+
+// Versions returns a copy of the versions of the module, or nil if it is not registered.
+func (p *Portal) Versions(name string) types.Versions {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	m, ok := p.modules[name]
+	if !ok {
+		return nil
+	}
+
+	return maps.Clone(m.versions)
+}
+
+// Uploads returns the release archives received so far.
+func (p *Portal) Uploads() []Upload {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return slices.Clone(p.uploads)
+}
+
+// Logins returns the number of successful logins.
+func (p *Portal) Logins() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.logins
+}
+
+// ExpireSessions logs all sessions out, as the portal does when a session times out.
+func (p *Portal) ExpireSessions() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	clear(p.sessions)
+}
+
+// SetMaintenance closes the portal for maintenance: every page responds
+// with 503 Service Unavailable and the maintenance notice.
+func (p *Portal) SetMaintenance(on bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.maintenance = on
+}
+
+func (p *Portal) handleLogin(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/personal/" {
+		http.NotFound(w, r)
+		return
+	}
+
+	if r.Method != http.MethodPost || r.FormValue("AUTH_FORM") != "Y" {
+		if s := p.session(r); s != nil {
+			render(w, pageTemplate, page{Title: "Персональный раздел", Login: s.login})
+			return
+		}
+		render(w, loginTemplate, page{Title: "Авторизация"})
+		return
+	}
+
+	login := r.FormValue("USER_LOGIN")
+	password := r.FormValue("USER_PASSWORD")
+
+	p.mu.Lock()
+	expected, ok := p.accounts[login]
+	if !ok || expected != password {
+		p.mu.Unlock()
+		render(w, loginTemplate, page{Title: "Авторизация", Error: "Неверный логин или пароль."})
+		return
+	}
+
+	id := token()
+	p.sessions[id] = &fakeSession{login: login, sessid: token()}
+	p.logins++
+	p.mu.Unlock()
+
+	http.SetCookie(w, &http.Cookie{Name: sessionCookie, Value: id, Path: "/", HttpOnly: true})
+	http.SetCookie(w, &http.Cookie{Name: loginCookie, Value: login, Path: "/"})
+
+	render(w, pageTemplate, page{Title: "Персональный раздел", Login: login})
+}
+
+func (p *Portal) handleEdit(w http.ResponseWriter, r *http.Request) {
+	s, m, name, ok := p.authorize(w, r, r.URL.Query().Get("ID"))
+	if !ok {
+		return
+	}
+
+	render(w, editTemplate, page{Title: "Редактирование модуля", Login: s.login, Sessid: s.sessid, Module: name,
+		Versions: sortedVersions(m)})
+}
+
+func (p *Portal) handleUpdate(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("ID")
+	if r.Method == http.MethodPost {
+		name = r.FormValue("ID")
+	}
+
+	s, m, name, ok := p.authorize(w, r, name)
+	if !ok {
+		return
+	}
+
+	data := page{Title: "Обновления модуля", Login: s.login, Sessid: s.sessid, Module: name}
+
+	if r.Method == http.MethodPost {
+		data.Error = p.changeLabels(r, s, m)
+	}
+
+	data.Versions = sortedVersions(m)
+
+	render(w, updateTemplate, data)
+}
+
+func (p *Portal) changeLabels(r *http.Request, s *fakeSession, m *fakeModule) string {
+	if r.FormValue("sessid") != s.sessid {
+		return "Ваша сессия истекла. Повторите попытку."
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for key, values := range r.PostForm {
+		if _, ok := m.versions[key]; !ok || len(values) == 0 {
+			continue
+		}
+
+		switch label := types.VersionLabel(values[0]); label {
+		case types.Alpha, types.Beta, types.Stable:
+			m.versions[key] = label
+		default:
+			return fmt.Sprintf("Неверная метка версии %s.", key)
+		}
+	}
+
+	return ""
+}
+
+func (p *Portal) handleDeploy(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxUploadSize)
+	if err := r.ParseMultipartForm(maxUploadSize); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s, m, name, ok := p.authorize(w, r, r.FormValue("ID"))
+	if !ok {
+		return
+	}
+
+	data := page{Title: "Загрузка обновления", Login: s.login, Sessid: s.sessid, Module: name}
+	data.Error = p.deploy(r, s, m, name)
+
+	render(w, deployTemplate, data)
+}
+
+func (p *Portal) deploy(r *http.Request, s *fakeSession, m *fakeModule, name string) string {
+	if r.FormValue("sessid") != s.sessid {
+		return "Ваша сессия истекла. Повторите попытку."
+	}
+
+	file, header, err := r.FormFile("update")
+	if err != nil {
+		return "Не выбран файл обновления."
+	}
+	defer helpers.Cleanup(file, nil)
+
+	content, err := io.ReadAll(file)
+	if err != nil {
+		return "Ошибка загрузки файла."
+	}
+
+	version, found := strings.CutSuffix(header.Filename, ".zip")
+	if !found || version == "" {
+		return "Файл обновления должен быть zip-архивом."
+	}
+
+	if _, err := zip.NewReader(bytes.NewReader(content), int64(len(content))); err != nil {
+		return "Архив обновления повреждён."
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, exists := m.versions[version]; exists {
+		return fmt.Sprintf("Версия %s уже загружена.", version)
+	}
+
+	m.versions[version] = types.Alpha
+	p.uploads = append(p.uploads, Upload{Module: name, Version: version, FileName: header.Filename, Data: content})
+
+	return ""
+}
+
+// authorize resolves the session and the module. If the session is missing, the login form
+// is rendered, as the portal does; if the module does not belong to the account,
+// the access denied page is.
+func (p *Portal) authorize(w http.ResponseWriter, r *http.Request, name string) (*fakeSession, *fakeModule, string, bool) {
+	s := p.session(r)
+	if s == nil {
+		render(w, loginTemplate, page{Title: "Авторизация"})
+		return nil, nil, "", false
+	}
+
+	p.mu.Lock()
+	m, ok := p.modules[name]
+	p.mu.Unlock()
+
+	if !ok || m.owner != s.login {
+		w.Header().Set("Content-Type", "text/html; charset=UTF-8")
+		w.WriteHeader(http.StatusForbidden)
+		_ = deniedTemplate.Execute(w, page{Title: "Доступ запрещён", Login: s.login})
+		return nil, nil, "", false
+	}
+
+	return s, m, name, true
+}
+
+func (p *Portal) session(r *http.Request) *fakeSession {
+	c, err := r.Cookie(sessionCookie)
+	if err != nil {
+		return nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.sessions[c.Value]
+}
+
+func sortedVersions(m *fakeModule) []versionRow {
+	keys := helpers.SortSemanticVersions(maps.Keys(m.versions))
+	slices.Reverse(keys)
+
+	rows := make([]versionRow, 0, len(keys))
+	for _, k := range keys {
+		rows = append(rows, versionRow{Version: k, Label: string(m.versions[k])})
+	}
+
+	return rows
+}
+
+func token() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+
+	return hex.EncodeToString(b)
+}
+
+func render(w http.ResponseWriter, t *template.Template, data page) {
+	w.Header().Set("Content-Type", "text/html; charset=UTF-8")
+	_ = t.Execute(w, data)
+}
+
+type page struct {
+	Title    string
+	Login    string
+	Sessid   string
+	Module   string
+	Error    string
+	Versions []versionRow
+}
+
+type versionRow struct {
+	Version string
+	Label   string
+}
@@ -0,0 +1,189 @@
+package portaltest_test
+
+import (
+	"archive/zip"
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pixel365/bx/internal/client"
+	"github.com/pixel365/bx/internal/errors"
+	"github.com/pixel365/bx/internal/module"
+	"github.com/pixel365/bx/internal/portaltest"
+	"github.com/pixel365/bx/internal/request"
+	"github.com/pixel365/bx/internal/types"
+)
+
+func newPortal(t *testing.T) (*portaltest.Portal, *module.Module) {
+	t.Helper()
+
+	portal := portaltest.NewPortal()
+	t.Cleanup(portal.Close)
+
+	portal.AddAccount("partner", "secret")
+	portal.AddModule("partner", "vendor.module", types.Versions{"1.0.0": types.Stable, "1.1.0": types.Beta})
+	portal.AddModule("other", "other.module", nil)
+
+	mod := &module.Module{
+		Name:           "vendor.module",
+		Account:        "partner",
+		Version:        "1.2.0",
+		Portal:         portal.URL(),
+		BuildDirectory: t.TempDir(),
+	}
+
+	return portal, mod
+}
+
+func writeZip(t *testing.T, mod *module.Module) {
+	t.Helper()
+
+	f, err := os.Create(filepath.Join(mod.BuildDirectory, mod.Version+".zip"))
+	require.NoError(t, err)
+
+	w := zip.NewWriter(f)
+	entry, err := w.Create(mod.Version + "/install/index.php")
+	require.NoError(t, err)
+	_, err = entry.Write([]byte("<?php"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	require.NoError(t, f.Close())
+}
+
+func login(t *testing.T, mod *module.Module) (client.HTTPClient, []*http.Cookie) {
+	t.Helper()
+
+	c := client.NewClient(5 * time.Second)
+	cookies, err := request.Authenticate(c, mod, "secret")
+	require.NoError(t, err)
+
+	return c, cookies
+}
+
+func TestPortal_Authenticate(t *testing.T) {
+	t.Parallel()
+
+	portal, mod := newPortal(t)
+
+	_, err := request.Authenticate(client.NewClient(5*time.Second), mod, "wrong")
+	require.ErrorIs(t, err, errors.ErrAuthentication)
+	assert.Equal(t, 0, portal.Logins())
+
+	_, cookies := login(t, mod)
+	assert.Equal(t, 1, portal.Logins())
+
+	names := make([]string, 0, len(cookies))
+	for _, c := range cookies {
+		names = append(names, c.Name)
+	}
+	assert.ElementsMatch(t, []string{"PHPSESSID", "BITRIX_SM_LOGIN"}, names)
+}
+
+func TestPortal_ValidateSession(t *testing.T) {
+	t.Parallel()
+
+	portal, mod := newPortal(t)
+	c, cookies := login(t, mod)
+
+	require.NoError(t, request.ValidateSession(c, mod, cookies))
+
+	other := &module.Module{Name: "other.module", Account: mod.Account, Portal: mod.Portal}
+	err := request.ValidateSession(c, other, cookies)
+	require.ErrorIs(t, err, errors.ErrEmptySession)
+	require.ErrorIs(t, err, errors.ErrPortalAccessDenied)
+
+	portal.ExpireSessions()
+	err = request.ValidateSession(c, mod, cookies)
+	require.ErrorIs(t, err, errors.ErrEmptySession)
+	require.ErrorIs(t, err, errors.ErrPortalSessionExpired)
+}
+
+func TestPortal_maintenance(t *testing.T) {
+	t.Parallel()
+
+	portal, mod := newPortal(t)
+	c, cookies := login(t, mod)
+
+	portal.SetMaintenance(true)
+
+	_, err := request.Versions(context.Background(), c, mod, cookies)
+	require.ErrorIs(t, err, errors.ErrPortalMaintenance)
+
+	var portalErr *errors.PortalError
+	require.ErrorAs(t, err, &portalErr)
+	assert.Equal(t, http.StatusServiceUnavailable, portalErr.Status)
+	assert.Contains(t, portalErr.Excerpt, "технические работы")
+
+	_, err = request.Authenticate(c, mod, "secret")
+	require.ErrorIs(t, err, errors.ErrPortalMaintenance)
+
+	portal.SetMaintenance(false)
+
+	_, err = request.Versions(context.Background(), c, mod, cookies)
+	require.NoError(t, err)
+}
+
+func TestPortal_Versions(t *testing.T) {
+	t.Parallel()
+
+	_, mod := newPortal(t)
+	c, cookies := login(t, mod)
+
+	versions, err := request.Versions(context.Background(), c, mod, cookies)
+	require.NoError(t, err)
+	assert.Equal(t, types.Versions{"1.0.0": types.Stable, "1.1.0": types.Beta}, versions)
+}
+
+func TestPortal_ChangeLabels(t *testing.T) {
+	t.Parallel()
+
+	portal, mod := newPortal(t)
+	c, cookies := login(t, mod)
+
+	err := request.ChangeLabels(c, mod, cookies, types.Versions{"1.1.0": types.Stable, "9.9.9": types.Alpha})
+	require.NoError(t, err)
+	assert.Equal(t, types.Versions{"1.0.0": types.Stable, "1.1.0": types.Stable}, portal.Versions(mod.Name))
+
+	err = request.ChangeLabels(c, mod, cookies, types.Versions{"1.0.0": "gamma"})
+	require.ErrorIs(t, err, errors.ErrPortalValidation)
+	assert.Contains(t, err.Error(), "1.0.0")
+}
+
+func TestPortal_UploadZIP(t *testing.T) {
+	t.Parallel()
+
+	portal, mod := newPortal(t)
+	writeZip(t, mod)
+	c, cookies := login(t, mod)
+
+	require.NoError(t, request.UploadZIP(context.Background(), c, mod, cookies, nil))
+
+	uploads := portal.Uploads()
+	require.Len(t, uploads, 1)
+	assert.Equal(t, "vendor.module", uploads[0].Module)
+	assert.Equal(t, "1.2.0", uploads[0].Version)
+	assert.Equal(t, "1.2.0.zip", uploads[0].FileName)
+	assert.Equal(t, types.Alpha, portal.Versions(mod.Name)["1.2.0"])
+
+	err := request.UploadZIP(context.Background(), c, mod, cookies, nil)
+	require.ErrorIs(t, err, errors.ErrPortalValidation)
+	assert.Contains(t, err.Error(), "1.2.0")
+	assert.Len(t, portal.Uploads(), 1)
+}
+
+func TestPortal_UploadZIP_corrupted(t *testing.T) {
+	t.Parallel()
+
+	portal, mod := newPortal(t)
+	require.NoError(t, os.WriteFile(filepath.Join(mod.BuildDirectory, mod.Version+".zip"), []byte("not a zip"), 0600))
+	c, cookies := login(t, mod)
+
+	require.Error(t, request.UploadZIP(context.Background(), c, mod, cookies, nil))
+	assert.Empty(t, portal.Uploads())
+}
@@ -0,0 +1,97 @@
+package portaltest
+
+import "html/template"
+
+// The templates reproduce the parts of the portal markup the request parsers rely on:
+// the sessid hidden input, the `data-table mt-3 mb-3` versions table with one radio group
+// per version, the `paragraph-15 color-red m-0` error paragraph, and the access denied
+// and maintenance pages.
+const layout = `<!DOCTYPE html>
+<html lang="ru">
+<head>
+<meta charset="UTF-8">
+<title>{{.Title}} | Партнёрский портал 1С-Битрикс</title>
+</head>
+<body>
+<header class="header">
+{{if .Login}}<div class="header-user">{{.Login}} <a href="/personal/?logout=yes">Выйти</a></div>{{end}}
+</header>
+<main class="container">
+<h1 class="title-h1">{{.Title}}</h1>
+{{if .Error}}<p class="paragraph-15 color-red m-0">{{.Error}}</p>{{end}}
+{{template "content" .}}
+</main>
+</body>
+</html>`
+
+var (
+	pageTemplate = newTemplate(`{{define "content"}}{{end}}`)
+
+	loginTemplate = newTemplate(`{{define "content"}}
+<form name="form_auth" method="post" target="_top" action="/personal/">
+<input type="hidden" name="AUTH_FORM" value="Y">
+<input type="hidden" name="TYPE" value="AUTH">
+<input type="text" name="USER_LOGIN" maxlength="255" value="">
+<input type="password" name="USER_PASSWORD" maxlength="255" autocomplete="off">
+<input type="checkbox" id="USER_REMEMBER" name="USER_REMEMBER" value="Y">
+<input type="submit" name="Login" value="Войти">
+</form>
+{{end}}`)
+
+	editTemplate = newTemplate(`{{define "content"}}
+<form method="post" action="/personal/modules/edit.php?ID={{.Module}}" enctype="multipart/form-data">
+<input type="hidden" name="sessid" id="sessid" value="{{.Sessid}}" />
+<input type="hidden" name="ID" value="{{.Module}}">
+<div class="form-group"><label>Код модуля</label> <b>{{.Module}}</b></div>
+<div class="form-group"><label>Версий</label> <b>{{len .Versions}}</b></div>
+<a href="/personal/modules/update.php?ID={{.Module}}">Обновления</a>
+</form>
+{{end}}`)
+
+	updateTemplate = newTemplate(`{{define "content"}}
+<form method="post" action="/personal/modules/update.php">
+<input type="hidden" name="sessid" id="sessid" value="{{.Sessid}}" />
+<input type="hidden" name="ID" value="{{.Module}}">
+<table class="data-table mt-3 mb-3">
+<thead><tr><th>Версия</th><th>Метка</th></tr></thead>
+<tbody>
+{{range .Versions}}<tr>
+<td>{{.Version}}</td>
+<td><input type="radio" name="{{.Version}}" value="alpha"{{if eq .Label "alpha"}} checked{{end}}> alpha <input type="radio" name="{{.Version}}" value="beta"{{if eq .Label "beta"}} checked{{end}}> beta <input type="radio" name="{{.Version}}" value="stable"{{if eq .Label "stable"}} checked{{end}}> stable</td>
+</tr>
+{{end}}</tbody>
+</table>
+<input type="submit" name="submit" value="Сохранить">
+</form>
+{{end}}`)
+
+	deniedTemplate = newTemplate(`{{define "content"}}
+<p class="paragraph-15 m-0">У вас недостаточно прав для просмотра этой страницы.</p>
+{{end}}`)
+
+	maintenanceTemplate = template.Must(template.New("maintenance").Parse(`<!DOCTYPE html>
+<html lang="ru">
+<head>
+<meta charset="UTF-8">
+<title>{{.Title}}</title>
+</head>
+<body>
+<h1>На сайте ведутся технические работы</h1>
+<p>Пожалуйста, зайдите позже.</p>
+</body>
+</html>`))
+
+	deployTemplate = newTemplate(`{{define "content"}}
+<form method="post" action="/personal/modules/deploy.php" enctype="multipart/form-data">
+<input type="hidden" name="sessid" id="sessid" value="{{.Sessid}}" />
+<input type="hidden" name="ID" value="{{.Module}}">
+<input type="file" name="update">
+<input type="submit" name="submit" value="Загрузить">
+</form>
+{{if not .Error}}<p class="paragraph-15 color-green m-0">Обновление загружено.</p>{{end}}
+{{end}}`)
+)
+
+func newTemplate(content string) *template.Template {
+	return template.Must(template.Must(template.New("layout").Parse(layout)).Parse(content))
+}
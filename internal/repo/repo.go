@@ -3,6 +3,7 @@ package repo
 import (
 	"errors"
 	"fmt"
+	"path/filepath"
 	"regexp"
 	"slices"
 	"strings"
@@ -37,7 +38,11 @@ type CommitFilterFunc func(string, types.TypeValue[types.ChangelogConditionType,
 //   - An error if the repository cannot be opened.
 //
 // Behavior:
-//   - Uses `git.PlainOpen` to open the repository.
+//   - Returns an error for an empty path.
+//   - Looks for the repository at the path and then in its parent directories,
+//     so the path may point to any directory inside the working tree.
+//   - Supports `.git` files, as used by linked worktrees and submodules,
+//     including the common directory shared by linked worktrees.
 //   - Wraps the error with additional context if opening fails.
 //
 // Example:
@@ -52,7 +57,14 @@ type CommitFilterFunc func(string, types.TypeValue[types.ChangelogConditionType,
 //   - The function does not initialize a new repository; it only opens an existing one.
 //   - Ensure that the provided path is a valid Git repository.
 func OpenRepository(repository string) (*git.Repository, error) {
-	r, err := git.PlainOpen(repository)
+	if repository == "" {
+		return nil, fmt.Errorf("repository path: %w", git.ErrRepositoryNotExists)
+	}
+
+	r, err := git.PlainOpenWithOptions(repository, &git.PlainOpenOptions{
+		DetectDotGit:          true,
+		EnableDotGitCommonDir: true,
+	})
 	if err != nil {
 		return nil, fmt.Errorf("repository [%s]: %w", repository, err)
 	}
@@ -60,31 +72,60 @@ func OpenRepository(repository string) (*git.Repository, error) {
 	return r, nil
 }
 
+// Root returns the absolute path of the working tree root of the repository that contains
+// the given path. The repository is discovered as in `OpenRepository`.
+func Root(repository string) (string, error) {
+	r, err := openRepositoryFunc(repository)
+	if err != nil {
+		return "", err
+	}
+
+	return worktreeRoot(r, repository)
+}
+
+// HeadCommit returns the hash of the commit HEAD of the repository points to.
+// The repository is discovered as in `OpenRepository`.
+func HeadCommit(repository string) (string, error) {
+	r, err := openRepositoryFunc(repository)
+	if err != nil {
+		return "", err
+	}
+
+	head, err := r.Head()
+	if err != nil {
+		return "", fmt.Errorf("repository [%s]: %w", repository, err)
+	}
+
+	return head.Hash().String(), nil
+}
+
 // ChangelogList generates a list of commits between two specified points in a Git repository,
 // applying given changelog rules.
 //
 // Parameters:
 //   - repository: The file system path to the Git repository.
 //   - rules: A `Changelog` struct defining the range of commits and sorting rules.
+//   - scope: Repository-relative paths; if not empty, only commits touching files under
+//     these paths are included.
 //
 // Returns:
-//   - A slice of commit hashes as strings.
+//   - A slice of commits with their metadata and transformed messages.
 //   - An error if the repository cannot be opened or commit retrieval fails.
 //
 // Behavior:
 //   - If `rules.From` or `rules.To` are not properly set, it returns an empty list with no error.
 //   - Opens the specified Git repository using `OpenRepository`.
 //   - Retrieves commits within the specified range using `listOfCommits`.
-//   - Sorts commits if `rules.Sort` is set to `Asc` (ascending) or `Desc` (descending).
+//   - Sorts commits according to `rules.Sort` (see `Changelog.SortCommits`).
 //
 // Example:
 //
 //	rules := Changelog{
 //	    From: Tag{"v1.0.0"},
 //	    To:   Tag{"v2.0.0"},
-//	    Sort: Desc,
+//	    Sort: DateDesc,
 //	}
-//	commits, err := ChangelogList("/path/to/repo", rules)
+//	commits, err := ChangelogList("/path/to/repo", rules, nil)
 //	if err != nil {
 //	    log.Fatalf("Failed to generate changelog: %v", err)
 //	}
@@ -94,13 +135,17 @@ func OpenRepository(repository string) (*git.Repository, error) {
 //   - Sorting is applied only if `rules.Sort` is explicitly set.
 //   - Uses `listOfCommits` with a predefined `CommitFilter` function.
 //   - The function does not modify the repository; it only queries commit history.
-func ChangelogList(repository string, rules changelog.Changelog) ([]string, error) {
+func ChangelogList(
+	repository string,
+	rules changelog.Changelog,
+	scope []string,
+) ([]changelog.Commit, error) {
 	if rules.From.Type == "" || rules.To.Type == "" {
-		return []string{}, nil
+		return []changelog.Commit{}, nil
 	}
 
-	if rules.From.Value == "" || rules.To.Value == "" {
-		return []string{}, nil
+	if rules.From.Value == "" || (rules.To.Value == "" && !rules.UsesWorktree()) {
+		return []changelog.Commit{}, nil
 	}
 
 	r, err := openRepositoryFunc(repository)
@@ -108,23 +153,20 @@ func ChangelogList(repository string, rules changelog.Changelog) ([]string, erro
 		return nil, err
 	}
 
+	// rules is a copy, so its patterns are compiled for this changelog
+	rules.Compile()
+
 	commits, err := listOfCommitsFunc(
 		r,
 		rules,
+		scope,
 		CommitFilter,
 	)
 	if err != nil {
 		return nil, err
 	}
 
-	if rules.Sort == types.Asc {
-		slices.Sort(commits)
-	}
-
-	if rules.Sort == types.Desc {
-		slices.Sort(commits)
-		slices.Reverse(commits)
-	}
+	rules.SortCommits(commits)
 
 	return commits, nil
 }
@@ -191,28 +233,34 @@ func CommitFilter(
 	return matched
 }
 
-// listOfCommits retrieves a list of commit messages from a Git repository
+// listOfCommits retrieves a list of commits from a Git repository
 // within a specified range, applying a filtering function.
 //
 // Parameters:
 //   - repository: A pointer to a `git.Repository` instance.
 //   - changelog: A `Changelog` struct defining the commit range and filtering rules.
+//   - scope: Repository-relative paths the commits must touch; empty means no restriction.
 //   - filter: A function that determines whether a commit message should be included.
 //
 // Returns:
-//   - A slice of commit messages that match the filtering criteria.
+//   - A slice of commits that match the filtering criteria, in the order they were read.
 //   - An error if the repository is nil, commit history retrieval fails, or iteration encounters an issue.
 //
 // Behavior:
 //   - Calls `hashes` to determine the start and end commit hashes based on `rules`.
 //   - Retrieves the commit log starting from `endHash`.
-//   - Iterates through commits, applying `filter` to the first line of each commit message.
 //   - Stops iteration when the `startHash` commit is reached.
-//   - If a commit matches the filter condition, its message is added to the result slice.
+//   - Computes the topological position of every visited commit.
+//   - Applies `filter` to the first line of each commit message and keeps the matching ones
+//     with their metadata and transformed message.
+//   - If `scope` is not empty, keeps only commits that change files under the scope paths
+//     compared to their first parent.
+//   - Appends the commits of submodules whose recorded commit changed in the range
+//     (see `submoduleCommits`), ordered after the commits of the repository itself.
 //
 // Example:
 //
-//	commits, err := listOfCommits(repo, rules, CommitFilter)
+//	commits, err := listOfCommits(repo, rules, nil, CommitFilter)
 //	if err != nil {
 //	    log.Fatalf("Failed to list commits: %v", err)
 //	}
@@ -224,35 +272,56 @@ func CommitFilter(
 //   - If an error occurs while iterating, it is wrapped and returned unless it's `ErrObjectNotFound`.
 func listOfCommits(
 	repository *git.Repository,
-	changelog changelog.Changelog,
+	rules changelog.Changelog,
+	scope []string,
 	filter CommitFilterFunc,
-) ([]string, error) {
+) ([]changelog.Commit, error) {
 	if repository == nil {
 		return nil, errors2.ErrNilRepository
 	}
 
-	startHash, endHash, err := hashes(repository, changelog)
+	startHash, endHash, err := hashes(repository, rules)
 	if err != nil {
 		return nil, fmt.Errorf("repository [%s]: %w", repository, err)
 	}
 
+	visited, err := rangeCommits(repository, startHash, endHash)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := filterCommits(visited, 0, rules, scope, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	subs, err := submoduleCommits(repository, startHash, endHash, len(visited), rules, scope, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(result, subs...), nil
+}
+
+// rangeCommits returns the commits reachable from endHash, stopping at startHash,
+// in the order they are read from the history. If startHash is zero, the whole history is returned.
+func rangeCommits(
+	repository *git.Repository,
+	startHash, endHash plumbing.Hash,
+) ([]*object.Commit, error) {
 	iter, err := repository.Log(&git.LogOptions{From: endHash})
 	if err != nil {
 		return nil, fmt.Errorf("failed to retrieve commit history: %w", err)
 	}
 	defer iter.Close()
 
-	var result []string
+	var visited []*object.Commit
 	err = iter.ForEach(func(c *object.Commit) error {
 		if c.Hash == startHash {
 			return plumbing.ErrObjectNotFound
 		}
 
-		m := strings.Split(c.Message, "\n")
-		if filter(m[0], changelog.Condition) {
-			transformed := changelog.ApplyTransformation(m[0])
-			result = append(result, transformed)
-		}
+		visited = append(visited, c)
 		return nil
 	})
 
@@ -260,9 +329,190 @@ func listOfCommits(
 		return nil, fmt.Errorf("failed to iterate commit history: %w", err)
 	}
 
+	return visited, nil
+}
+
+// filterCommits converts the visited commits into changelog entries, keeping the ones that
+// pass the filter and touch the scope. The topological position of each commit is
+// shifted by offset.
+func filterCommits(
+	visited []*object.Commit,
+	offset int,
+	rules changelog.Changelog,
+	scope []string,
+	filter CommitFilterFunc,
+) ([]changelog.Commit, error) {
+	order := topologicalOrder(visited)
+
+	var result []changelog.Commit
+	for _, c := range visited {
+		entry := newCommit(c, offset+order[c.Hash])
+		if !filter(entry.Subject, rules.Condition) {
+			continue
+		}
+
+		if len(scope) > 0 {
+			ok, err := touchesScope(c, scope)
+			if err != nil {
+				return nil, fmt.Errorf("commit [%s]: %w", entry.Hash, err)
+			}
+
+			if !ok {
+				continue
+			}
+		}
+
+		entry.Message = rules.ApplyTransformation(entry.Subject)
+		result = append(result, entry)
+	}
+
 	return result, nil
 }
 
+// touchesScope reports whether the commit changes at least one file under the scope paths.
+// The commit is compared to its first parent; a root commit is compared to an empty tree.
+func touchesScope(c *object.Commit, scope []string) (bool, error) {
+	tree, err := c.Tree()
+	if err != nil {
+		return false, err
+	}
+
+	var parentTree *object.Tree
+	if c.NumParents() > 0 {
+		parent, err := c.Parent(0)
+		if err != nil {
+			return false, err
+		}
+
+		parentTree, err = parent.Tree()
+		if err != nil {
+			return false, err
+		}
+	}
+
+	changes, err := object.DiffTree(parentTree, tree)
+	if err != nil {
+		return false, err
+	}
+
+	for _, change := range changes {
+		if InScope(change.From.Name, scope) || InScope(change.To.Name, scope) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// InScope reports whether the repository-relative path lies under one of the scope paths.
+//
+// Parameters:
+//   - path: Repository-relative path using forward slashes.
+//   - scope: Repository-relative files or directories.
+//
+// Returns:
+//   - true if scope is empty, or path equals one of the scope paths or lies inside it.
+//   - false otherwise, including an empty path.
+func InScope(path string, scope []string) bool {
+	if len(scope) == 0 {
+		return true
+	}
+
+	if path == "" {
+		return false
+	}
+
+	for _, root := range scope {
+		root = strings.Trim(filepath.ToSlash(filepath.Clean(root)), "/")
+		if root == "." || root == "" || path == root || strings.HasPrefix(path, root+"/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// newCommit converts a go-git commit object into a changelog entry.
+// The Message field is left empty and is filled in after transformation.
+func newCommit(c *object.Commit, order int) changelog.Commit {
+	subject, body, _ := strings.Cut(c.Message, "\n")
+
+	parents := make([]string, 0, len(c.ParentHashes))
+	for _, p := range c.ParentHashes {
+		parents = append(parents, p.String())
+	}
+
+	return changelog.Commit{
+		When:    c.Committer.When,
+		Hash:    c.Hash.String(),
+		Author:  c.Author.Name,
+		Subject: subject,
+		Body:    strings.TrimSpace(body),
+		Parents: parents,
+		Order:   order,
+	}
+}
+
+// topologicalOrder assigns every commit a position so that children always come before
+// their parents. Commits are processed in the order they were read, which keeps
+// unrelated branches in history order.
+//
+// Parameters:
+//   - commits: Commits in the order they were read from the repository.
+//
+// Returns:
+//   - A map from commit hash to its topological position.
+func topologicalOrder(commits []*object.Commit) map[plumbing.Hash]int {
+	children := make(map[plumbing.Hash]int, len(commits))
+	for _, c := range commits {
+		if _, ok := children[c.Hash]; !ok {
+			children[c.Hash] = 0
+		}
+	}
+
+	for _, c := range commits {
+		for _, p := range c.ParentHashes {
+			if _, ok := children[p]; ok {
+				children[p]++
+			}
+		}
+	}
+
+	byHash := make(map[plumbing.Hash]*object.Commit, len(commits))
+	queue := make([]*object.Commit, 0, len(commits))
+	for _, c := range commits {
+		byHash[c.Hash] = c
+		if children[c.Hash] == 0 {
+			queue = append(queue, c)
+		}
+	}
+
+	order := make(map[plumbing.Hash]int, len(commits))
+	for len(queue) > 0 {
+		c := queue[0]
+		queue = queue[1:]
+
+		if _, done := order[c.Hash]; done {
+			continue
+		}
+		order[c.Hash] = len(order)
+
+		for _, p := range c.ParentHashes {
+			parent, ok := byHash[p]
+			if !ok {
+				continue
+			}
+
+			children[p]--
+			if children[p] == 0 {
+				queue = append(queue, parent)
+			}
+		}
+	}
+
+	return order
+}
+
 // hashes resolves the start and end commit hashes based on the given changelog rules.
 //
 // Parameters:
@@ -278,7 +528,7 @@ func listOfCommits(
 //   - If the repository is nil, returns `plumbing.ZeroHash` for both values and an error.
 //   - If `rules.From.Type` is `Commit`, directly converts `rules.From.Value` into a hash.
 //   - Otherwise, attempts to resolve `rules.From.Value` as a branch, tag, or other reference.
-//   - Performs the same logic for `rules.To`.
+//   - Performs the same logic for `rules.To`; if its type is `Worktree`, `HEAD` is resolved.
 //   - If resolving a commit hash fails, an error is returned.
 //
 // Example:
@@ -313,9 +563,16 @@ func hashes(
 		startHash = *hash
 	}
 
-	if rules.To.Type == types.Commit {
+	switch rules.To.Type {
+	case types.Commit:
 		endHash = plumbing.NewHash(rules.To.Value)
-	} else {
+	case types.Worktree:
+		head, err := repository.Head()
+		if err != nil {
+			return startHash, endHash, fmt.Errorf("failed to resolve HEAD: %w", err)
+		}
+		endHash = head.Hash()
+	default:
 		hash, err := repository.ResolveRevision(plumbing.Revision(rules.To.Value))
 		if err != nil {
 			return startHash, endHash, fmt.Errorf("failed to resolve commit hash [%s]: %w", rules.To.Value, err)
@@ -331,21 +588,27 @@ func hashes(
 // Parameters:
 //   - repository: The file system path to the Git repository.
 //   - rules: A `Changelog` struct defining the commit range for comparison.
+//   - scope: Repository-relative paths; if not empty, files outside them are left out.
 //
 // Returns:
-//   - A pointer to a `Changes` struct containing lists of added, modified, and deleted files.
+//   - A pointer to a `Changes` struct containing lists of added, modified, deleted and moved files,
+//     relative to the repository root, the old paths of renamed files and the absolute root.
 //   - An error if the repository cannot be opened, commit hashes cannot be resolved, or patch generation fails.
 //
 // Behavior:
 //   - Opens the Git repository using `OpenRepository`.
 //   - Resolves the start and end commit hashes using `hashes`.
 //   - Retrieves the commit objects corresponding to these hashes.
-//   - Generates a diff (`Patch`) between the two commits.
-//   - Iterates through the `FilePatches()` to categorize files as added, modified, or deleted.
+//   - Diffs the trees of the two commits with rename detection and categorizes files
+//     as added, modified, deleted or moved (see `collectChanges`).
+//   - Descends into submodules whose recorded commit changed, diffing the submodule between
+//     the recorded commits; their files are listed with the submodule path as prefix.
+//   - If `rules.To.Type` is `Worktree`, the diff ends at `HEAD` and the staged, unstaged
+//     and untracked changes of the working tree are applied on top of it (see `applyWorktree`).
 //
 // Example:
 //
-//	changes, err := ChangesList("/path/to/repo", rules)
+//	changes, err := ChangesList("/path/to/repo", rules, nil)
 //	if err != nil {
 //	    log.Fatalf("Failed to generate changes list: %v", err)
 //	}
@@ -354,11 +617,13 @@ func hashes(
 //	fmt.Println("Deleted files:", changes.Deleted)
 //
 // Notes:
-//   - If `from == nil`, the file was newly added.
-//   - If `to == nil`, the file was deleted.
-//   - Otherwise, the file was modified.
+//   - Submodules must be initialized; otherwise an error is returned.
 //   - The function does not modify the repository; it only analyzes commit differences.
-func ChangesList(repository string, rules changelog.Changelog) (*types.Changes, error) {
+func ChangesList(
+	repository string,
+	rules changelog.Changelog,
+	scope []string,
+) (*types.Changes, error) {
 	r, err := openRepositoryFunc(repository)
 	if err != nil {
 		return nil, err
@@ -383,32 +648,134 @@ func ChangesList(repository string, rules changelog.Changelog) (*types.Changes,
 		return nil, fmt.Errorf("repository [%s]: %w", repository, err)
 	}
 
-	patch, err := startCommit.Patch(endCommit)
+	root, err := worktreeRoot(r, repository)
 	if err != nil {
 		return nil, fmt.Errorf("repository [%s]: %w", repository, err)
 	}
 
-	c := types.Changes{}
+	startTree, err := startCommit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("repository [%s]: %w", repository, err)
+	}
 
-	for _, filePatch := range patch.FilePatches() {
-		from, to := filePatch.Files()
+	endTree, err := endCommit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("repository [%s]: %w", repository, err)
+	}
+
+	c := types.Changes{Root: root}
+
+	if err := collectChanges(r, startTree, endTree, "", scope, &c); err != nil {
+		return nil, fmt.Errorf("repository [%s]: %w", repository, err)
+	}
+
+	if rules.UsesWorktree() {
+		if err := applyWorktree(r, startCommit, &c, scope); err != nil {
+			return nil, fmt.Errorf("repository [%s]: %w", repository, err)
+		}
+	}
+
+	return &c, nil
+}
+
+// applyWorktree updates the changes between the start commit and `HEAD` with the
+// uncommitted changes of the working tree.
+//
+// Every path reported by the worktree status (staged, unstaged or untracked, but not ignored)
+// is re-classified by comparing the start commit with the working tree:
+//   - missing in the start commit and present on disk: added;
+//   - present in the start commit and missing on disk: deleted;
+//   - present in both: modified, or moved if it was already the target of a committed rename;
+//   - missing in both: dropped from the changes.
+//
+// Paths outside a non-empty scope are skipped.
+func applyWorktree(
+	repository *git.Repository,
+	start *object.Commit,
+	changes *types.Changes,
+	scope []string,
+) error {
+	wt, err := repository.Worktree()
+	if err != nil {
+		return fmt.Errorf("failed to open worktree: %w", err)
+	}
+
+	status, err := wt.Status()
+	if err != nil {
+		return fmt.Errorf("failed to read worktree status: %w", err)
+	}
+
+	tree, err := start.Tree()
+	if err != nil {
+		return err
+	}
 
-		if from == nil && to != nil {
-			c.Added = append(c.Added, to.Path())
+	paths := make([]string, 0, len(status))
+	for path, s := range status {
+		if s.Staging == git.Unmodified && s.Worktree == git.Unmodified {
+			continue
 		}
 
-		if from != nil && to == nil {
-			c.Deleted = append(c.Deleted, from.Path())
+		if len(scope) > 0 && !InScope(path, scope) {
+			continue
 		}
 
-		if from != nil && to != nil {
-			if from.Path() != to.Path() {
-				c.Moved = append(c.Moved, to.Path())
-			} else {
-				c.Modified = append(c.Modified, from.Path())
-			}
+		paths = append(paths, path)
+	}
+	slices.Sort(paths)
+
+	for _, path := range paths {
+		_, err := tree.FindEntry(path)
+		inStart := err == nil
+
+		_, err = wt.Filesystem.Lstat(path)
+		inWorktree := err == nil
+
+		oldPath, moved := removeChange(changes, path)
+
+		switch {
+		case !inStart && inWorktree && moved:
+			changes.Moved = append(changes.Moved, path)
+			changes.Renamed[path] = oldPath
+		case !inStart && inWorktree:
+			changes.Added = append(changes.Added, path)
+		case inStart && !inWorktree:
+			changes.Deleted = append(changes.Deleted, path)
+		case inStart && inWorktree:
+			changes.Modified = append(changes.Modified, path)
 		}
 	}
 
-	return &c, nil
+	return nil
+}
+
+// removeChange removes the path from all lists of the changes. If it was listed as moved,
+// its old path is returned as well.
+func removeChange(changes *types.Changes, path string) (string, bool) {
+	oldPath, moved := changes.Renamed[path]
+	delete(changes.Renamed, path)
+
+	changes.Added = slices.DeleteFunc(changes.Added, func(p string) bool { return p == path })
+	changes.Modified = slices.DeleteFunc(changes.Modified, func(p string) bool { return p == path })
+	changes.Deleted = slices.DeleteFunc(changes.Deleted, func(p string) bool { return p == path })
+	changes.Moved = slices.DeleteFunc(changes.Moved, func(p string) bool { return p == path })
+
+	return oldPath, moved
+}
+
+// worktreeRoot returns the absolute path of the repository working tree.
+// If the repository has no file system root (e.g. in-memory), the repository path is used.
+func worktreeRoot(r *git.Repository, repository string) (string, error) {
+	root := repository
+
+	if wt, err := r.Worktree(); err == nil && wt.Filesystem.Root() != "" {
+		root = wt.Filesystem.Root()
+	}
+
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Clean(abs), nil
 }
@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -16,7 +18,10 @@ import (
 
 	"github.com/pixel365/bx/internal/types"
 
+	"github.com/go-git/go-billy/v5/memfs"
 	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/memory"
 
 	"github.com/go-git/go-git/v5"
 )
@@ -59,11 +64,11 @@ func TestChangelogList(t *testing.T) {
 	}
 	tests := []struct {
 		name    string
-		want    []string
+		want    []changelog.Commit
 		args    args
 		wantErr bool
 	}{
-		{"empty changelog", []string{}, args{"", changelog.Changelog{}}, false},
+		{"empty changelog", []changelog.Commit{}, args{"", changelog.Changelog{}}, false},
 		{"empty repository", nil, args{"", changelog.Changelog{
 			From: types.TypeValue[types.ChangelogType, string]{
 				Type:  types.Tag,
@@ -76,7 +81,7 @@ func TestChangelogList(t *testing.T) {
 			Sort:      "asc",
 			Condition: types.TypeValue[types.ChangelogConditionType, []string]{},
 		}}, true},
-		{"empty from values", []string{}, args{"", changelog.Changelog{
+		{"empty from values", []changelog.Commit{}, args{"", changelog.Changelog{
 			From: types.TypeValue[types.ChangelogType, string]{
 				Type:  types.Tag,
 				Value: "",
@@ -92,7 +97,7 @@ func TestChangelogList(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := ChangelogList(tt.args.repository, tt.args.rules)
+			got, err := ChangelogList(tt.args.repository, tt.args.rules, nil)
 			if tt.wantErr {
 				require.Error(t, err)
 			} else {
@@ -113,7 +118,7 @@ func TestChangelogList_listOfCommits_Fail(t *testing.T) {
 		openRepositoryFunc = origOpenRepository
 	}()
 
-	listOfCommitsFunc = func(_ *git.Repository, _ changelog.Changelog, _ CommitFilterFunc) ([]string, error) {
+	listOfCommitsFunc = func(_ *git.Repository, _ changelog.Changelog, _ []string, _ CommitFilterFunc) ([]changelog.Commit, error) {
 		return nil, errors.New("fail")
 	}
 
@@ -131,7 +136,7 @@ func TestChangelogList_listOfCommits_Fail(t *testing.T) {
 				Type:  types.Tag,
 				Value: "v2.0.0",
 			},
-		})
+		}, nil)
 		if err == nil {
 			t.Errorf("ChangelogList() error = %v, wantErr %v", err, errors.New("fail"))
 		}
@@ -148,8 +153,8 @@ func TestChangelogList_listOfCommits_Ok_Asc(t *testing.T) {
 		openRepositoryFunc = origOpenRepository
 	}()
 
-	listOfCommitsFunc = func(_ *git.Repository, _ changelog.Changelog, _ CommitFilterFunc) ([]string, error) {
-		return []string{"commit 2", "commit 1"}, nil
+	listOfCommitsFunc = func(_ *git.Repository, _ changelog.Changelog, _ []string, _ CommitFilterFunc) ([]changelog.Commit, error) {
+		return []changelog.Commit{{Message: "commit 2"}, {Message: "commit 1"}}, nil
 	}
 
 	openRepositoryFunc = func(_ string) (*git.Repository, error) {
@@ -166,9 +171,9 @@ func TestChangelogList_listOfCommits_Ok_Asc(t *testing.T) {
 			Value: "v2.0.0",
 		},
 		Sort: types.Asc,
-	})
+	}, nil)
 	require.NoError(t, err)
-	assert.Equal(t, []string{"commit 1", "commit 2"}, commits)
+	assert.Equal(t, []changelog.Commit{{Message: "commit 1"}, {Message: "commit 2"}}, commits)
 }
 
 func TestChangelogList_listOfCommits_Ok_Desc(t *testing.T) {
@@ -181,8 +186,8 @@ func TestChangelogList_listOfCommits_Ok_Desc(t *testing.T) {
 		openRepositoryFunc = origOpenRepository
 	}()
 
-	listOfCommitsFunc = func(_ *git.Repository, _ changelog.Changelog, _ CommitFilterFunc) ([]string, error) {
-		return []string{"commit 1", "commit 2"}, nil
+	listOfCommitsFunc = func(_ *git.Repository, _ changelog.Changelog, _ []string, _ CommitFilterFunc) ([]changelog.Commit, error) {
+		return []changelog.Commit{{Message: "commit 1"}, {Message: "commit 2"}}, nil
 	}
 
 	openRepositoryFunc = func(_ string) (*git.Repository, error) {
@@ -199,9 +204,9 @@ func TestChangelogList_listOfCommits_Ok_Desc(t *testing.T) {
 			Value: "v2.0.0",
 		},
 		Sort: types.Desc,
-	})
+	}, nil)
 	require.NoError(t, err)
-	assert.Equal(t, []string{"commit 2", "commit 1"}, commits)
+	assert.Equal(t, []changelog.Commit{{Message: "commit 2"}, {Message: "commit 1"}}, commits)
 }
 
 func TestCommitFilter(t *testing.T) {
@@ -259,7 +264,7 @@ func Test_listOfCommits(t *testing.T) {
 	}
 	tests := []struct {
 		name    string
-		want    []string
+		want    []changelog.Commit
 		args    args
 		wantErr bool
 	}{
@@ -268,7 +273,7 @@ func Test_listOfCommits(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := listOfCommits(tt.args.repository, tt.args.rules, tt.args.filter)
+			got, err := listOfCommits(tt.args.repository, tt.args.rules, nil, tt.args.filter)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("listOfCommits() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -325,7 +330,7 @@ func TestChangesList(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := ChangesList(tt.args.repository, tt.args.rules)
+			got, err := ChangesList(tt.args.repository, tt.args.rules, nil)
 			if tt.wantErr {
 				require.Error(t, err)
 			} else {
@@ -344,7 +349,7 @@ func TestChangesList_nil_repository(t *testing.T) {
 		return nil, nil
 	}
 
-	_, err := ChangesList("", changelog.Changelog{})
+	_, err := ChangesList("", changelog.Changelog{}, nil)
 	assert.ErrorIs(t, errors2.ErrNilRepository, err)
 }
 
@@ -362,7 +367,7 @@ func TestChangesList_hashes_fail(t *testing.T) {
 		return plumbing.ZeroHash, plumbing.ZeroHash, errors.New("some error")
 	}
 
-	_, err := ChangesList("repo", changelog.Changelog{})
+	_, err := ChangesList("repo", changelog.Changelog{}, nil)
 	e := fmt.Errorf("repository [%s]: %w", "repo", errors.New("some error")).Error()
 	require.Error(t, err)
 	assert.Equal(t, e, err.Error())
@@ -402,3 +407,274 @@ func TestChanges_IsChangedFile(t *testing.T) {
 		})
 	}
 }
+
+type testCommit struct {
+	when    time.Time
+	files   map[string]string
+	message string
+}
+
+// newTestRepository creates an in-memory repository with the given commits
+// in order and tags the first commit as `v1.0.0` and the last one as `v2.0.0`.
+func newTestRepository(t *testing.T, commits []testCommit) *git.Repository {
+	t.Helper()
+
+	r, err := git.Init(memory.NewStorage(), memfs.New())
+	require.NoError(t, err)
+
+	w, err := r.Worktree()
+	require.NoError(t, err)
+
+	var first, last plumbing.Hash
+	for i, c := range commits {
+		for name, content := range c.files {
+			f, err := w.Filesystem.Create(name)
+			require.NoError(t, err)
+			_, err = f.Write([]byte(content))
+			require.NoError(t, err)
+			require.NoError(t, f.Close())
+			_, err = w.Add(name)
+			require.NoError(t, err)
+		}
+
+		sig := &object.Signature{Name: "tester", Email: "tester@example.com", When: c.when}
+		hash, err := w.Commit(c.message, &git.CommitOptions{Author: sig, Committer: sig})
+		require.NoError(t, err)
+
+		if i == 0 {
+			first = hash
+		}
+		last = hash
+	}
+
+	_, err = r.CreateTag("v1.0.0", first, nil)
+	require.NoError(t, err)
+	_, err = r.CreateTag("v2.0.0", last, nil)
+	require.NoError(t, err)
+
+	return r
+}
+
+func Test_listOfCommits_metadata(t *testing.T) {
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	r := newTestRepository(t, []testCommit{
+		{message: "initial", when: base, files: map[string]string{"a.txt": "a"}},
+		{message: "fix: second\n\nbody text", when: base.Add(time.Hour), files: map[string]string{"b.txt": "b"}},
+		{message: "feat: third", when: base.Add(2 * time.Hour), files: map[string]string{"c.txt": "c"}},
+	})
+
+	rules := changelog.Changelog{
+		From: types.TypeValue[types.ChangelogType, string]{Type: types.Tag, Value: "v1.0.0"},
+		To:   types.TypeValue[types.ChangelogType, string]{Type: types.Tag, Value: "v2.0.0"},
+		Transform: &[]types.TypeValue[types.TransformType, []string]{
+			{Type: types.StripPrefix, Value: []string{"feat:", "fix:"}},
+		},
+	}
+
+	commits, err := listOfCommits(r, rules, nil, CommitFilter)
+	require.NoError(t, err)
+	require.Len(t, commits, 2)
+
+	assert.Equal(t, "feat: third", commits[0].Subject)
+	assert.Equal(t, "third", commits[0].Message)
+	assert.Equal(t, 0, commits[0].Order)
+	assert.Equal(t, base.Add(2*time.Hour).Unix(), commits[0].When.Unix())
+	assert.Equal(t, "tester", commits[0].Author)
+
+	assert.Equal(t, "fix: second", commits[1].Subject)
+	assert.Equal(t, "body text", commits[1].Body)
+	assert.Equal(t, 1, commits[1].Order)
+	assert.Equal(t, []string{commits[1].Hash}, commits[0].Parents)
+}
+
+func Test_topologicalOrder(t *testing.T) {
+	a := &object.Commit{Hash: plumbing.NewHash("a1")}
+	b := &object.Commit{Hash: plumbing.NewHash("b1"), ParentHashes: []plumbing.Hash{a.Hash}}
+	c := &object.Commit{Hash: plumbing.NewHash("c1"), ParentHashes: []plumbing.Hash{a.Hash}}
+	merge := &object.Commit{
+		Hash:         plumbing.NewHash("d1"),
+		ParentHashes: []plumbing.Hash{b.Hash, c.Hash},
+	}
+
+	// a parent read before one of its children must still come after it
+	order := topologicalOrder([]*object.Commit{merge, b, a, c})
+
+	assert.Equal(t, 0, order[merge.Hash])
+	assert.Less(t, order[b.Hash], order[a.Hash])
+	assert.Less(t, order[c.Hash], order[a.Hash])
+	assert.Equal(t, 3, order[a.Hash])
+}
+
+func Test_listOfCommits_scope(t *testing.T) {
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	r := newTestRepository(t, []testCommit{
+		{message: "initial", when: base, files: map[string]string{"a/init.txt": "a"}},
+		{message: "touch a", when: base.Add(time.Hour), files: map[string]string{"a/a.txt": "a"}},
+		{message: "touch b", when: base.Add(2 * time.Hour), files: map[string]string{"b/b.txt": "b"}},
+		{message: "touch ab", when: base.Add(3 * time.Hour), files: map[string]string{"ab/ab.txt": "ab"}},
+	})
+
+	rules := changelog.Changelog{
+		From: types.TypeValue[types.ChangelogType, string]{Type: types.Tag, Value: "v1.0.0"},
+		To:   types.TypeValue[types.ChangelogType, string]{Type: types.Tag, Value: "v2.0.0"},
+	}
+
+	commits, err := listOfCommits(r, rules, []string{"a"}, CommitFilter)
+	require.NoError(t, err)
+	require.Len(t, commits, 1)
+	assert.Equal(t, "touch a", commits[0].Message)
+
+	commits, err = listOfCommits(r, rules, []string{"b", "ab/ab.txt"}, CommitFilter)
+	require.NoError(t, err)
+	require.Len(t, commits, 2)
+	assert.Equal(t, "touch ab", commits[0].Message)
+	assert.Equal(t, "touch b", commits[1].Message)
+}
+
+func TestChangesList_scope(t *testing.T) {
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	r := newTestRepository(t, []testCommit{
+		{message: "initial", when: base, files: map[string]string{"a/init.txt": "a"}},
+		{message: "touch a", when: base.Add(time.Hour), files: map[string]string{"a/a.txt": "a"}},
+		{message: "touch b", when: base.Add(2 * time.Hour), files: map[string]string{"b/b.txt": "b"}},
+	})
+
+	origOpenRepositoryFunc := openRepositoryFunc
+	defer func() { openRepositoryFunc = origOpenRepositoryFunc }()
+
+	openRepositoryFunc = func(_ string) (*git.Repository, error) {
+		return r, nil
+	}
+
+	rules := changelog.Changelog{
+		From: types.TypeValue[types.ChangelogType, string]{Type: types.Tag, Value: "v1.0.0"},
+		To:   types.TypeValue[types.ChangelogType, string]{Type: types.Tag, Value: "v2.0.0"},
+	}
+
+	changes, err := ChangesList("repo", rules, nil)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"a/a.txt", "b/b.txt"}, changes.Added)
+
+	changes, err = ChangesList("repo", rules, []string{"a"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a/a.txt"}, changes.Added)
+}
+
+func TestChangesList_worktree(t *testing.T) {
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	r := newTestRepository(t, []testCommit{
+		{message: "initial", when: base, files: map[string]string{"a.txt": "a", "b.txt": "b", "c.txt": "c"}},
+		{message: "second", when: base.Add(time.Hour), files: map[string]string{"d.txt": "d", "e.txt": "e"}},
+	})
+
+	w, err := r.Worktree()
+	require.NoError(t, err)
+
+	writeFile := func(name, content string) {
+		f, err := w.Filesystem.Create(name)
+		require.NoError(t, err)
+		_, err = f.Write([]byte(content))
+		require.NoError(t, err)
+		require.NoError(t, f.Close())
+	}
+
+	writeFile("a.txt", "a2")
+	writeFile("staged.txt", "s")
+	_, err = w.Add("staged.txt")
+	require.NoError(t, err)
+	writeFile("untracked.txt", "u")
+	require.NoError(t, w.Filesystem.Remove("b.txt"))
+	require.NoError(t, w.Filesystem.Remove("e.txt"))
+
+	origOpenRepositoryFunc := openRepositoryFunc
+	defer func() { openRepositoryFunc = origOpenRepositoryFunc }()
+
+	openRepositoryFunc = func(_ string) (*git.Repository, error) {
+		return r, nil
+	}
+
+	rules := changelog.Changelog{
+		From: types.TypeValue[types.ChangelogType, string]{Type: types.Tag, Value: "v1.0.0"},
+		To:   types.TypeValue[types.ChangelogType, string]{Type: types.Worktree},
+	}
+
+	changes, err := ChangesList("repo", rules, nil)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"d.txt", "staged.txt", "untracked.txt"}, changes.Added)
+	assert.Equal(t, []string{"a.txt"}, changes.Modified)
+	assert.Equal(t, []string{"b.txt"}, changes.Deleted)
+	assert.Empty(t, changes.Moved)
+
+	changes, err = ChangesList("repo", rules, []string{"a.txt", "d.txt"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"d.txt"}, changes.Added)
+	assert.Equal(t, []string{"a.txt"}, changes.Modified)
+	assert.Empty(t, changes.Deleted)
+}
+
+func TestChangesList_renamed(t *testing.T) {
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	content := strings.Repeat("some long enough content\n", 10)
+	r := newTestRepository(t, []testCommit{
+		{message: "initial", when: base, files: map[string]string{"lib/old.php": content}},
+		{message: "other", when: base.Add(time.Hour), files: map[string]string{"otherlib/a.php": "a"}},
+	})
+
+	w, err := r.Worktree()
+	require.NoError(t, err)
+
+	_, err = w.Move("lib/old.php", "lib/new.php")
+	require.NoError(t, err)
+
+	sig := &object.Signature{Name: "tester", Email: "tester@example.com", When: base.Add(2 * time.Hour)}
+	hash, err := w.Commit("rename", &git.CommitOptions{Author: sig, Committer: sig})
+	require.NoError(t, err)
+	_, err = r.CreateTag("v3.0.0", hash, nil)
+	require.NoError(t, err)
+
+	origOpenRepositoryFunc := openRepositoryFunc
+	defer func() { openRepositoryFunc = origOpenRepositoryFunc }()
+
+	openRepositoryFunc = func(_ string) (*git.Repository, error) {
+		return r, nil
+	}
+
+	rules := changelog.Changelog{
+		From: types.TypeValue[types.ChangelogType, string]{Type: types.Tag, Value: "v1.0.0"},
+		To:   types.TypeValue[types.ChangelogType, string]{Type: types.Tag, Value: "v3.0.0"},
+	}
+
+	changes, err := ChangesList("repo", rules, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"lib/new.php"}, changes.Moved)
+	assert.Equal(t, map[string]string{"lib/new.php": "lib/old.php"}, changes.Renamed)
+	assert.True(t, filepath.IsAbs(changes.Root))
+
+	assert.True(t, changes.IsChangedFile(filepath.Join(changes.Root, "lib/new.php")))
+	assert.True(t, changes.IsChangedFile(filepath.Join(changes.Root, "otherlib/a.php")))
+	assert.False(t, changes.IsChangedFile(filepath.Join(changes.Root, "lib/old.php")))
+	assert.False(t, changes.IsChangedFile(filepath.Join(changes.Root, "lib/a.php")))
+}
+
+func TestInScope(t *testing.T) {
+	tests := []struct {
+		name  string
+		path  string
+		scope []string
+		want  bool
+	}{
+		{"empty scope", "a/b.txt", nil, true},
+		{"empty path", "", []string{"a"}, false},
+		{"inside dir", "a/b.txt", []string{"a"}, true},
+		{"same file", "a/b.txt", []string{"a/b.txt"}, true},
+		{"sibling prefix", "ab/b.txt", []string{"a"}, false},
+		{"trailing slash", "a/b.txt", []string{"a/"}, true},
+		{"root", "a/b.txt", []string{"."}, true},
+		{"outside", "c/b.txt", []string{"a", "b"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, InScope(tt.path, tt.scope))
+		})
+	}
+}
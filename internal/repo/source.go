@@ -0,0 +1,426 @@
+package repo
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+
+	errors2 "github.com/pixel365/bx/internal/errors"
+)
+
+// TreeSource reads stage sources from the Git tree of a commit instead of the working directory.
+//
+// Stage paths are resolved against the repository root, so they must lie inside the repository.
+// Submodules are read at the commits recorded in the tree; they must be initialized.
+// File modification times are set to the commit time.
+// TreeSource is safe for concurrent use.
+type TreeSource struct {
+	modTime    time.Time
+	submodules map[string]repoTree
+	top        repoTree
+	root       string
+	ref        string
+	commit     string
+	mu         sync.Mutex
+}
+
+// repoTree is the tree of the repository or of a submodule at the commit the source reads.
+//
+// Fields:
+//   - repository: The repository the tree belongs to.
+//   - tree:       The root tree of the commit.
+//   - path:       The slash-separated path of the submodule in the top-level repository;
+//     empty for the top-level repository.
+type repoTree struct {
+	repository *git.Repository
+	tree       *object.Tree
+	path       string
+}
+
+// treeDir is a directory of the source.
+//
+// Fields:
+//   - repo:  The repository the directory belongs to.
+//   - inner: The slash-separated path of the directory in the repository; empty for its root.
+//   - tree:  The tree of the directory.
+type treeDir struct {
+	repo  repoTree
+	tree  *object.Tree
+	inner string
+}
+
+// NewTreeSource opens the repository and returns a source reading from the tree at the ref.
+//
+// Parameters:
+//   - repository: The file system path to the Git repository.
+//   - ref: A tag, a branch or a commit hash (any revision accepted by `git rev-parse`).
+//
+// Returns:
+//   - The tree source.
+//   - An error if the repository cannot be opened or the ref cannot be resolved to a commit.
+func NewTreeSource(repository, ref string) (*TreeSource, error) {
+	r, err := openRepositoryFunc(repository)
+	if err != nil {
+		return nil, err
+	}
+
+	if r == nil {
+		return nil, errors2.ErrNilRepository
+	}
+
+	return newTreeSource(r, repository, ref)
+}
+
+func newTreeSource(r *git.Repository, repository, ref string) (*TreeSource, error) {
+	hash, err := r.ResolveRevision(plumbing.Revision(ref))
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve ref [%s]: %w", ref, err)
+	}
+
+	commit, err := r.CommitObject(*hash)
+	if err != nil {
+		return nil, fmt.Errorf("ref [%s]: %w", ref, err)
+	}
+
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("ref [%s]: %w", ref, err)
+	}
+
+	root, err := worktreeRoot(r, repository)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TreeSource{
+		modTime:    commit.Committer.When,
+		submodules: make(map[string]repoTree),
+		top:        repoTree{repository: r, tree: tree},
+		root:       root,
+		ref:        ref,
+		commit:     hash.String(),
+	}, nil
+}
+
+// Commit returns the hash of the commit the ref resolves to.
+func (s *TreeSource) Commit() string {
+	return s.commit
+}
+
+// Walk walks the tree rooted at root in lexical order, calling fn for each file and directory.
+// Paths passed to fn are built by joining root with the tree paths, as `filepath.Walk` does.
+// Submodules are walked as directories with the files of their recorded commits.
+func (s *TreeSource) Walk(root string, fn filepath.WalkFunc) error {
+	rel, err := s.relative(root)
+	if err != nil {
+		return fn(root, nil, err)
+	}
+
+	s.mu.Lock()
+	info, dir, err := s.stat(rel)
+	s.mu.Unlock()
+	if err != nil {
+		return fn(root, nil, err)
+	}
+
+	if dir == nil {
+		return fn(root, info, nil)
+	}
+
+	err = s.walkTree(root, *dir, info, fn)
+	if errors.Is(err, filepath.SkipDir) || errors.Is(err, filepath.SkipAll) {
+		return nil
+	}
+
+	return err
+}
+
+func (s *TreeSource) walkTree(p string, dir treeDir, info os.FileInfo, fn filepath.WalkFunc) error {
+	if err := fn(p, info, nil); err != nil {
+		return err
+	}
+
+	for _, entry := range dir.tree.Entries {
+		entryPath := filepath.Join(p, entry.Name)
+
+		if entry.Mode != filemode.Dir && entry.Mode != filemode.Submodule {
+			if err := fn(entryPath, s.entryInfo(entry, 0), nil); err != nil {
+				if errors.Is(err, filepath.SkipDir) {
+					return nil
+				}
+				return err
+			}
+			continue
+		}
+
+		s.mu.Lock()
+		subdir, err := s.subdir(dir, entry)
+		s.mu.Unlock()
+		if err != nil {
+			if err := fn(entryPath, nil, err); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := s.walkTree(entryPath, subdir, s.entryInfo(entry, 0), fn); err != nil {
+			if errors.Is(err, filepath.SkipDir) {
+				continue
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+// IsDir reports whether the path is a directory in the tree.
+func (s *TreeSource) IsDir(p string) (bool, error) {
+	rel, err := s.relative(p)
+	if err != nil {
+		return false, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	info, _, err := s.stat(rel)
+	if err != nil {
+		return false, err
+	}
+
+	return info.IsDir(), nil
+}
+
+// Open reads the file from the tree. Symbolic links are not supported.
+func (s *TreeSource) Open(p string) (io.ReadCloser, os.FileInfo, error) {
+	rel, err := s.relative(p)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	repo, inner, err := s.locate(rel)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if inner == "" {
+		return nil, nil, fmt.Errorf("%s: is a submodule, not a file", p)
+	}
+
+	file, err := repo.tree.File(inner)
+	if err != nil {
+		return nil, nil, s.notFound(p, err)
+	}
+
+	if file.Mode == filemode.Symlink {
+		return nil, nil, fmt.Errorf("%s: symbolic links are not supported when building from ref %s", p, s.ref)
+	}
+
+	content, err := file.Contents()
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", p, err)
+	}
+
+	info := &treeFileInfo{
+		modTime: s.modTime,
+		name:    path.Base(rel),
+		size:    file.Size,
+		mode:    fileMode(file.Mode),
+	}
+
+	return io.NopCloser(bytes.NewReader([]byte(content))), info, nil
+}
+
+// relative converts a stage path into a slash-separated path relative to the repository root.
+func (s *TreeSource) relative(p string) (string, error) {
+	abs, err := filepath.Abs(p)
+	if err != nil {
+		return "", err
+	}
+
+	rel, err := filepath.Rel(s.root, filepath.Clean(abs))
+	if err != nil {
+		return "", err
+	}
+
+	rel = filepath.ToSlash(rel)
+	if rel == ".." || strings.HasPrefix(rel, "../") {
+		return "", fmt.Errorf("%s: path is outside the repository %s", p, s.root)
+	}
+
+	return rel, nil
+}
+
+// stat returns the info of the path and, for directories and submodules, the directory.
+// The caller must hold the lock.
+func (s *TreeSource) stat(rel string) (os.FileInfo, *treeDir, error) {
+	if rel == "." {
+		info := &treeFileInfo{modTime: s.modTime, name: path.Base(s.root), mode: fs.ModeDir | 0755}
+		return info, &treeDir{repo: s.top, tree: s.top.tree}, nil
+	}
+
+	repo, inner, err := s.locate(rel)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if inner == "" {
+		info := &treeFileInfo{modTime: s.modTime, name: path.Base(rel), mode: fs.ModeDir | 0755}
+		return info, &treeDir{repo: repo, tree: repo.tree}, nil
+	}
+
+	entry, err := repo.tree.FindEntry(inner)
+	if err != nil {
+		return nil, nil, s.notFound(rel, err)
+	}
+
+	if entry.Mode != filemode.Dir {
+		var size int64
+		if file, err := repo.tree.TreeEntryFile(entry); err == nil {
+			size = file.Size
+		}
+
+		return s.entryInfo(*entry, size), nil, nil
+	}
+
+	tree, err := repo.tree.Tree(inner)
+	if err != nil {
+		return nil, nil, s.notFound(rel, err)
+	}
+
+	return s.entryInfo(*entry, 0), &treeDir{repo: repo, tree: tree, inner: inner}, nil
+}
+
+// locate returns the repository the path belongs to, following the submodules on the path,
+// and the path inside that repository; the inner path is empty if rel is a submodule root.
+// The caller must hold the lock.
+func (s *TreeSource) locate(rel string) (repoTree, string, error) {
+	repo := s.top
+	parts := strings.Split(rel, "/")
+
+	start := 0
+	for i := range parts {
+		inner := strings.Join(parts[start:i+1], "/")
+
+		entry, err := repo.tree.FindEntry(inner)
+		if err != nil {
+			return repoTree{}, "", s.notFound(rel, err)
+		}
+
+		if entry.Mode != filemode.Submodule {
+			continue
+		}
+
+		if repo, err = s.submodule(repo, inner, entry.Hash); err != nil {
+			return repoTree{}, "", err
+		}
+		start = i + 1
+	}
+
+	return repo, strings.Join(parts[start:], "/"), nil
+}
+
+// subdir returns the directory of a tree entry of the directory: a subtree, or the root tree
+// of a submodule. The caller must hold the lock.
+func (s *TreeSource) subdir(dir treeDir, entry object.TreeEntry) (treeDir, error) {
+	inner := joinPath(dir.inner, entry.Name)
+
+	if entry.Mode == filemode.Submodule {
+		repo, err := s.submodule(dir.repo, inner, entry.Hash)
+		if err != nil {
+			return treeDir{}, err
+		}
+
+		return treeDir{repo: repo, tree: repo.tree}, nil
+	}
+
+	tree, err := dir.tree.Tree(entry.Name)
+	if err != nil {
+		return treeDir{}, err
+	}
+
+	return treeDir{repo: dir.repo, tree: tree, inner: inner}, nil
+}
+
+// submodule returns the tree of the submodule at subPath of the repository at the recorded commit.
+// The submodules are opened once. The caller must hold the lock.
+func (s *TreeSource) submodule(parent repoTree, subPath string, commit plumbing.Hash) (repoTree, error) {
+	key := joinPath(parent.path, subPath)
+	if repo, ok := s.submodules[key]; ok {
+		return repo, nil
+	}
+
+	r, err := openSubmoduleFunc(parent.repository, subPath)
+	if err != nil {
+		return repoTree{}, fmt.Errorf("ref %s: %w", s.ref, err)
+	}
+
+	tree, err := commitTree(r, commit)
+	if err != nil {
+		return repoTree{}, fmt.Errorf("ref %s: submodule [%s]: %w", s.ref, key, err)
+	}
+
+	repo := repoTree{repository: r, tree: tree, path: key}
+	s.submodules[key] = repo
+
+	return repo, nil
+}
+
+func (s *TreeSource) entryInfo(entry object.TreeEntry, size int64) os.FileInfo {
+	return &treeFileInfo{
+		modTime: s.modTime,
+		name:    entry.Name,
+		size:    size,
+		mode:    fileMode(entry.Mode),
+	}
+}
+
+func (s *TreeSource) notFound(p string, err error) error {
+	return fmt.Errorf("%s: not found at ref %s: %w", p, s.ref, err)
+}
+
+// fileMode converts a Git file mode into an os.FileMode.
+func fileMode(m filemode.FileMode) os.FileMode {
+	if m == filemode.Submodule {
+		return fs.ModeDir | 0755
+	}
+
+	mode, err := m.ToOSFileMode()
+	if err != nil {
+		return 0644
+	}
+
+	return mode
+}
+
+// treeFileInfo implements os.FileInfo for Git tree entries.
+type treeFileInfo struct {
+	modTime time.Time
+	name    string
+	size    int64
+	mode    os.FileMode
+}
+
+func (i *treeFileInfo) Name() string       { return i.name }
+func (i *treeFileInfo) Size() int64        { return i.size }
+func (i *treeFileInfo) Mode() os.FileMode  { return i.mode }
+func (i *treeFileInfo) ModTime() time.Time { return i.modTime }
+func (i *treeFileInfo) IsDir() bool        { return i.mode.IsDir() }
+func (i *treeFileInfo) Sys() any           { return nil }
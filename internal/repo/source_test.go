@@ -0,0 +1,250 @@
+package repo
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestTreeSource(t *testing.T) (*TreeSource, time.Time) {
+	t.Helper()
+
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	r := newTestRepository(t, []testCommit{
+		{message: "initial", when: base, files: map[string]string{"lib/a.php": "old"}},
+		{message: "second", when: base.Add(time.Hour), files: map[string]string{
+			"lib/a.php":         "new",
+			"lib/lang/ru/a.php": "lang",
+			"lib/skip/s.php":    "skip",
+			"other/b.txt":       "b",
+		}},
+	})
+
+	source, err := newTreeSource(r, "/", "v2.0.0")
+	require.NoError(t, err)
+
+	return source, base.Add(time.Hour)
+}
+
+func TestTreeSource_Walk(t *testing.T) {
+	t.Parallel()
+	source, _ := newTestTreeSource(t)
+
+	var files, dirs []string
+	err := source.Walk("/lib", func(path string, info os.FileInfo, err error) error {
+		require.NoError(t, err)
+		if info.IsDir() {
+			dirs = append(dirs, path)
+			if info.Name() == "skip" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		files = append(files, path)
+		return nil
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"/lib", "/lib/lang", "/lib/lang/ru", "/lib/skip"}, dirs)
+	assert.Equal(t, []string{"/lib/a.php", "/lib/lang/ru/a.php"}, files)
+}
+
+func TestTreeSource_Walk_file(t *testing.T) {
+	t.Parallel()
+	source, _ := newTestTreeSource(t)
+
+	var files []string
+	err := source.Walk("/other/b.txt", func(path string, info os.FileInfo, err error) error {
+		require.NoError(t, err)
+		files = append(files, path)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/other/b.txt"}, files)
+}
+
+func TestTreeSource_Walk_missing(t *testing.T) {
+	t.Parallel()
+	source, _ := newTestTreeSource(t)
+
+	err := source.Walk("/missing", func(_ string, _ os.FileInfo, err error) error {
+		return err
+	})
+	require.Error(t, err)
+}
+
+func TestTreeSource_IsDir(t *testing.T) {
+	t.Parallel()
+	source, _ := newTestTreeSource(t)
+
+	tests := []struct {
+		name    string
+		path    string
+		want    bool
+		wantErr bool
+	}{
+		{"root", "/", true, false},
+		{"dir", "/lib/lang", true, false},
+		{"file", "/lib/a.php", false, false},
+		{"missing", "/lib/b.php", false, true},
+		{"not cleaned", "/lib/../lib/lang", true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := source.IsDir(tt.path)
+			if tt.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestTreeSource_Open(t *testing.T) {
+	t.Parallel()
+	source, when := newTestTreeSource(t)
+
+	f, info, err := source.Open("/lib/a.php")
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	content, err := io.ReadAll(f)
+	require.NoError(t, err)
+	assert.Equal(t, "new", string(content))
+	assert.Equal(t, "a.php", info.Name())
+	assert.Equal(t, int64(3), info.Size())
+	assert.True(t, info.Mode().IsRegular())
+	assert.True(t, when.Equal(info.ModTime()))
+
+	_, _, err = source.Open("/lib/missing.php")
+	require.Error(t, err)
+}
+
+func TestTreeSource_relative(t *testing.T) {
+	t.Parallel()
+	source := &TreeSource{root: "/repo"}
+
+	rel, err := source.relative("/repo/lib/../lib/a.php")
+	require.NoError(t, err)
+	assert.Equal(t, "lib/a.php", rel)
+
+	_, err = source.relative("/other/a.php")
+	require.Error(t, err)
+}
+
+func TestNewTreeSource(t *testing.T) {
+	r := newTestRepository(t, []testCommit{
+		{message: "initial", when: time.Now(), files: map[string]string{"a.txt": "a"}},
+	})
+
+	origOpenRepositoryFunc := openRepositoryFunc
+	defer func() { openRepositoryFunc = origOpenRepositoryFunc }()
+
+	openRepositoryFunc = func(_ string) (*git.Repository, error) {
+		return r, nil
+	}
+
+	source, err := NewTreeSource("repo", "v1.0.0")
+	require.NoError(t, err)
+
+	head, err := r.Head()
+	require.NoError(t, err)
+	assert.Equal(t, head.Hash().String(), source.Commit())
+
+	_, err = NewTreeSource("repo", "v9.9.9")
+	require.Error(t, err)
+}
+
+func TestHeadCommit(t *testing.T) {
+	r := newTestRepository(t, []testCommit{
+		{message: "initial", when: time.Now(), files: map[string]string{"a.txt": "a"}},
+		{message: "second", when: time.Now(), files: map[string]string{"a.txt": "b"}},
+	})
+
+	origOpenRepositoryFunc := openRepositoryFunc
+	defer func() { openRepositoryFunc = origOpenRepositoryFunc }()
+
+	openRepositoryFunc = func(_ string) (*git.Repository, error) {
+		return r, nil
+	}
+
+	head, err := r.Head()
+	require.NoError(t, err)
+
+	hash, err := HeadCommit("repo")
+	require.NoError(t, err)
+	assert.Equal(t, head.Hash().String(), hash)
+
+	openRepositoryFunc = OpenRepository
+	_, err = HeadCommit("")
+	require.Error(t, err)
+}
+
+func TestTreeSource_submodule(t *testing.T) {
+	parent, sub := newSubmoduleRepositories(t)
+
+	original := openSubmoduleFunc
+	defer func() {
+		openSubmoduleFunc = original
+	}()
+
+	opened := 0
+	openSubmoduleFunc = func(_ *git.Repository, path string) (*git.Repository, error) {
+		require.Equal(t, "sub", path)
+		opened++
+		return sub, nil
+	}
+
+	source, err := newTreeSource(parent, "/", "v1.0.0")
+	require.NoError(t, err)
+
+	var files []string
+	err = source.Walk("/", func(path string, info os.FileInfo, err error) error {
+		require.NoError(t, err)
+		if !info.IsDir() {
+			files = append(files, path)
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/readme.md", "/sub/a.php"}, files, "the files of the recorded commit")
+
+	isDir, err := source.IsDir("/sub")
+	require.NoError(t, err)
+	assert.True(t, isDir)
+
+	rc, _, err := source.Open("/sub/a.php")
+	require.NoError(t, err)
+	content, err := io.ReadAll(rc)
+	require.NoError(t, err)
+	assert.Equal(t, "a", string(content))
+
+	_, err = source.IsDir("/sub/b.php")
+	require.Error(t, err, "b.php is added to the submodule after the recorded commit")
+	assert.Equal(t, 1, opened, "the submodule is opened once")
+
+	openSubmoduleFunc = func(_ *git.Repository, path string) (*git.Repository, error) {
+		return nil, errors.New("submodule [sub] is not initialized")
+	}
+
+	source, err = newTreeSource(parent, "/", "v2.0.0")
+	require.NoError(t, err)
+
+	_, err = source.IsDir("/sub/a.php")
+	require.EqualError(t, err, "ref v2.0.0: submodule [sub] is not initialized")
+
+	err = source.Walk("/", func(path string, info os.FileInfo, err error) error {
+		return err
+	})
+	require.EqualError(t, err, "ref v2.0.0: submodule [sub] is not initialized", "the build fails")
+}
@@ -0,0 +1,281 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+
+	"github.com/pixel365/bx/internal/types"
+	"github.com/pixel365/bx/internal/types/changelog"
+)
+
+var openSubmoduleFunc = openSubmodule
+
+// collectChanges diffs two trees of the repository and adds the changed files to changes.
+//
+// Parameters:
+//   - repository: The repository the trees belong to.
+//   - from, to: The trees to compare; nil means an empty tree.
+//   - prefix: The path of the repository inside the top-level repository; empty for the top level.
+//   - scope: Paths relative to the repository; if not empty, files outside them are left out.
+//   - changes: The changes to add to. Paths are prefixed with prefix.
+//
+// Behavior:
+//   - Renames are detected and listed as moved, with the old path kept in `Renamed`.
+//   - For submodule entries, the submodule repository is opened and diffed between the
+//     recorded commits, recursively.
+func collectChanges(
+	repository *git.Repository,
+	from, to *object.Tree,
+	prefix string,
+	scope []string,
+	changes *types.Changes,
+) error {
+	diff, err := object.DiffTreeWithOptions(context.Background(), from, to, object.DefaultDiffTreeOptions)
+	if err != nil {
+		return err
+	}
+
+	for _, change := range diff {
+		if isSubmodule(change.From) || isSubmodule(change.To) {
+			if err := submoduleChanges(repository, change, prefix, scope, changes); err != nil {
+				return err
+			}
+			continue
+		}
+
+		fromPath, toPath := change.From.Name, change.To.Name
+
+		if len(scope) > 0 && !InScope(fromPath, scope) && !InScope(toPath, scope) {
+			continue
+		}
+
+		switch {
+		case fromPath == "":
+			changes.Added = append(changes.Added, joinPath(prefix, toPath))
+		case toPath == "":
+			changes.Deleted = append(changes.Deleted, joinPath(prefix, fromPath))
+		case fromPath != toPath:
+			changes.Moved = append(changes.Moved, joinPath(prefix, toPath))
+			if changes.Renamed == nil {
+				changes.Renamed = make(map[string]string)
+			}
+			changes.Renamed[joinPath(prefix, toPath)] = joinPath(prefix, fromPath)
+		default:
+			changes.Modified = append(changes.Modified, joinPath(prefix, fromPath))
+		}
+	}
+
+	return nil
+}
+
+// submoduleChanges adds the changes of a submodule entry whose recorded commit changed.
+// If the entry changed its type (e.g. a submodule replaced with a file), the file side
+// is reported as added or deleted.
+func submoduleChanges(
+	repository *git.Repository,
+	change *object.Change,
+	prefix string,
+	scope []string,
+	changes *types.Changes,
+) error {
+	name := change.To.Name
+	if name == "" {
+		name = change.From.Name
+	}
+
+	if !isSubmodule(change.From) && change.From.Name != "" && InScope(change.From.Name, scope) {
+		changes.Deleted = append(changes.Deleted, joinPath(prefix, change.From.Name))
+	}
+
+	if !isSubmodule(change.To) && change.To.Name != "" && InScope(change.To.Name, scope) {
+		changes.Added = append(changes.Added, joinPath(prefix, change.To.Name))
+	}
+
+	subScope, ok := submoduleScope(name, scope)
+	if !ok {
+		return nil
+	}
+
+	sub, err := openSubmoduleFunc(repository, name)
+	if err != nil {
+		return err
+	}
+
+	var fromTree, toTree *object.Tree
+
+	if isSubmodule(change.From) {
+		if fromTree, err = commitTree(sub, change.From.TreeEntry.Hash); err != nil {
+			return fmt.Errorf("submodule [%s]: %w", name, err)
+		}
+	}
+
+	if isSubmodule(change.To) {
+		if toTree, err = commitTree(sub, change.To.TreeEntry.Hash); err != nil {
+			return fmt.Errorf("submodule [%s]: %w", name, err)
+		}
+	}
+
+	return collectChanges(sub, fromTree, toTree, joinPath(prefix, name), subScope, changes)
+}
+
+// submoduleCommits returns the changelog commits of the submodules whose recorded commit
+// changed between the two commits of the repository, recursively.
+//
+// For every such submodule, the commits between the recorded commits are read from the
+// submodule repository; if the submodule was added, its whole history up to the recorded
+// commit is used, and if it was removed, it is skipped. The topological positions start
+// at offset, so the entries are ordered after the commits of the repository itself.
+func submoduleCommits(
+	repository *git.Repository,
+	startHash, endHash plumbing.Hash,
+	offset int,
+	rules changelog.Changelog,
+	scope []string,
+	filter CommitFilterFunc,
+) ([]changelog.Commit, error) {
+	var startTree *object.Tree
+	if !startHash.IsZero() {
+		tree, err := commitTree(repository, startHash)
+		if err != nil {
+			return nil, err
+		}
+		startTree = tree
+	}
+
+	endTree, err := commitTree(repository, endHash)
+	if err != nil {
+		return nil, err
+	}
+
+	diff, err := object.DiffTree(startTree, endTree)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []changelog.Commit
+	for _, change := range diff {
+		if !isSubmodule(change.To) {
+			continue
+		}
+
+		name := change.To.Name
+		subScope, ok := submoduleScope(name, scope)
+		if !ok {
+			continue
+		}
+
+		sub, err := openSubmoduleFunc(repository, name)
+		if err != nil {
+			return nil, err
+		}
+
+		var subStart plumbing.Hash
+		if isSubmodule(change.From) {
+			subStart = change.From.TreeEntry.Hash
+		}
+		subEnd := change.To.TreeEntry.Hash
+
+		visited, err := rangeCommits(sub, subStart, subEnd)
+		if err != nil {
+			return nil, fmt.Errorf("submodule [%s]: %w", name, err)
+		}
+
+		commits, err := filterCommits(visited, offset, rules, subScope, filter)
+		if err != nil {
+			return nil, fmt.Errorf("submodule [%s]: %w", name, err)
+		}
+		result = append(result, commits...)
+		offset += len(visited)
+
+		nested, err := submoduleCommits(sub, subStart, subEnd, offset, rules, subScope, filter)
+		if err != nil {
+			return nil, fmt.Errorf("submodule [%s]: %w", name, err)
+		}
+		result = append(result, nested...)
+		for _, c := range nested {
+			offset = max(offset, c.Order+1)
+		}
+	}
+
+	return result, nil
+}
+
+// openSubmodule opens the repository of the submodule at the path of the repository worktree.
+// The submodule must be initialized.
+func openSubmodule(repository *git.Repository, subPath string) (*git.Repository, error) {
+	wt, err := repository.Worktree()
+	if err != nil {
+		return nil, fmt.Errorf("submodule [%s]: %w", subPath, err)
+	}
+
+	subs, err := wt.Submodules()
+	if err != nil {
+		return nil, fmt.Errorf("submodule [%s]: %w", subPath, err)
+	}
+
+	for _, sub := range subs {
+		if path.Clean(sub.Config().Path) != subPath {
+			continue
+		}
+
+		r, err := sub.Repository()
+		if err != nil {
+			return nil, fmt.Errorf("submodule [%s] is not initialized: %w", subPath, err)
+		}
+
+		return r, nil
+	}
+
+	return nil, fmt.Errorf("submodule [%s]: not found in .gitmodules", subPath)
+}
+
+// submoduleScope maps the scope of a repository onto its submodule at subPath.
+//
+// Returns:
+//   - nil and true if the scope is empty or covers the whole submodule.
+//   - The scope paths inside the submodule, relative to it, and true.
+//   - nil and false if no scope path overlaps the submodule.
+func submoduleScope(subPath string, scope []string) ([]string, bool) {
+	if len(scope) == 0 || InScope(subPath, scope) {
+		return nil, true
+	}
+
+	var result []string
+	for _, p := range scope {
+		p = path.Clean(filepath.ToSlash(p))
+		if rel, found := strings.CutPrefix(p, subPath+"/"); found {
+			result = append(result, rel)
+		}
+	}
+
+	return result, len(result) > 0
+}
+
+func commitTree(repository *git.Repository, hash plumbing.Hash) (*object.Tree, error) {
+	commit, err := repository.CommitObject(hash)
+	if err != nil {
+		return nil, fmt.Errorf("commit [%s]: %w", hash, err)
+	}
+
+	return commit.Tree()
+}
+
+func isSubmodule(entry object.ChangeEntry) bool {
+	return entry.Name != "" && entry.TreeEntry.Mode == filemode.Submodule
+}
+
+func joinPath(prefix, p string) string {
+	if prefix == "" {
+		return p
+	}
+
+	return prefix + "/" + p
+}
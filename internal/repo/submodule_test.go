@@ -0,0 +1,229 @@
+package repo
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pixel365/bx/internal/types"
+	"github.com/pixel365/bx/internal/types/changelog"
+)
+
+// commitEntries stores a flat tree with the given blobs and submodule links and commits it.
+func commitEntries(
+	t *testing.T,
+	r *git.Repository,
+	parent plumbing.Hash,
+	blobs map[string]string,
+	links map[string]plumbing.Hash,
+	message string,
+	when time.Time,
+) plumbing.Hash {
+	t.Helper()
+
+	tree := &object.Tree{}
+	for name, content := range blobs {
+		obj := r.Storer.NewEncodedObject()
+		obj.SetType(plumbing.BlobObject)
+		w, err := obj.Writer()
+		require.NoError(t, err)
+		_, err = w.Write([]byte(content))
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+
+		hash, err := r.Storer.SetEncodedObject(obj)
+		require.NoError(t, err)
+		tree.Entries = append(tree.Entries, object.TreeEntry{Name: name, Mode: filemode.Regular, Hash: hash})
+	}
+
+	for name, hash := range links {
+		tree.Entries = append(tree.Entries, object.TreeEntry{Name: name, Mode: filemode.Submodule, Hash: hash})
+	}
+
+	slices.SortFunc(tree.Entries, func(a, b object.TreeEntry) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	treeObj := r.Storer.NewEncodedObject()
+	require.NoError(t, tree.Encode(treeObj))
+	treeHash, err := r.Storer.SetEncodedObject(treeObj)
+	require.NoError(t, err)
+
+	sig := object.Signature{Name: "tester", Email: "tester@example.com", When: when}
+	commit := &object.Commit{Author: sig, Committer: sig, Message: message, TreeHash: treeHash}
+	if !parent.IsZero() {
+		commit.ParentHashes = []plumbing.Hash{parent}
+	}
+
+	commitObj := r.Storer.NewEncodedObject()
+	require.NoError(t, commit.Encode(commitObj))
+	hash, err := r.Storer.SetEncodedObject(commitObj)
+	require.NoError(t, err)
+
+	return hash
+}
+
+// newSubmoduleRepositories creates a parent repository with two commits tagged v1.0.0 and v2.0.0
+// that move the `sub` submodule from its first to its last commit.
+func newSubmoduleRepositories(t *testing.T) (*git.Repository, *git.Repository) {
+	t.Helper()
+
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	sub := newTestRepository(t, []testCommit{
+		{message: "sub: initial", when: base, files: map[string]string{"a.php": "a"}},
+		{message: "sub: add b", when: base.Add(time.Hour), files: map[string]string{"b.php": "b"}},
+		{message: "sub: change a", when: base.Add(2 * time.Hour), files: map[string]string{"a.php": "a2"}},
+	})
+
+	first, err := sub.Tag("v1.0.0")
+	require.NoError(t, err)
+	last, err := sub.Tag("v2.0.0")
+	require.NoError(t, err)
+
+	parent, err := git.Init(memory.NewStorage(), memfs.New())
+	require.NoError(t, err)
+
+	p1 := commitEntries(t, parent, plumbing.ZeroHash,
+		map[string]string{"readme.md": "r"}, map[string]plumbing.Hash{"sub": first.Hash()}, "initial", base)
+	p2 := commitEntries(t, parent, p1,
+		map[string]string{"readme.md": "r2"}, map[string]plumbing.Hash{"sub": last.Hash()}, "bump sub", base.Add(3*time.Hour))
+
+	_, err = parent.CreateTag("v1.0.0", p1, nil)
+	require.NoError(t, err)
+	_, err = parent.CreateTag("v2.0.0", p2, nil)
+	require.NoError(t, err)
+
+	return parent, sub
+}
+
+func TestChangesList_submodule(t *testing.T) {
+	parent, sub := newSubmoduleRepositories(t)
+
+	origOpenRepositoryFunc := openRepositoryFunc
+	origOpenSubmoduleFunc := openSubmoduleFunc
+	defer func() {
+		openRepositoryFunc = origOpenRepositoryFunc
+		openSubmoduleFunc = origOpenSubmoduleFunc
+	}()
+
+	openRepositoryFunc = func(_ string) (*git.Repository, error) {
+		return parent, nil
+	}
+	openSubmoduleFunc = func(_ *git.Repository, path string) (*git.Repository, error) {
+		require.Equal(t, "sub", path)
+		return sub, nil
+	}
+
+	rules := changelog.Changelog{
+		From: types.TypeValue[types.ChangelogType, string]{Type: types.Tag, Value: "v1.0.0"},
+		To:   types.TypeValue[types.ChangelogType, string]{Type: types.Tag, Value: "v2.0.0"},
+	}
+
+	changes, err := ChangesList("repo", rules, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"sub/b.php"}, changes.Added)
+	assert.ElementsMatch(t, []string{"readme.md", "sub/a.php"}, changes.Modified)
+
+	changes, err = ChangesList("repo", rules, []string{"sub/b.php"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"sub/b.php"}, changes.Added)
+	assert.Empty(t, changes.Modified)
+
+	commits, err := listOfCommits(parent, rules, nil, CommitFilter)
+	require.NoError(t, err)
+
+	var messages []string
+	for _, c := range commits {
+		messages = append(messages, c.Message)
+	}
+	assert.Equal(t, []string{"bump sub", "sub: change a", "sub: add b"}, messages)
+	assert.Less(t, commits[0].Order, commits[1].Order)
+
+	commits, err = listOfCommits(parent, rules, []string{"sub/b.php"}, CommitFilter)
+	require.NoError(t, err)
+	require.Len(t, commits, 1)
+	assert.Equal(t, "sub: add b", commits[0].Message)
+}
+
+func TestSubmoduleScope(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		path   string
+		scope  []string
+		want   []string
+		wantOk bool
+	}{
+		{"empty scope", "sub", nil, nil, true},
+		{"covers submodule", "vendor/sub", []string{"vendor"}, nil, true},
+		{"inside submodule", "sub", []string{"sub/lib", "other", "sub/a.php"}, []string{"lib", "a.php"}, true},
+		{"no overlap", "sub", []string{"subdir", "other"}, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, ok := submoduleScope(tt.path, tt.scope)
+			assert.Equal(t, tt.wantOk, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestOpenRepository_discovery(t *testing.T) {
+	root, err := filepath.Abs("../../")
+	require.NoError(t, err)
+
+	got, err := Root(".")
+	require.NoError(t, err)
+	assert.Equal(t, root, got)
+}
+
+func TestOpenRepository_linked_worktree(t *testing.T) {
+	dir := t.TempDir()
+	mainDir := filepath.Join(dir, "main")
+	wtDir := filepath.Join(dir, "wt")
+
+	r, err := git.PlainInit(mainDir, false)
+	require.NoError(t, err)
+
+	w, err := r.Worktree()
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(mainDir, "a.txt"), []byte("a"), 0600))
+	_, err = w.Add("a.txt")
+	require.NoError(t, err)
+	sig := &object.Signature{Name: "tester", Email: "tester@example.com", When: time.Now()}
+	hash, err := w.Commit("initial", &git.CommitOptions{Author: sig, Committer: sig})
+	require.NoError(t, err)
+
+	// Lay out a linked worktree the way `git worktree add` does.
+	gitDir := filepath.Join(mainDir, ".git", "worktrees", "wt")
+	require.NoError(t, os.MkdirAll(gitDir, 0750))
+	require.NoError(t, os.MkdirAll(filepath.Join(wtDir, "lib"), 0750))
+	require.NoError(t, os.WriteFile(filepath.Join(gitDir, "HEAD"), []byte(hash.String()+"\n"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(gitDir, "commondir"), []byte("../..\n"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(gitDir, "gitdir"), []byte(filepath.Join(wtDir, ".git")+"\n"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(wtDir, ".git"), []byte("gitdir: "+gitDir+"\n"), 0600))
+
+	opened, err := OpenRepository(filepath.Join(wtDir, "lib"))
+	require.NoError(t, err)
+
+	head, err := opened.Head()
+	require.NoError(t, err)
+	assert.Equal(t, hash, head.Hash())
+
+	root, err := Root(filepath.Join(wtDir, "lib"))
+	require.NoError(t, err)
+	assert.Equal(t, wtDir, root)
+}
@@ -0,0 +1,282 @@
+package parser
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+
+	errors2 "github.com/pixel365/bx/internal/errors"
+)
+
+// PageKind is the kind of portal page recognized by Classify.
+type PageKind int
+
+const (
+	// PageUnknown is a page of none of the known kinds, e.g. the expected result of a request
+	// or a page of a changed layout.
+	PageUnknown PageKind = iota
+	// PageLogin is the login form, shown instead of any page when the session has expired.
+	PageLogin
+	// PageAccessDenied is shown when the account has no access to the page or the module.
+	PageAccessDenied
+	// PageValidation is a page with an error message, e.g. a rejected upload.
+	PageValidation
+	// PageMaintenance is shown while the portal is closed for maintenance.
+	PageMaintenance
+)
+
+const excerptLength = 200
+
+var (
+	maintenanceMarkers = []string{
+		"технические работы",
+		"техническое обслуживание",
+		"на реконструкции",
+		"временно недоступен",
+		"under maintenance",
+	}
+	accessDeniedMarkers = []string{
+		"доступ запрещен",
+		"доступ запрещён",
+		"недостаточно прав",
+		"access denied",
+	}
+	sessionExpiredMarkers = []string{
+		"сессия истекла",
+		"сессия устарела",
+		"session has expired",
+	}
+	errorClasses = []string{"color-red", "errortext", "alert-danger", "ui-alert-danger"}
+	loginInputs  = []string{"USER_LOGIN", "USER_PASSWORD"}
+)
+
+// Page is the result of Classify.
+type Page struct {
+	// Message is the error message shown on the page, if any.
+	Message string
+	// Excerpt is the beginning of the visible text of the page.
+	Excerpt string
+	Kind    PageKind
+}
+
+// Classify recognizes the kind of portal page by its content rather than by the exact markup:
+// the login form is recognized by its inputs, error messages by the classes used for them
+// on the portal, and the access denied and maintenance pages by the text of the title,
+// the h1 headings and the error messages. The rest of the page, e.g. the description of
+// a version, is not searched for the markers.
+//
+// Parameters:
+//   - content: The HTML of the page.
+//
+// Returns:
+//   - Page: The kind of the page, the error message found on it and an excerpt of its text.
+func Classify(content string) Page {
+	doc, err := html.Parse(strings.NewReader(content))
+	if err != nil {
+		return Page{Kind: PageUnknown, Excerpt: excerpt(content)}
+	}
+
+	text := visibleText(doc)
+
+	page := Page{
+		Kind:    PageUnknown,
+		Message: errorMessage(doc),
+		Excerpt: excerpt(text),
+	}
+
+	lower := strings.ToLower(headlineText(doc) + " " + page.Message)
+
+	switch {
+	case containsAny(lower, maintenanceMarkers):
+		page.Kind = PageMaintenance
+	case isLoginForm(doc):
+		page.Kind = PageLogin
+	case containsAny(lower, accessDeniedMarkers):
+		page.Kind = PageAccessDenied
+	case page.Message != "" && containsAny(strings.ToLower(page.Message), sessionExpiredMarkers):
+		page.Kind = PageLogin
+	case page.Message != "":
+		page.Kind = PageValidation
+	}
+
+	return page
+}
+
+// PageError returns the typed error for a page that is not the expected result of a request.
+//
+// Parameters:
+//   - status: The HTTP status of the response; 0 or 200 if the status is fine.
+//   - content: The HTML of the page.
+//   - expected: What was looked for on the page, e.g. "versions table"; used when the page is unknown.
+//
+// Returns:
+//   - *errors.PortalError wrapping errors.ErrPortalSessionExpired for the login form,
+//     errors.ErrPortalAccessDenied, errors.ErrPortalValidation, errors.ErrPortalMaintenance,
+//     or errors.ErrPortalUnexpectedPage for a page of unknown layout.
+func PageError(status int, content, expected string) error {
+	page := Classify(content)
+
+	err := &errors2.PortalError{Message: page.Message, Excerpt: page.Excerpt}
+	if status != 0 && status != http.StatusOK {
+		err.Status = status
+	}
+
+	switch page.Kind {
+	case PageLogin:
+		err.Err = errors2.ErrPortalSessionExpired
+	case PageAccessDenied:
+		err.Err = errors2.ErrPortalAccessDenied
+	case PageMaintenance:
+		err.Err = errors2.ErrPortalMaintenance
+	case PageValidation:
+		err.Err = errors2.ErrPortalValidation
+	default:
+		switch status {
+		case http.StatusUnauthorized, http.StatusForbidden:
+			err.Err = errors2.ErrPortalAccessDenied
+		case http.StatusServiceUnavailable:
+			err.Err = errors2.ErrPortalMaintenance
+		default:
+			err.Err = errors2.ErrPortalUnexpectedPage
+			if expected != "" {
+				err.Message = expected + " not found"
+			}
+		}
+	}
+
+	return err
+}
+
+func isLoginForm(doc *html.Node) bool {
+	found := false
+
+	walk(doc, func(n *html.Node) bool {
+		if n.Data == input && slices.Contains(loginInputs, attribute(n, "name")) {
+			found = true
+		}
+		return !found
+	})
+
+	return found
+}
+
+// headlineText returns the text of the title and the h1 headings of the page.
+func headlineText(doc *html.Node) string {
+	var parts []string
+
+	walk(doc, func(n *html.Node) bool {
+		switch n.Data {
+		case "title":
+			parts = append(parts, strings.Join(strings.Fields(nodeText(n)), " "))
+		case "h1":
+			parts = append(parts, visibleText(n))
+		}
+		return true
+	})
+
+	return strings.Join(parts, " ")
+}
+
+// nodeText returns the text of the direct text children of the node. It is used for the title,
+// which visibleText skips as a part of the head.
+func nodeText(n *html.Node) string {
+	var b strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.TextNode {
+			b.WriteString(c.Data)
+		}
+	}
+
+	return b.String()
+}
+
+// errorMessage returns the text of the first element styled as an error message.
+func errorMessage(doc *html.Node) string {
+	message := ""
+
+	walk(doc, func(n *html.Node) bool {
+		classes := strings.Fields(attribute(n, class))
+		for _, c := range errorClasses {
+			if slices.Contains(classes, c) {
+				if text := visibleText(n); text != "" {
+					message = text
+					return false
+				}
+			}
+		}
+		return true
+	})
+
+	return message
+}
+
+// walk calls f for every element node in document order until f returns false.
+func walk(n *html.Node, f func(*html.Node) bool) bool {
+	if n.Type == html.ElementNode && !f(n) {
+		return false
+	}
+
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if !walk(c, f) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func attribute(n *html.Node, key string) string {
+	for _, attr := range n.Attr {
+		if attr.Key == key {
+			return attr.Val
+		}
+	}
+
+	return ""
+}
+
+// visibleText returns the text of the node without scripts and styles, with whitespace collapsed.
+func visibleText(n *html.Node) string {
+	var b strings.Builder
+
+	var f func(*html.Node)
+	f = func(n *html.Node) {
+		if n.Type == html.ElementNode && (n.Data == "script" || n.Data == "style" || n.Data == "head") {
+			return
+		}
+		if n.Type == html.TextNode {
+			b.WriteString(n.Data)
+			b.WriteByte(' ')
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			f(c)
+		}
+	}
+	f(n)
+
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+func excerpt(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if utf8.RuneCountInString(text) <= excerptLength {
+		return text
+	}
+
+	runes := []rune(text)
+
+	return string(runes[:excerptLength]) + "…"
+}
+
+func containsAny(s string, markers []string) bool {
+	for _, m := range markers {
+		if strings.Contains(s, m) {
+			return true
+		}
+	}
+
+	return false
+}
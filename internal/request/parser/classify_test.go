@@ -0,0 +1,143 @@
+package parser
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	errors2 "github.com/pixel365/bx/internal/errors"
+	"github.com/pixel365/bx/internal/types"
+)
+
+// fixtures are pages saved from the portal, in testdata. Every page in testdata must be listed here,
+// so a page saved after a layout change is not forgotten.
+var fixtures = []struct {
+	file    string
+	message string
+	kind    PageKind
+}{
+	{"login.html", "", PageLogin},
+	{"login_error.html", "Неверный логин или пароль.", PageLogin},
+	{"session_expired.html", "Ваша сессия истекла. Повторите попытку.", PageLogin},
+	{"access_denied.html", "", PageAccessDenied},
+	{"validation.html", "Версия 1.2.0 уже загружена.", PageValidation},
+	{"maintenance.html", "", PageMaintenance},
+	{"unknown.html", "", PageUnknown},
+	{"edit.html", "", PageUnknown},
+	{"versions.html", "", PageUnknown},
+	{"upload_success.html", "", PageUnknown},
+	{"upload_success_description.html", "", PageUnknown},
+}
+
+func readFixture(t *testing.T, name string) string {
+	t.Helper()
+
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	require.NoError(t, err)
+
+	return string(data)
+}
+
+func TestClassify_fixtures(t *testing.T) {
+	t.Parallel()
+
+	files, err := filepath.Glob(filepath.Join("testdata", "*.html"))
+	require.NoError(t, err)
+
+	listed := make(map[string]bool, len(fixtures))
+	for _, f := range fixtures {
+		listed[f.file] = true
+	}
+	for _, f := range files {
+		assert.True(t, listed[filepath.Base(f)], "fixture %s has no expectation", f)
+	}
+
+	for _, tt := range fixtures {
+		t.Run(tt.file, func(t *testing.T) {
+			t.Parallel()
+
+			page := Classify(readFixture(t, tt.file))
+			assert.Equal(t, tt.kind, page.Kind)
+			assert.Equal(t, tt.message, page.Message)
+			assert.NotEmpty(t, page.Excerpt)
+			assert.LessOrEqual(t, len([]rune(page.Excerpt)), excerptLength+1)
+		})
+	}
+}
+
+func TestClassify_expectedContent(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "f4c1b0e2a9d3", ParseSessionId(readFixture(t, "edit.html")))
+
+	versions, err := ParseVersions(readFixture(t, "versions.html"))
+	require.NoError(t, err)
+	assert.Equal(t, types.Versions{"1.1.0": types.Beta, "1.0.0": types.Stable}, versions)
+
+	require.NoError(t, UploadResult(readFixture(t, "upload_success.html")))
+	require.NoError(t, UploadResult(readFixture(t, "upload_success_description.html")),
+		"the markers in the description of a version are not a portal failure")
+}
+
+func TestPageError(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		want     error
+		name     string
+		file     string
+		contains string
+		status   int
+	}{
+		{errors2.ErrPortalSessionExpired, "login", "login.html", "portal session has expired; page:", 0},
+		{errors2.ErrPortalSessionExpired, "session expired", "session_expired.html", "сессия истекла", 0},
+		{errors2.ErrPortalAccessDenied, "access denied", "access_denied.html", "Доступ запрещён", 0},
+		{errors2.ErrPortalValidation, "validation", "validation.html", "Версия 1.2.0 уже загружена.", 0},
+		{errors2.ErrPortalMaintenance, "maintenance", "maintenance.html", "(HTTP 503)", http.StatusServiceUnavailable},
+		{errors2.ErrPortalUnexpectedPage, "unknown", "unknown.html", "versions table not found", 0},
+		{errors2.ErrPortalAccessDenied, "forbidden status", "unknown.html", "(HTTP 403)", http.StatusForbidden},
+		{errors2.ErrPortalMaintenance, "unavailable status", "unknown.html", "(HTTP 503)", http.StatusServiceUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := PageError(tt.status, readFixture(t, tt.file), "versions table")
+			require.ErrorIs(t, err, tt.want)
+			assert.Contains(t, err.Error(), tt.contains)
+
+			var portalErr *errors2.PortalError
+			require.ErrorAs(t, err, &portalErr)
+			assert.NotEmpty(t, portalErr.Excerpt)
+		})
+	}
+}
+
+func TestParseVersions_pageErrors(t *testing.T) {
+	t.Parallel()
+
+	_, err := ParseVersions(readFixture(t, "login.html"))
+	require.ErrorIs(t, err, errors2.ErrPortalSessionExpired)
+
+	_, err = ParseVersions(readFixture(t, "maintenance.html"))
+	require.ErrorIs(t, err, errors2.ErrPortalMaintenance)
+
+	err = UploadResult(readFixture(t, "validation.html"))
+	require.ErrorIs(t, err, errors2.ErrPortalValidation)
+	assert.Equal(t, "portal rejected the request: Версия 1.2.0 уже загружена.", err.Error())
+}
+
+func Test_excerpt(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "a b c", excerpt("  a\n\tb   c "))
+
+	long := excerpt(strings.Repeat("я", excerptLength*2))
+	assert.Equal(t, excerptLength+1, len([]rune(long)))
+	assert.True(t, strings.HasSuffix(long, "…"))
+}
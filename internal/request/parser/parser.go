@@ -1,7 +1,6 @@
 package parser
 
 import (
-	"errors"
 	"strings"
 
 	"golang.org/x/net/html"
@@ -23,7 +22,8 @@ const (
 // Returns:
 //   - Versions: A map where each key is a version string (e.g., "3.0.10")
 //     and the value is a VersionLabel constant (Alpha, Beta, or Stable) representing the selected flag.
-//   - error: An error if the target versions table is not found in the HTML; otherwise, nil.
+//   - error: If the target versions table is not found in the HTML, the typed error of the page
+//     returned instead (see PageError); otherwise, nil.
 //
 // Description:
 // ParseVersions parses the given HTML content and looks for a <table> element with the class "data-table mt-3 mb-3".
@@ -31,7 +31,8 @@ const (
 // extracting the version string (from the first <td>)
 // and identifying which radio input is currently checked (determining the selected VersionLabel).
 // The results are collected into a Versions map, where keys are version identifiers and values are the selected flags.
-// If the expected table is not found, the function returns an error.
+// If the expected table is not found, the page is classified and its typed error is returned,
+// e.g. errors.ErrPortalSessionExpired for the login form.
 func ParseVersions(content string) (types.Versions, error) {
 	var err error
 
@@ -41,7 +42,7 @@ func ParseVersions(content string) (types.Versions, error) {
 
 	table := versionsTable(doc, "data-table mt-3 mb-3")
 	if table == nil {
-		return nil, errors.New("table not found")
+		return nil, PageError(0, content, "versions table")
 	}
 
 	for tr := table.FirstChild; tr != nil; tr = tr.NextSibling {
@@ -107,42 +108,20 @@ func ParseSessionId(content string) string {
 // UploadResult processes the HTML content returned from the upload request
 // to check for error messages.
 //
-// The function parses the HTML content and searches for a <p> element with
-// a specific CSS class (`paragraph-15 color-red m-0`), which indicates
-// an error message. If such an element is found, the error message is
-// extracted and returned as an error.
+// The page is classified (see Classify). The login form, the access denied and maintenance pages,
+// and pages with an error message (e.g. the `paragraph-15 color-red m-0` paragraph)
+// are reported as typed errors carrying the message of the portal. Any other page is taken
+// as a success; the result of an upload is confirmed separately by reading the versions.
 //
 // Parameters:
 //   - htmlContent: The HTML response body to be parsed for error messages.
 //
 // Returns:
-//   - An error if an error message is found in the HTML content or nil if
-//     no errors are present.
+//   - A *errors.PortalError if the page reports a failure or nil if no errors are present.
 func UploadResult(content string) error {
-	var err error
-
-	doc, _ := html.Parse(strings.NewReader(content))
-
-	var f func(*html.Node)
-	f = func(n *html.Node) {
-		if n.Type == html.ElementNode && n.Data == "p" {
-			hasClass := false
-			for _, attr := range n.Attr {
-				if attr.Key == class && attr.Val == "paragraph-15 color-red m-0" {
-					hasClass = true
-					break
-				}
-			}
-			if hasClass && n.FirstChild != nil {
-				err = errors.New(strings.TrimSpace(n.FirstChild.Data))
-				return
-			}
-		}
-		for c := n.FirstChild; c != nil; c = c.NextSibling {
-			f(c)
-		}
+	if Classify(content).Kind == PageUnknown {
+		return nil
 	}
-	f(doc)
 
-	return err
+	return PageError(0, content, "")
 }
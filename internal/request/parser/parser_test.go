@@ -5,6 +5,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	errors2 "github.com/pixel365/bx/internal/errors"
 )
 
 func Test_UploadResult(t *testing.T) {
@@ -103,8 +105,8 @@ func TestParseVersions_table_not_found(t *testing.T) {
 </table>`
 
 	_, err := ParseVersions(content)
-	require.Error(t, err)
-	assert.Equal(t, err.Error(), "table not found")
+	require.ErrorIs(t, err, errors2.ErrPortalUnexpectedPage)
+	assert.Equal(t, "unexpected portal page: versions table not found", err.Error())
 }
 
 func TestParseVersions_invalid_content(t *testing.T) {
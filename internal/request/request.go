@@ -1,11 +1,9 @@
 package request
 
 import (
-	"bytes"
 	"context"
-	"errors"
+	"fmt"
 	"io"
-	"mime/multipart"
 	"net/http"
 	"net/url"
 	"os"
@@ -24,15 +22,20 @@ import (
 var getSessionFunc = getSession
 
 // Authenticate performs user authentication by sending login credentials
-// to the Bitrix Partner Portal.
+// to the Bitrix Partner Portal of the module.
 //
-// It sends a POST request with the login and password as form data and checks
+// It sends a POST request with the module account and the password as form data and checks
 // the response for authentication success by verifying the presence of a
 // "BITRIX_SM_LOGIN" cookie.
 //
 // Returns a slice of cookies if authentication is successful or an error if
 // authentication fails or an issue occurs during the request.
-func Authenticate(client client.HTTPClient, login, password string) ([]*http.Cookie, error) {
+func Authenticate(client client.HTTPClient, module *module.Module, password string) ([]*http.Cookie, error) {
+	if module == nil {
+		return nil, errors2.ErrNilModule
+	}
+
+	login := module.Account
 	if login == "" {
 		return nil, errors2.ErrEmptyLogin
 	}
@@ -49,9 +52,15 @@ func Authenticate(client client.HTTPClient, login, password string) ([]*http.Coo
 		"USER_REMEMBER": {"Y"},
 	}
 
-	req, err := http.NewRequest(
+	u, err := endpoint(module, "/personal/", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(
+		types.WithOperation(context.Background(), types.OperationLogin),
 		http.MethodPost,
-		"https://partners.1c-bitrix.ru/personal/",
+		u.String(),
 		strings.NewReader(body.Encode()),
 	)
 	if err != nil {
@@ -69,7 +78,8 @@ func Authenticate(client client.HTTPClient, login, password string) ([]*http.Coo
 	defer helpers.Cleanup(resp.Body, nil)
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, errors.New(resp.Status)
+		respBody, _ := io.ReadAll(resp.Body)
+		return nil, parser.PageError(resp.StatusCode, string(respBody), "")
 	}
 
 	var cookies []*http.Cookie
@@ -101,12 +111,16 @@ func Versions(
 		return nil, errors2.ErrNilCookie
 	}
 
-	session := sessionId(client, module, cookies)
-	if session == "" {
-		return nil, errors2.ErrEmptySession
+	if _, err := sessionId(client, module, cookies); err != nil {
+		return nil, err
+	}
+
+	u, err := endpoint(module, "/personal/modules/update.php", url.Values{"ID": {module.Name}})
+	if err != nil {
+		return nil, err
 	}
 
-	u, _ := url.Parse("https://partners.1c-bitrix.ru/personal/modules/update.php?ID=" + module.Name)
+	ctx = types.WithOperation(ctx, types.OperationVersions)
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
 	if err != nil {
 		return nil, err
@@ -122,12 +136,12 @@ func Versions(
 
 	defer helpers.Cleanup(resp.Body, nil)
 
-	respBody, err := io.ReadAll(resp.Body)
+	respBody, err := readPage(resp)
 	if err != nil {
 		return nil, err
 	}
 
-	return parser.ParseVersions(string(respBody))
+	return parser.ParseVersions(respBody)
 }
 
 func ChangeLabels(
@@ -144,9 +158,9 @@ func ChangeLabels(
 		return errors2.ErrNilCookie
 	}
 
-	session := sessionId(client, module, cookies)
-	if session == "" {
-		return errors2.ErrEmptySession
+	session, err := sessionId(client, module, cookies)
+	if err != nil {
+		return err
 	}
 
 	body := url.Values{
@@ -158,8 +172,17 @@ func ChangeLabels(
 		body.Set(version, string(label))
 	}
 
-	u, _ := url.Parse("https://partners.1c-bitrix.ru/personal/modules/update.php")
-	req, err := http.NewRequest(http.MethodPost, u.String(), strings.NewReader(body.Encode()))
+	u, err := endpoint(module, "/personal/modules/update.php", nil)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(
+		types.WithOperation(context.Background(), types.OperationLabels),
+		http.MethodPost,
+		u.String(),
+		strings.NewReader(body.Encode()),
+	)
 	if err != nil {
 		return err
 	}
@@ -176,25 +199,29 @@ func ChangeLabels(
 
 	defer helpers.Cleanup(resp.Body, nil)
 
-	respBody, err := io.ReadAll(resp.Body)
+	respBody, err := readPage(resp)
 	if err != nil {
 		return err
 	}
 
-	return parser.UploadResult(string(respBody))
+	return parser.UploadResult(respBody)
 }
 
+// ProgressFunc receives the number of bytes of a release archive sent so far and its total size.
+type ProgressFunc func(sent, total int64)
+
 // UploadZIP uploads a ZIP file containing the module's data to the Bitrix Partner Portal.
 //
 // This function first validates that the module and cookies are provided. It then retrieves the
-// session ID and prepares the ZIP file for upload. The request is sent with the necessary form
-// data, including the session ID, module name, and the ZIP file. The response body is checked
+// session ID and streams the multipart form with the session ID, the module name and the ZIP file
+// to the portal, without loading the archive into memory. The response body is checked
 // for the result of the upload operation.
 //
 // Parameters:
 //   - ctx: context.Context.
 //   - module: The module whose ZIP file is being uploaded.
 //   - cookies: The cookies containing the authentication information.
+//   - progress: Called as the archive is sent; may be nil.
 //
 // Returns:
 //   - An error if any step fails (e.g., missing session, file errors, upload failure).
@@ -203,6 +230,7 @@ func UploadZIP(
 	client client.HTTPClient,
 	module *module.Module,
 	cookies []*http.Cookie,
+	progress ProgressFunc,
 ) error {
 	if module == nil {
 		return errors2.ErrNilModule
@@ -212,9 +240,9 @@ func UploadZIP(
 		return errors2.ErrNilCookie
 	}
 
-	session := sessionId(client, module, cookies)
-	if session == "" {
-		return errors2.ErrEmptySession
+	session, err := sessionId(client, module, cookies)
+	if err != nil {
+		return err
 	}
 
 	path, err := module.ZipPath()
@@ -222,57 +250,78 @@ func UploadZIP(
 		return err
 	}
 
-	path = filepath.Clean(path)
-	file, err := os.Open(path)
+	file, err := os.Open(filepath.Clean(path))
 	if err != nil {
-		panic(err)
+		return fmt.Errorf("failed to open release archive: %w", err)
 	}
 	defer helpers.Cleanup(file, nil)
 
-	var requestBody bytes.Buffer
-	writer := multipart.NewWriter(&requestBody)
-
-	fileWriter, err := writer.CreateFormFile("update", module.Version+".zip")
+	info, err := file.Stat()
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to read release archive: %w", err)
 	}
 
-	if _, err := io.Copy(fileWriter, file); err != nil {
-		return err
+	fields := [][2]string{{"sessid", session}, {"ID", module.Name}, {"submit", "Y"}}
+	form := &uploadForm{
+		file:     file,
+		fileName: module.Version + ".zip",
+		fields:   fields,
+		size:     info.Size(),
+		progress: progress,
 	}
 
-	_ = writer.WriteField("sessid", session)
-	_ = writer.WriteField("ID", module.Name)
-	_ = writer.WriteField("submit", "Y")
-
-	err = writer.Close()
+	u, err := endpoint(module, "/personal/modules/deploy.php", nil)
 	if err != nil {
 		return err
 	}
 
-	u, _ := url.Parse("https://partners.1c-bitrix.ru/personal/modules/deploy.php")
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u.String(), &requestBody)
+	body, contentType, length, wait := form.stream()
+	defer func() {
+		_ = body.Close()
+		_ = wait()
+	}()
+
+	ctx = types.WithOperation(ctx, types.OperationUpload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u.String(), body)
 	if err != nil {
 		return err
 	}
-	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Set("Content-Type", contentType)
+	req.ContentLength = length
 
 	client.SetCookies(u, cookies)
 
 	//nolint:bodyclose
 	resp, err := client.Do(req)
 	if err != nil {
+		_ = body.Close()
+		if writeErr := wait(); writeErr != nil {
+			return writeErr
+		}
 		return err
 	}
 
 	defer helpers.Cleanup(resp.Body, nil)
 
-	respBody, err := io.ReadAll(resp.Body)
+	respBody, err := readPage(resp)
 	if err != nil {
 		return err
 	}
 
-	return parser.UploadResult(string(respBody))
+	return parser.UploadResult(respBody)
+}
+
+// ValidateSession checks that the cookies still give access to the module on the portal.
+//
+// The edit page of the module is requested, and the session is valid if it contains a session ID.
+//
+// Returns:
+//   - nil if the session is valid.
+//   - errors.ErrEmptySession if the cookies are rejected or the page cannot be fetched,
+//     joined with the typed error of the page returned instead (see parser.PageError).
+func ValidateSession(client client.HTTPClient, module *module.Module, cookies []*http.Cookie) error {
+	_, err := sessionId(client, module, cookies)
+	return err
 }
 
 // sessionId retrieves the session ID for a given module from the Bitrix Partner Portal.
@@ -286,20 +335,40 @@ func UploadZIP(
 //   - cookies: The cookies containing the authentication information.
 //
 // Returns:
-//   - The session ID as a string if found, otherwise returns an empty string.
-func sessionId(client client.HTTPClient, module *module.Module, cookies []*http.Cookie) string {
-	return getSessionFunc(client, module, cookies)
+//   - The session ID as a string if found.
+//   - errors.ErrEmptySession otherwise. If the portal returned a page, the error also wraps
+//     its typed error, e.g. errors.ErrPortalSessionExpired for the login form.
+func sessionId(client client.HTTPClient, module *module.Module, cookies []*http.Cookie) (string, error) {
+	session, err := getSessionFunc(client, module, cookies)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", errors2.ErrEmptySession, err)
+	}
+
+	if session == "" {
+		return "", errors2.ErrEmptySession
+	}
+
+	return session, nil
 }
 
-func getSession(client client.HTTPClient, module *module.Module, cookies []*http.Cookie) string {
+func getSession(client client.HTTPClient, module *module.Module, cookies []*http.Cookie) (string, error) {
 	if module == nil || len(cookies) == 0 || module.Name == "" {
-		return ""
+		return "", nil
+	}
+
+	u, err := endpoint(module, "/personal/modules/edit.php", url.Values{"ID": {module.Name}})
+	if err != nil {
+		return "", err
 	}
 
-	u, _ := url.Parse("https://partners.1c-bitrix.ru/personal/modules/edit.php?ID=" + module.Name)
-	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	req, err := http.NewRequestWithContext(
+		types.WithOperation(context.Background(), types.OperationSession),
+		http.MethodGet,
+		u.String(),
+		nil,
+	)
 	if err != nil {
-		return ""
+		return "", err
 	}
 
 	client.SetCookies(u, cookies)
@@ -307,15 +376,55 @@ func getSession(client client.HTTPClient, module *module.Module, cookies []*http
 	//nolint:bodyclose
 	resp, err := client.Do(req)
 	if err != nil {
-		return ""
+		return "", err
 	}
 
 	defer helpers.Cleanup(resp.Body, nil)
 
-	respBody, err := io.ReadAll(resp.Body)
+	respBody, err := readPage(resp)
+	if err != nil {
+		return "", err
+	}
+
+	session := parser.ParseSessionId(respBody)
+	if session == "" {
+		return "", parser.PageError(0, respBody, "session ID")
+	}
+
+	return session, nil
+}
+
+// readPage reads the body of a portal response.
+// A response with an error status is returned as the typed error of its page (see parser.PageError).
+func readPage(resp *http.Response) (string, error) {
+	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return ""
+		return "", err
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return "", parser.PageError(resp.StatusCode, string(body), "")
+	}
+
+	return string(body), nil
+}
+
+// endpoint builds the URL of a portal page from the portal base URL of the module.
+//
+// Parameters:
+//   - module: The module whose portal is used (see module.PortalURL).
+//   - path: The absolute path of the page, e.g. `/personal/modules/edit.php`.
+//   - query: Optional query parameters.
+func endpoint(module *module.Module, path string, query url.Values) (*url.URL, error) {
+	base, err := module.PortalURL()
+	if err != nil {
+		return nil, err
+	}
+
+	u := base.JoinPath(path)
+	if len(query) > 0 {
+		u.RawQuery = query.Encode()
 	}
 
-	return parser.ParseSessionId(string(respBody))
+	return u, nil
 }
@@ -13,6 +13,7 @@ import (
 	"github.com/pixel365/bx/internal/types"
 
 	client2 "github.com/pixel365/bx/internal/client"
+	errors2 "github.com/pixel365/bx/internal/errors"
 
 	module2 "github.com/pixel365/bx/internal/module"
 )
@@ -36,7 +37,7 @@ func Test_Authorization(t *testing.T) {
 	}
 
 	type args struct {
-		login    string
+		module   *module2.Module
 		password string
 	}
 	tests := []struct {
@@ -46,15 +47,16 @@ func Test_Authorization(t *testing.T) {
 		want    []*http.Cookie
 		wantErr bool
 	}{
-		{"empty login", client, args{"", "1234556"}, nil, true},
-		{"empty password", client, args{"abc", ""}, nil, true},
-		{"success", client, args{"testuser", "123456"}, want, false},
+		{"nil module", client, args{nil, "1234556"}, nil, true},
+		{"empty login", client, args{&module2.Module{}, "1234556"}, nil, true},
+		{"empty password", client, args{&module2.Module{Account: "abc"}, ""}, nil, true},
+		{"success", client, args{&module2.Module{Account: "testuser"}, "123456"}, want, false},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 
-			got, err := Authenticate(tt.client, tt.args.login, tt.args.password)
+			got, err := Authenticate(tt.client, tt.args.module, tt.args.password)
 			if tt.wantErr {
 				require.Error(t, err)
 			} else {
@@ -115,7 +117,7 @@ func Test_UploadZIP(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 
-			err := UploadZIP(ctx, tt.client, tt.args.module, tt.args.cookies)
+			err := UploadZIP(ctx, tt.client, tt.args.module, tt.args.cookies, nil)
 			if tt.wantErr {
 				require.Error(t, err)
 			} else {
@@ -131,8 +133,8 @@ func Test_UploadZIP_InvalidZipPath(t *testing.T) {
 	ctx := context.Background()
 	origGetSession := getSessionFunc
 	defer func() { getSessionFunc = origGetSession }()
-	getSessionFunc = func(c client2.HTTPClient, module *module2.Module, cookies []*http.Cookie) string {
-		return "fake-session-id"
+	getSessionFunc = func(c client2.HTTPClient, module *module2.Module, cookies []*http.Cookie) (string, error) {
+		return "fake-session-id", nil
 	}
 
 	client := &client2.MockHttpClient{DoFunc: func(req *http.Request) (*http.Response, error) {
@@ -151,6 +153,7 @@ func Test_UploadZIP_InvalidZipPath(t *testing.T) {
 		client,
 		&module2.Module{Name: "fake-name"},
 		[]*http.Cookie{{Name: "foo", Value: "bar"}},
+		nil,
 	)
 	require.Error(t, err)
 }
@@ -194,12 +197,52 @@ func Test_SessionId(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 
-			got := sessionId(tt.client, tt.args.module, tt.args.cookies)
+			got, err := sessionId(tt.client, tt.args.module, tt.args.cookies)
+			require.NoError(t, err)
 			assert.Equal(t, tt.want, got)
 		})
 	}
 }
 
+func Test_ValidateSession(t *testing.T) {
+	t.Parallel()
+
+	page := func(body string) client2.HTTPClient {
+		return &client2.MockHttpClient{DoFunc: func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader(body)),
+			}, nil
+		}}
+	}
+
+	cookies := []*http.Cookie{{Name: "BITRIX_SM_LOGIN", Value: "testuser"}}
+	module := &module2.Module{Name: "test"}
+
+	tests := []struct {
+		client  client2.HTTPClient
+		name    string
+		cookies []*http.Cookie
+		wantErr bool
+	}{
+		{page(`<input type="hidden" name="sessid" id="sessid" value="123456" />`), "valid", cookies, false},
+		{page(`<form action="/auth/"></form>`), "login page", cookies, true},
+		{page(""), "no cookies", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := ValidateSession(tt.client, module, tt.cookies)
+			if tt.wantErr {
+				require.ErrorIs(t, err, errors2.ErrEmptySession)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
 func TestVersions(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,140 @@
+package request
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"sync"
+)
+
+// uploadForm is the multipart form of a release upload: the archive in the `update` part,
+// followed by the text fields.
+type uploadForm struct {
+	file     io.Reader
+	progress ProgressFunc
+	fileName string
+	fields   [][2]string
+	size     int64
+}
+
+// stream returns a reader producing the form, written by a goroutine as the reader is consumed.
+//
+// Returns:
+//   - body: The form. Closing it stops the writer.
+//   - contentType: The Content-Type header with the boundary.
+//   - length: The exact size of the form, or -1 if it cannot be computed.
+//   - wait: Waits for the writer to finish and returns its error. Safe to call more than once.
+func (f *uploadForm) stream() (io.ReadCloser, string, int64, func() error) {
+	pr, pw := io.Pipe()
+	writer := multipart.NewWriter(pw)
+
+	length, err := f.length(writer.Boundary())
+	if err != nil {
+		length = -1
+	}
+
+	var writeErr error
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		writeErr = f.write(writer)
+		_ = pw.CloseWithError(writeErr)
+	}()
+
+	var once sync.Once
+	wait := func() error {
+		once.Do(wg.Wait)
+		if errors.Is(writeErr, io.ErrClosedPipe) {
+			return nil
+		}
+		return writeErr
+	}
+
+	return pr, writer.FormDataContentType(), length, wait
+}
+
+func (f *uploadForm) write(writer *multipart.Writer) error {
+	part, err := writer.CreateFormFile("update", f.fileName)
+	if err != nil {
+		return err
+	}
+
+	reader := io.Reader(f.file)
+	if f.progress != nil {
+		f.progress(0, f.size)
+		reader = &progressReader{reader: f.file, total: f.size, progress: f.progress}
+	}
+
+	n, err := io.Copy(part, reader)
+	if err != nil {
+		return fmt.Errorf("failed to read release archive: %w", err)
+	}
+
+	if n != f.size {
+		return fmt.Errorf("release archive changed during upload: read %d of %d bytes", n, f.size)
+	}
+
+	for _, field := range f.fields {
+		if err := writer.WriteField(field[0], field[1]); err != nil {
+			return err
+		}
+	}
+
+	return writer.Close()
+}
+
+// length computes the size of the form by writing it with an empty archive
+// and the same boundary, then adding the archive size.
+func (f *uploadForm) length(boundary string) (int64, error) {
+	var counter countingWriter
+
+	writer := multipart.NewWriter(&counter)
+	if err := writer.SetBoundary(boundary); err != nil {
+		return 0, err
+	}
+
+	if _, err := writer.CreateFormFile("update", f.fileName); err != nil {
+		return 0, err
+	}
+
+	for _, field := range f.fields {
+		if err := writer.WriteField(field[0], field[1]); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return 0, err
+	}
+
+	return counter.n + f.size, nil
+}
+
+type countingWriter struct {
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.n += int64(len(p))
+	return len(p), nil
+}
+
+type progressReader struct {
+	reader   io.Reader
+	progress ProgressFunc
+	total    int64
+	sent     int64
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	if n > 0 {
+		r.sent += int64(n)
+		r.progress(r.sent, r.total)
+	}
+
+	return n, err
+}
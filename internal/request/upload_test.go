@@ -0,0 +1,178 @@
+package request
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	client2 "github.com/pixel365/bx/internal/client"
+	module2 "github.com/pixel365/bx/internal/module"
+	"github.com/pixel365/bx/internal/types"
+)
+
+func Test_uploadForm_stream(t *testing.T) {
+	t.Parallel()
+
+	content := bytes.Repeat([]byte("0123456789"), 10_000)
+
+	var reports [][2]int64
+	form := &uploadForm{
+		file:     bytes.NewReader(content),
+		fileName: "1.0.0.zip",
+		fields:   [][2]string{{"sessid", "abc"}, {"ID", "vendor.module"}, {"submit", "Y"}},
+		size:     int64(len(content)),
+		progress: func(sent, total int64) { reports = append(reports, [2]int64{sent, total}) },
+	}
+
+	body, contentType, length, wait := form.stream()
+	data, err := io.ReadAll(body)
+	require.NoError(t, err)
+	require.NoError(t, wait())
+	require.NoError(t, wait())
+
+	assert.Equal(t, int64(len(data)), length, "the computed length matches the body")
+
+	_, params, err := mime.ParseMediaType(contentType)
+	require.NoError(t, err)
+
+	reader := multipart.NewReader(bytes.NewReader(data), params["boundary"])
+	parsed, err := reader.ReadForm(1 << 20)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"abc"}, parsed.Value["sessid"])
+	assert.Equal(t, []string{"vendor.module"}, parsed.Value["ID"])
+	require.Len(t, parsed.File["update"], 1)
+	assert.Equal(t, "1.0.0.zip", parsed.File["update"][0].Filename)
+	assert.Equal(t, int64(len(content)), parsed.File["update"][0].Size)
+
+	require.NotEmpty(t, reports)
+	assert.Equal(t, [2]int64{0, int64(len(content))}, reports[0])
+	assert.Equal(t, [2]int64{int64(len(content)), int64(len(content))}, reports[len(reports)-1])
+	for i := 1; i < len(reports); i++ {
+		assert.GreaterOrEqual(t, reports[i][0], reports[i-1][0])
+	}
+}
+
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) {
+	return 0, errors.New("disk error")
+}
+
+func Test_uploadForm_stream_readError(t *testing.T) {
+	t.Parallel()
+
+	form := &uploadForm{file: failingReader{}, fileName: "1.0.0.zip", size: 10}
+
+	body, _, _, wait := form.stream()
+	_, err := io.ReadAll(body)
+	require.ErrorContains(t, err, "disk error")
+	require.ErrorContains(t, wait(), "disk error")
+}
+
+func Test_uploadForm_stream_truncated(t *testing.T) {
+	t.Parallel()
+
+	form := &uploadForm{file: strings.NewReader("short"), fileName: "1.0.0.zip", size: 10}
+
+	body, _, _, wait := form.stream()
+	_, err := io.ReadAll(body)
+	require.Error(t, err)
+	require.ErrorContains(t, wait(), "changed during upload")
+}
+
+func Test_uploadForm_stream_notConsumed(t *testing.T) {
+	t.Parallel()
+
+	form := &uploadForm{file: bytes.NewReader(make([]byte, 1<<20)), fileName: "1.0.0.zip", size: 1 << 20}
+
+	body, _, _, wait := form.stream()
+	require.NoError(t, body.Close())
+	require.NoError(t, wait(), "closing the body stops the writer")
+}
+
+func Test_UploadZIP_streaming(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "1.0.0.zip")
+
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	w := zip.NewWriter(f)
+	entry, err := w.Create("1.0.0/install/index.php")
+	require.NoError(t, err)
+	_, err = entry.Write(bytes.Repeat([]byte("<?php // padding\n"), 5000))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	require.NoError(t, f.Close())
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+
+	origGetSession := getSessionFunc
+	defer func() { getSessionFunc = origGetSession }()
+	getSessionFunc = func(c client2.HTTPClient, module *module2.Module, cookies []*http.Cookie) (string, error) {
+		return "fake-session-id", nil
+	}
+
+	var received int64
+	var contentLength int64
+	var op types.Operation
+	client := &client2.MockHttpClient{DoFunc: func(req *http.Request) (*http.Response, error) {
+		contentLength = req.ContentLength
+		op = types.OperationFromContext(req.Context())
+		received, _ = io.Copy(io.Discard, req.Body)
+
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(""))}, nil
+	}}
+
+	var sent, total int64
+	err = UploadZIP(
+		context.Background(),
+		client,
+		&module2.Module{Name: "vendor.module", Version: "1.0.0", BuildDirectory: dir},
+		[]*http.Cookie{{Name: "foo", Value: "bar"}},
+		func(s, t int64) { sent, total = s, t },
+	)
+	require.NoError(t, err)
+
+	assert.Equal(t, received, contentLength)
+	assert.Equal(t, types.OperationUpload, op)
+	assert.Equal(t, info.Size(), sent)
+	assert.Equal(t, info.Size(), total)
+}
+
+func Test_UploadZIP_clientError(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "1.0.0.zip"), make([]byte, 1<<20), 0600))
+
+	origGetSession := getSessionFunc
+	defer func() { getSessionFunc = origGetSession }()
+	getSessionFunc = func(c client2.HTTPClient, module *module2.Module, cookies []*http.Cookie) (string, error) {
+		return "fake-session-id", nil
+	}
+
+	client := &client2.MockHttpClient{DoFunc: func(req *http.Request) (*http.Response, error) {
+		return nil, errors.New("connection refused")
+	}}
+
+	err := UploadZIP(
+		context.Background(),
+		client,
+		&module2.Module{Name: "vendor.module", Version: "1.0.0", BuildDirectory: dir},
+		[]*http.Cookie{{Name: "foo", Value: "bar"}},
+		nil,
+	)
+	require.ErrorContains(t, err, "connection refused")
+}
@@ -0,0 +1,373 @@
+// Package secret encrypts values of module configurations with a local key file.
+//
+// An encrypted value is a YAML scalar tagged `!secret`, e.g. `token: !secret v1:...`.
+// Values are sealed with AES-256-GCM under a random 32-byte key stored in a key file,
+// `bx/secret.key` in the user config directory by default (see DefaultKeyPath).
+// The key file must be readable by its owner only. Without the key file the module
+// configuration cannot be read, but it can be safely committed to a repository.
+package secret
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	errors2 "github.com/pixel365/bx/internal/errors"
+)
+
+const (
+	// Tag is the YAML tag of an encrypted value.
+	Tag = "!secret"
+	// KeyEnv is the environment variable that overrides the path of the key file.
+	KeyEnv = "BX_SECRET_KEY_FILE"
+
+	keySize = 32
+	prefix  = "v1:"
+)
+
+var userConfigDirFunc = os.UserConfigDir
+
+// Key is the key values are sealed with.
+type Key []byte
+
+// Value is a decrypted secret of a module configuration.
+//
+// Fields:
+//   - Plain:  The decrypted value.
+//   - Sealed: The encrypted value as written in the configuration.
+//   - Path:   The keys and the sequence indexes leading to the value in the document,
+//     e.g. ["callbacks", "0", "post", "parameters", "1"].
+type Value struct {
+	Plain  string
+	Sealed string
+	Path   []string
+}
+
+// DefaultKeyPath returns the path of the key file: the value of BX_SECRET_KEY_FILE if set,
+// otherwise `bx/secret.key` in the user config directory, e.g. `~/.config/bx/secret.key` on Linux.
+func DefaultKeyPath() (string, error) {
+	if path := strings.TrimSpace(os.Getenv(KeyEnv)); path != "" {
+		return path, nil
+	}
+
+	dir, err := userConfigDirFunc()
+	if err != nil {
+		return "", fmt.Errorf("secret key: %w", err)
+	}
+
+	return filepath.Join(dir, "bx", "secret.key"), nil
+}
+
+// DefaultKey loads the key from DefaultKeyPath.
+func DefaultKey() (Key, error) {
+	path, err := DefaultKeyPath()
+	if err != nil {
+		return nil, err
+	}
+
+	return LoadKey(path)
+}
+
+// GenerateKey returns a new random key.
+func GenerateKey() (Key, error) {
+	key := make(Key, keySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// LoadKey reads a base64 encoded key from the file.
+//
+// Returns:
+//   - Key: The key.
+//   - error: errors.ErrSecretKeyNotFound if the file does not exist, errors.ErrInsecureKeyFile if
+//     it is accessible by the group or other users (not checked on Windows), or errors.ErrSecretKeyInvalid
+//     if it does not contain a key.
+func LoadKey(path string) (Key, error) {
+	path = filepath.Clean(path)
+
+	info, err := os.Stat(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("%w: %s", errors2.ErrSecretKeyNotFound, path)
+		}
+		return nil, fmt.Errorf("secret key: %w", err)
+	}
+
+	if perm := info.Mode().Perm(); runtime.GOOS != "windows" && perm&0o077 != 0 {
+		return nil, fmt.Errorf("%w: %s has mode %04o, expected 0600 or stricter",
+			errors2.ErrInsecureKeyFile, path, perm)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("secret key: %w", err)
+	}
+
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil || len(key) != keySize {
+		return nil, fmt.Errorf("%w: %s", errors2.ErrSecretKeyInvalid, path)
+	}
+
+	return key, nil
+}
+
+// WriteKey writes the key to the file with 0600 permissions, creating its directory with 0700.
+// An existing file is replaced.
+func WriteKey(path string, key Key) error {
+	if len(key) != keySize {
+		return errors2.ErrSecretKeyInvalid
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("secret key: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("secret key: %w", err)
+	}
+
+	name := tmp.Name()
+	defer func() { _ = os.Remove(name) }()
+
+	if err := tmp.Chmod(0600); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("secret key: %w", err)
+	}
+
+	if _, err := tmp.WriteString(base64.StdEncoding.EncodeToString(key) + "\n"); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("secret key: %w", err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("secret key: %w", err)
+	}
+
+	if err := os.Rename(name, path); err != nil {
+		return fmt.Errorf("secret key: %w", err)
+	}
+
+	return nil
+}
+
+// Seal encrypts the value. The result is `v1:` followed by base64 of nonce | ciphertext,
+// so the same value gives a different result every time.
+func Seal(key Key, plain string) (string, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	sealed := aead.Seal(nonce, nonce, []byte(plain), nil)
+
+	return prefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Open decrypts a value sealed by Seal.
+// It returns errors.ErrSecretInvalid if the value is malformed or was sealed with another key.
+func Open(key Key, sealed string) (string, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return "", err
+	}
+
+	encoded, ok := strings.CutPrefix(strings.TrimSpace(sealed), prefix)
+	if !ok {
+		return "", fmt.Errorf("%w: unknown format", errors2.ErrSecretInvalid)
+	}
+
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(data) < aead.NonceSize() {
+		return "", fmt.Errorf("%w: malformed value", errors2.ErrSecretInvalid)
+	}
+
+	plain, err := aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], nil)
+	if err != nil {
+		return "", fmt.Errorf("%w: wrong key or corrupted value", errors2.ErrSecretInvalid)
+	}
+
+	return string(plain), nil
+}
+
+func newAEAD(key Key) (cipher.AEAD, error) {
+	if len(key) != keySize {
+		return nil, errors2.ErrSecretKeyInvalid
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// Decrypt replaces the `!secret` scalars of the YAML document with their decrypted values.
+//
+// The key is loaded by keyFunc on the first encrypted value, so a document without secrets
+// does not need the key file.
+//
+// Parameters:
+//   - node: The YAML document, changed in place.
+//   - keyFunc: Loads the key, e.g. DefaultKey.
+//
+// Returns:
+//   - []Value: The decrypted values with their sealed form, in document order.
+//   - error: If the key cannot be loaded or a value cannot be decrypted. The error names the line.
+func Decrypt(node *yaml.Node, keyFunc func() (Key, error)) ([]Value, error) {
+	var key Key
+	var values []Value
+
+	err := walkPath(node, nil, func(n *yaml.Node, path []string) error {
+		if n.Kind != yaml.ScalarNode || n.Tag != Tag {
+			return nil
+		}
+
+		if key == nil {
+			var err error
+			if key, err = keyFunc(); err != nil {
+				return err
+			}
+		}
+
+		plain, err := Open(key, n.Value)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", n.Line, err)
+		}
+
+		values = append(values, Value{Plain: plain, Sealed: n.Value, Path: slices.Clone(path)})
+		n.Tag = "!!str"
+		n.Value = plain
+		n.Style = 0
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
+
+// Encrypt replaces the scalars of the YAML document at the paths of the decrypted values
+// with their sealed form tagged `!secret`. It is the reverse of Decrypt, used so that
+// decrypted values are never written back in plain text. Other fields are not changed,
+// even if they are equal to a decrypted value; a scalar at the path of a value is sealed
+// only if it still holds the decrypted value.
+func Encrypt(node *yaml.Node, values []Value) {
+	if len(values) == 0 {
+		return
+	}
+
+	byPath := make(map[string]Value, len(values))
+	for _, v := range values {
+		byPath[pathKey(v.Path)] = v
+	}
+
+	_ = walkPath(node, nil, func(n *yaml.Node, path []string) error {
+		if n.Kind != yaml.ScalarNode {
+			return nil
+		}
+
+		if v, ok := byPath[pathKey(path)]; ok && n.Value == v.Plain {
+			n.Tag = Tag
+			n.Value = v.Sealed
+			n.Style = 0
+		}
+
+		return nil
+	})
+}
+
+// pathKey joins the path of a value into a map key.
+func pathKey(path []string) string {
+	return strings.Join(path, "\x00")
+}
+
+// Sealed returns the `!secret` scalars of the YAML document without decrypting them.
+func Sealed(node *yaml.Node) []*yaml.Node {
+	var nodes []*yaml.Node
+
+	_ = walk(node, func(n *yaml.Node) error {
+		if n.Kind == yaml.ScalarNode && n.Tag == Tag {
+			nodes = append(nodes, n)
+		}
+		return nil
+	})
+
+	return nodes
+}
+
+// walkPath calls fn for the node and its descendants, except the keys of mappings, with the path
+// of every node: the keys of the mappings and the indexes of the sequences leading to it.
+func walkPath(node *yaml.Node, path []string, fn func(*yaml.Node, []string) error) error {
+	if node == nil {
+		return nil
+	}
+
+	if err := fn(node, path); err != nil {
+		return err
+	}
+
+	for i, child := range node.Content {
+		childPath := path
+		switch node.Kind {
+		case yaml.MappingNode:
+			if i%2 == 0 {
+				continue
+			}
+			childPath = append(path[:len(path):len(path)], node.Content[i-1].Value)
+		case yaml.SequenceNode:
+			childPath = append(path[:len(path):len(path)], strconv.Itoa(i))
+		}
+
+		if err := walkPath(child, childPath, fn); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// walk calls fn for the node and its descendants, except the keys of mappings.
+func walk(node *yaml.Node, fn func(*yaml.Node) error) error {
+	if node == nil {
+		return nil
+	}
+
+	if err := fn(node); err != nil {
+		return err
+	}
+
+	for i, child := range node.Content {
+		if node.Kind == yaml.MappingNode && i%2 == 0 {
+			continue
+		}
+		if err := walk(child, fn); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,219 @@
+package secret
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/pixel365/bx/internal/errors"
+)
+
+func newKey(t *testing.T) Key {
+	t.Helper()
+
+	key, err := GenerateKey()
+	require.NoError(t, err)
+
+	return key
+}
+
+func TestSeal_Open(t *testing.T) {
+	t.Parallel()
+
+	key := newKey(t)
+
+	for _, plain := range []string{"token", "", "пароль с пробелами", strings.Repeat("x", 4096)} {
+		sealed, err := Seal(key, plain)
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(sealed, "v1:"))
+
+		if plain != "" {
+			assert.NotContains(t, sealed, plain)
+		}
+
+		got, err := Open(key, sealed)
+		require.NoError(t, err)
+		assert.Equal(t, plain, got)
+	}
+
+	a, _ := Seal(key, "token")
+	b, _ := Seal(key, "token")
+	assert.NotEqual(t, a, b, "every seal uses a new nonce")
+}
+
+func TestOpen_errors(t *testing.T) {
+	t.Parallel()
+
+	key := newKey(t)
+	sealed, err := Seal(key, "token")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		key    Key
+		sealed string
+	}{
+		{"other key", newKey(t), sealed},
+		{"no prefix", key, strings.TrimPrefix(sealed, "v1:")},
+		{"not base64", key, "v1:???"},
+		{"too short", key, "v1:AAAA"},
+		{"tampered", key, sealed[:len(sealed)-4] + "AAA="},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := Open(tt.key, tt.sealed)
+			require.ErrorIs(t, err, errors.ErrSecretInvalid)
+		})
+	}
+
+	_, err = Open(Key("short"), sealed)
+	require.ErrorIs(t, err, errors.ErrSecretKeyInvalid)
+}
+
+func TestWriteKey_LoadKey(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "bx", "secret.key")
+
+	_, err := LoadKey(path)
+	require.ErrorIs(t, err, errors.ErrSecretKeyNotFound)
+
+	key := newKey(t)
+	require.NoError(t, WriteKey(path, key))
+
+	got, err := LoadKey(path)
+	require.NoError(t, err)
+	assert.Equal(t, key, got)
+
+	require.ErrorIs(t, WriteKey(path, Key("short")), errors.ErrSecretKeyInvalid)
+
+	invalid := filepath.Join(dir, "invalid.key")
+	require.NoError(t, os.WriteFile(invalid, []byte("c2hvcnQ=\n"), 0o600))
+	_, err = LoadKey(invalid)
+	require.ErrorIs(t, err, errors.ErrSecretKeyInvalid)
+
+	if runtime.GOOS == "windows" {
+		return
+	}
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	require.NoError(t, os.Chmod(path, 0o640))
+	_, err = LoadKey(path)
+	require.ErrorIs(t, err, errors.ErrInsecureKeyFile)
+}
+
+func TestDefaultKeyPath(t *testing.T) {
+	original := userConfigDirFunc
+	defer func() {
+		userConfigDirFunc = original
+	}()
+
+	userConfigDirFunc = func() (string, error) { return "/home/user/.config", nil }
+
+	t.Setenv(KeyEnv, "")
+	path, err := DefaultKeyPath()
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join("/home/user/.config", "bx", "secret.key"), path)
+
+	t.Setenv(KeyEnv, "/run/secrets/bx.key")
+	path, err = DefaultKeyPath()
+	require.NoError(t, err)
+	assert.Equal(t, "/run/secrets/bx.key", path)
+}
+
+func TestDecrypt_Encrypt(t *testing.T) {
+	t.Parallel()
+
+	key := newKey(t)
+	token, _ := Seal(key, "token")
+	password, _ := Seal(key, "123456")
+
+	source := "name: test\ntoken: !secret " + token + "\ncallbacks:\n  - parameters:\n      - !secret " + password +
+		"\n      - plain\n"
+
+	var doc yaml.Node
+	require.NoError(t, yaml.Unmarshal([]byte(source), &doc))
+	assert.Len(t, Sealed(&doc), 2)
+
+	loads := 0
+	values, err := Decrypt(&doc, func() (Key, error) {
+		loads++
+		return key, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, loads, "the key is loaded once")
+	assert.Equal(t, []Value{
+		{Plain: "token", Sealed: token, Path: []string{"token"}},
+		{Plain: "123456", Sealed: password, Path: []string{"callbacks", "0", "parameters", "0"}},
+	}, values)
+	assert.Empty(t, Sealed(&doc))
+
+	var decoded struct {
+		Name      string `yaml:"name"`
+		Token     string `yaml:"token"`
+		Callbacks []struct {
+			Parameters []string `yaml:"parameters"`
+		} `yaml:"callbacks"`
+	}
+	require.NoError(t, doc.Decode(&decoded))
+	assert.Equal(t, "token", decoded.Token)
+	assert.Equal(t, []string{"123456", "plain"}, decoded.Callbacks[0].Parameters)
+
+	decoded.Name = "token"
+	var out yaml.Node
+	require.NoError(t, out.Encode(decoded))
+	Encrypt(&out, values)
+
+	data, err := yaml.Marshal(&out)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "123456")
+	assert.Contains(t, string(data), "name: token\n", "a field equal to a secret is not replaced")
+	assert.Contains(t, string(data), "token: !secret "+token, "mapping keys are not replaced")
+	assert.Contains(t, string(data), "- !secret "+password)
+
+	decoded.Callbacks[0].Parameters[0] = "changed"
+	out = yaml.Node{}
+	require.NoError(t, out.Encode(decoded))
+	Encrypt(&out, values)
+
+	data, err = yaml.Marshal(&out)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "- changed\n", "a changed value is not replaced with the old secret")
+	assert.NotContains(t, string(data), password)
+}
+
+func TestDecrypt_errors(t *testing.T) {
+	t.Parallel()
+
+	var doc yaml.Node
+	require.NoError(t, yaml.Unmarshal([]byte("name: test\n"), &doc))
+	values, err := Decrypt(&doc, func() (Key, error) {
+		return nil, errors.ErrSecretKeyNotFound
+	})
+	require.NoError(t, err, "the key is not needed without secrets")
+	assert.Empty(t, values)
+
+	require.NoError(t, yaml.Unmarshal([]byte("name: test\ntoken: !secret v1:AAAA\n"), &doc))
+	_, err = Decrypt(&doc, func() (Key, error) {
+		return nil, errors.ErrSecretKeyNotFound
+	})
+	require.ErrorIs(t, err, errors.ErrSecretKeyNotFound)
+
+	_, err = Decrypt(&doc, func() (Key, error) {
+		return newKey(t), nil
+	})
+	require.ErrorIs(t, err, errors.ErrSecretInvalid)
+	assert.Contains(t, err.Error(), "line 2")
+}
@@ -0,0 +1,341 @@
+// Package session stores partners.1c-bitrix.ru session cookies on disk between commands.
+//
+// Every account has its own cache file. The cookies are encrypted with AES-256-GCM using
+// a key derived from the account password, so a cache file is useless without the password
+// and is dropped automatically when the password changes. Files are created with 0600
+// permissions in a 0700 directory.
+package session
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	errors2 "github.com/pixel365/bx/internal/errors"
+)
+
+const (
+	fileExt    = ".session"
+	saltSize   = 16
+	keySize    = 32
+	iterations = 100_000
+	version    = 1
+)
+
+var (
+	userCacheDirFunc = os.UserCacheDir
+	nowFunc          = time.Now
+)
+
+// Store is a directory of cached sessions.
+type Store struct {
+	Dir string
+}
+
+type entry struct {
+	SavedAt   time.Time `json:"savedAt"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	Cookies   []cookie  `json:"cookies"`
+	Version   int       `json:"version"`
+}
+
+type cookie struct {
+	Expires  time.Time `json:"expires,omitzero"`
+	Name     string    `json:"name"`
+	Value    string    `json:"value"`
+	Path     string    `json:"path,omitempty"`
+	Domain   string    `json:"domain,omitempty"`
+	Secure   bool      `json:"secure,omitempty"`
+	HttpOnly bool      `json:"httpOnly,omitempty"`
+}
+
+// DefaultStore returns the store in the `bx/sessions` directory of the user cache directory,
+// e.g. `~/.cache/bx/sessions` on Linux.
+func DefaultStore() (*Store, error) {
+	dir, err := userCacheDirFunc()
+	if err != nil {
+		return nil, fmt.Errorf("session cache: %w", err)
+	}
+
+	return &Store{Dir: filepath.Join(dir, "bx", "sessions")}, nil
+}
+
+// Load returns the cached cookies of the account.
+//
+// Parameters:
+//   - account: The account login.
+//   - password: The account password the cache was saved with.
+//
+// Returns:
+//   - The cookies.
+//   - errors.ErrSessionNotFound if there is no cache for the account.
+//   - errors.ErrSessionExpired if the cache or one of the cookies has expired.
+//   - errors.ErrSessionInvalid if the cache cannot be decrypted (e.g. the password changed)
+//     or is corrupted.
+func (s *Store) Load(account, password string) ([]*http.Cookie, error) {
+	path, err := s.path(account)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, errors2.ErrSessionNotFound
+		}
+		return nil, fmt.Errorf("session cache: %w", err)
+	}
+
+	plain, err := decrypt(data, account, password)
+	if err != nil {
+		return nil, err
+	}
+
+	var e entry
+	if err := json.Unmarshal(plain, &e); err != nil || e.Version != version || len(e.Cookies) == 0 {
+		return nil, errors2.ErrSessionInvalid
+	}
+
+	now := nowFunc()
+	if !now.Before(e.ExpiresAt) {
+		return nil, errors2.ErrSessionExpired
+	}
+
+	cookies := make([]*http.Cookie, 0, len(e.Cookies))
+	for _, c := range e.Cookies {
+		if !c.Expires.IsZero() && !now.Before(c.Expires) {
+			return nil, errors2.ErrSessionExpired
+		}
+
+		cookies = append(cookies, &http.Cookie{
+			Name:     c.Name,
+			Value:    c.Value,
+			Path:     c.Path,
+			Domain:   c.Domain,
+			Expires:  c.Expires,
+			Secure:   c.Secure,
+			HttpOnly: c.HttpOnly,
+		})
+	}
+
+	return cookies, nil
+}
+
+// Save encrypts the cookies and writes them to the cache of the account,
+// replacing the previous cache. The cache expires after ttl.
+func (s *Store) Save(account, password string, cookies []*http.Cookie, ttl time.Duration) error {
+	if password == "" {
+		return errors2.ErrEmptyPassword
+	}
+
+	if len(cookies) == 0 {
+		return errors2.ErrNilCookie
+	}
+
+	path, err := s.path(account)
+	if err != nil {
+		return err
+	}
+
+	now := nowFunc()
+	e := entry{
+		Version:   version,
+		SavedAt:   now,
+		ExpiresAt: now.Add(ttl),
+		Cookies:   make([]cookie, 0, len(cookies)),
+	}
+
+	for _, c := range cookies {
+		if c == nil {
+			continue
+		}
+
+		e.Cookies = append(e.Cookies, cookie{
+			Name:     c.Name,
+			Value:    c.Value,
+			Path:     c.Path,
+			Domain:   c.Domain,
+			Expires:  c.Expires,
+			Secure:   c.Secure,
+			HttpOnly: c.HttpOnly,
+		})
+	}
+
+	plain, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	data, err := encrypt(plain, account, password)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(s.Dir, 0700); err != nil {
+		return fmt.Errorf("session cache: %w", err)
+	}
+
+	return writeFile(path, data)
+}
+
+// Delete removes the cache of the account. A missing cache is not an error.
+func (s *Store) Delete(account string) error {
+	path, err := s.path(account)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("session cache: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteAll removes the caches of all accounts and returns how many were removed.
+func (s *Store) DeleteAll() (int, error) {
+	entries, err := os.ReadDir(s.Dir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("session cache: %w", err)
+	}
+
+	n := 0
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), fileExt) {
+			continue
+		}
+
+		if err := os.Remove(filepath.Join(s.Dir, e.Name())); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return n, fmt.Errorf("session cache: %w", err)
+		}
+		n++
+	}
+
+	return n, nil
+}
+
+// path returns the cache file of the account. The file name is a hash of the account,
+// so the login is not disclosed by the directory listing.
+func (s *Store) path(account string) (string, error) {
+	account = strings.TrimSpace(account)
+	if account == "" {
+		return "", errors2.ErrEmptyAccountName
+	}
+
+	sum := sha256.Sum256([]byte(strings.ToLower(account)))
+
+	return filepath.Join(s.Dir, hex.EncodeToString(sum[:])+fileExt), nil
+}
+
+// writeFile writes the data to a temporary file with 0600 permissions
+// and renames it over path, so a concurrent reader never sees a partial file.
+func writeFile(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("session cache: %w", err)
+	}
+
+	name := tmp.Name()
+	defer func() { _ = os.Remove(name) }()
+
+	if err := tmp.Chmod(0600); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("session cache: %w", err)
+	}
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("session cache: %w", err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("session cache: %w", err)
+	}
+
+	if err := os.Rename(name, path); err != nil {
+		return fmt.Errorf("session cache: %w", err)
+	}
+
+	return nil
+}
+
+// encrypt returns salt | nonce | ciphertext. The account is authenticated as additional data,
+// so a file copied to another account name cannot be decrypted.
+func encrypt(plain []byte, account, password string) ([]byte, error) {
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	aead, err := newAEAD(password, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, 0, saltSize+len(nonce)+len(plain)+aead.Overhead())
+	out = append(out, salt...)
+	out = append(out, nonce...)
+
+	return aead.Seal(out, nonce, plain, additionalData(account)), nil
+}
+
+func decrypt(data []byte, account, password string) ([]byte, error) {
+	if len(data) < saltSize {
+		return nil, errors2.ErrSessionInvalid
+	}
+
+	aead, err := newAEAD(password, data[:saltSize])
+	if err != nil {
+		return nil, err
+	}
+
+	data = data[saltSize:]
+	if len(data) < aead.NonceSize() {
+		return nil, errors2.ErrSessionInvalid
+	}
+
+	plain, err := aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], additionalData(account))
+	if err != nil {
+		return nil, errors2.ErrSessionInvalid
+	}
+
+	return plain, nil
+}
+
+func newAEAD(password string, salt []byte) (cipher.AEAD, error) {
+	key, err := pbkdf2.Key(sha256.New, password, salt, iterations, keySize)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+func additionalData(account string) []byte {
+	return []byte(strings.ToLower(strings.TrimSpace(account)))
+}
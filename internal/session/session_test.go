@@ -0,0 +1,187 @@
+package session
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pixel365/bx/internal/errors"
+)
+
+func testCookies() []*http.Cookie {
+	return []*http.Cookie{
+		{Name: "BITRIX_SM_LOGIN", Value: "partner", Path: "/"},
+		{Name: "PHPSESSID", Value: "abc123", Domain: "partners.1c-bitrix.ru", HttpOnly: true, Secure: true},
+	}
+}
+
+func TestStore_SaveLoad(t *testing.T) {
+	store := &Store{Dir: filepath.Join(t.TempDir(), "sessions")}
+
+	require.NoError(t, store.Save("partner@example.com", "secret", testCookies(), time.Hour))
+
+	got, err := store.Load("Partner@Example.com ", "secret")
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+	assert.Equal(t, "BITRIX_SM_LOGIN", got[0].Name)
+	assert.Equal(t, "partner", got[0].Value)
+	assert.Equal(t, "/", got[0].Path)
+	assert.Equal(t, "abc123", got[1].Value)
+	assert.Equal(t, "partners.1c-bitrix.ru", got[1].Domain)
+	assert.True(t, got[1].HttpOnly)
+	assert.True(t, got[1].Secure)
+
+	path, err := store.path("partner@example.com")
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "abc123")
+	assert.NotContains(t, filepath.Base(path), "partner")
+
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+		info, err = os.Stat(store.Dir)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0700), info.Mode().Perm())
+	}
+}
+
+func TestStore_Load_errors(t *testing.T) {
+	store := &Store{Dir: t.TempDir()}
+	require.NoError(t, store.Save("partner", "secret", testCookies(), time.Hour))
+
+	tests := []struct {
+		err      error
+		name     string
+		account  string
+		password string
+	}{
+		{errors.ErrSessionNotFound, "not cached", "other", "secret"},
+		{errors.ErrSessionInvalid, "wrong password", "partner", "changed"},
+		{errors.ErrEmptyAccountName, "empty account", " ", "secret"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := store.Load(tt.account, tt.password)
+			require.ErrorIs(t, err, tt.err)
+		})
+	}
+}
+
+func TestStore_Load_copiedFile(t *testing.T) {
+	store := &Store{Dir: t.TempDir()}
+	require.NoError(t, store.Save("partner", "secret", testCookies(), time.Hour))
+
+	from, _ := store.path("partner")
+	to, _ := store.path("other")
+
+	data, err := os.ReadFile(from)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(to, data, 0600))
+
+	_, err = store.Load("other", "secret")
+	require.ErrorIs(t, err, errors.ErrSessionInvalid)
+}
+
+func TestStore_Load_corrupted(t *testing.T) {
+	store := &Store{Dir: t.TempDir()}
+	path, _ := store.path("partner")
+
+	for _, data := range []string{"", "short", "0123456789abcdef0123456789abcdef"} {
+		require.NoError(t, os.WriteFile(path, []byte(data), 0600))
+
+		_, err := store.Load("partner", "secret")
+		require.ErrorIs(t, err, errors.ErrSessionInvalid, data)
+	}
+}
+
+func TestStore_Load_expired(t *testing.T) {
+	now := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	originalNow := nowFunc
+	defer func() { nowFunc = originalNow }()
+
+	store := &Store{Dir: t.TempDir()}
+
+	nowFunc = func() time.Time { return now }
+	require.NoError(t, store.Save("partner", "secret", testCookies(), time.Hour))
+
+	expiring := append(testCookies(), &http.Cookie{Name: "BITRIX_SM_UIDH", Value: "x", Expires: now.Add(time.Minute)})
+	require.NoError(t, store.Save("cookie", "secret", expiring, time.Hour))
+
+	nowFunc = func() time.Time { return now.Add(30 * time.Second) }
+	_, err := store.Load("partner", "secret")
+	require.NoError(t, err)
+	_, err = store.Load("cookie", "secret")
+	require.NoError(t, err)
+
+	nowFunc = func() time.Time { return now.Add(2 * time.Minute) }
+	_, err = store.Load("cookie", "secret")
+	require.ErrorIs(t, err, errors.ErrSessionExpired)
+
+	nowFunc = func() time.Time { return now.Add(time.Hour) }
+	_, err = store.Load("partner", "secret")
+	require.ErrorIs(t, err, errors.ErrSessionExpired)
+}
+
+func TestStore_Save_errors(t *testing.T) {
+	store := &Store{Dir: t.TempDir()}
+
+	require.ErrorIs(t, store.Save("partner", "", testCookies(), time.Hour), errors.ErrEmptyPassword)
+	require.ErrorIs(t, store.Save("partner", "secret", nil, time.Hour), errors.ErrNilCookie)
+	require.ErrorIs(t, store.Save("", "secret", testCookies(), time.Hour), errors.ErrEmptyAccountName)
+}
+
+func TestStore_Delete(t *testing.T) {
+	store := &Store{Dir: t.TempDir()}
+	require.NoError(t, store.Save("partner", "secret", testCookies(), time.Hour))
+	require.NoError(t, store.Save("other", "secret", testCookies(), time.Hour))
+
+	require.NoError(t, store.Delete("partner"))
+	require.NoError(t, store.Delete("partner"))
+
+	_, err := store.Load("partner", "secret")
+	require.ErrorIs(t, err, errors.ErrSessionNotFound)
+
+	_, err = store.Load("other", "secret")
+	require.NoError(t, err)
+}
+
+func TestStore_DeleteAll(t *testing.T) {
+	store := &Store{Dir: t.TempDir()}
+	require.NoError(t, store.Save("partner", "secret", testCookies(), time.Hour))
+	require.NoError(t, store.Save("other", "secret", testCookies(), time.Hour))
+	require.NoError(t, os.WriteFile(filepath.Join(store.Dir, "keep.txt"), nil, 0600))
+
+	n, err := store.DeleteAll()
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.FileExists(t, filepath.Join(store.Dir, "keep.txt"))
+
+	n, err = (&Store{Dir: filepath.Join(store.Dir, "missing")}).DeleteAll()
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+}
+
+func TestDefaultStore(t *testing.T) {
+	originalDir := userCacheDirFunc
+	defer func() { userCacheDirFunc = originalDir }()
+
+	userCacheDirFunc = func() (string, error) { return "/home/user/.cache", nil }
+	store, err := DefaultStore()
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join("/home/user/.cache", "bx", "sessions"), store.Dir)
+
+	userCacheDirFunc = func() (string, error) { return "", os.ErrNotExist }
+	_, err = DefaultStore()
+	require.Error(t, err)
+}
@@ -0,0 +1,27 @@
+package types
+
+// CallbackInfo describes the build a callback or a hook is run for.
+//
+// Fields:
+//   - Module:     The name of the module.
+//   - Version:    The version being built or published.
+//   - Label:      The label of the version.
+//   - Stage:      The name of the stage; empty for hooks.
+//   - VersionDir: The directory the files of the version are collected in.
+//   - ZipPath:    The path of the release archive.
+//   - Commit:     The hash of the Git commit the module is built from; empty without a repository.
+//   - Files:      The number of files written by the stage, without skipped and failed ones;
+//     set for post-stage callbacks.
+//   - LogDir:     The log directory the output of commands is saved to; empty without the log section.
+//     It is not passed to the callbacks.
+type CallbackInfo struct {
+	Module     string
+	Version    string
+	Label      string
+	Stage      string
+	VersionDir string
+	ZipPath    string
+	Commit     string
+	LogDir     string
+	Files      int
+}
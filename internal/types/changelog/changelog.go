@@ -1,15 +1,72 @@
 package changelog
 
 import (
+	"regexp"
+	"strings"
+	"time"
+
 	"github.com/pixel365/bx/internal/types"
 )
 
 type Changelog struct {
-	Transform      *[]types.TypeValue[types.TransformType, []string]       `yaml:"transform,omitempty"`
-	From           types.TypeValue[types.ChangelogType, string]            `yaml:"from"`
-	To             types.TypeValue[types.ChangelogType, string]            `yaml:"to"`
-	Sort           types.SortingType                                       `yaml:"sort,omitempty"`
-	FooterTemplate string                                                  `yaml:"footerTemplate,omitempty"`
-	Condition      types.TypeValue[types.ChangelogConditionType, []string] `yaml:"condition,omitempty"`
-	MaxLength      int                                                     `yaml:"maxLength,omitempty"`
+	FooterTemplates map[types.Locale]string                                 `yaml:"footerTemplates,omitempty"`
+	Transform       *[]types.TypeValue[types.TransformType, []string]       `yaml:"transform,omitempty"`
+	From            types.TypeValue[types.ChangelogType, string]            `yaml:"from"`
+	To              types.TypeValue[types.ChangelogType, string]            `yaml:"to"`
+	Sort            types.SortingType                                       `yaml:"sort,omitempty"`
+	FooterTemplate  string                                                  `yaml:"footerTemplate,omitempty"`
+	Condition       types.TypeValue[types.ChangelogConditionType, []string] `yaml:"condition,omitempty"`
+	Scope           types.ChangelogScopeType                                `yaml:"scope,omitempty"`
+	TypesOrder      []string                                                `yaml:"typesOrder,omitempty"`
+	Paths           []string                                                `yaml:"paths,omitempty"`
+	Locales         []types.Locale                                          `yaml:"locales,omitempty"`
+	MaxLength       int                                                     `yaml:"maxLength,omitempty"`
+	compiled        []compiledRule
+}
+
+// compiledRule holds the compiled patterns of a transformation rule.
+//
+// Fields:
+//   - regex:  The pattern of a RegexReplace rule; nil if it is invalid.
+//   - issues: The patterns of the keys of a LinkIssues rule, one per value; nil for invalid values.
+type compiledRule struct {
+	regex  *regexp.Regexp
+	issues []*regexp.Regexp
+}
+
+// Commit is a single changelog entry together with the metadata of the commit
+// it was produced from.
+//
+// Fields:
+//   - When: Committer time of the commit.
+//   - Hash: Full commit hash.
+//   - Author: Author name.
+//   - Subject: Raw first line of the commit message.
+//   - Body: The rest of the commit message without the subject line.
+//   - Message: Subject after all transformation rules were applied.
+//   - Parents: Hashes of the parent commits.
+//   - Order: Position of the commit in topological order (children before parents).
+type Commit struct {
+	When    time.Time
+	Hash    string
+	Author  string
+	Subject string
+	Body    string
+	Message string
+	Parents []string
+	Order   int
+}
+
+// Trailer returns the value of the last `key: value` trailer line in the commit body,
+// or an empty string if there is no such trailer. The key is matched case-insensitively.
+func (c Commit) Trailer(key string) string {
+	value := ""
+	for _, line := range strings.Split(c.Body, "\n") {
+		k, v, ok := strings.Cut(line, ":")
+		if ok && strings.EqualFold(strings.TrimSpace(k), key) {
+			value = strings.TrimSpace(v)
+		}
+	}
+
+	return value
 }
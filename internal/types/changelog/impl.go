@@ -2,27 +2,80 @@ package changelog
 
 import (
 	"fmt"
+	"html"
+	"net/url"
+	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
-
-	"golang.org/x/text/encoding/charmap"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/pixel365/bx/internal/errors"
 	"github.com/pixel365/bx/internal/types"
 )
 
-// EncodedFooter returns the FooterTemplate string encoded in Windows-1251,
-// prefixed with a <br> tag. If FooterTemplate is empty, it returns an empty string.
+// issueProjectRegex matches a tracker project key, e.g. `TASK` in `TASK-123`.
+var issueProjectRegex = regexp.MustCompile(`^[A-Z][A-Z0-9_]*$`)
+
+// EncodedFooter returns the footer template of the locale encoded in the locale charset,
+// prefixed with a <br> tag. For Russian, FooterTemplate is used unless FooterTemplates
+// contains the `ru` key. If there is no footer for the locale, it returns an empty string.
 //
 // Returns:
 //   - The encoded footer string, or an empty string if not set
 //   - An error if encoding fails
-func (c *Changelog) EncodedFooter() (string, error) {
-	if c.FooterTemplate == "" {
+func (c *Changelog) EncodedFooter(locale types.Locale) (string, error) {
+	footer := c.FooterTemplates[locale]
+	if footer == "" && locale == types.Ru {
+		footer = c.FooterTemplate
+	}
+
+	if footer == "" {
 		return "", nil
 	}
 
-	return charmap.Windows1251.NewEncoder().String("<br>" + c.FooterTemplate)
+	return locale.Charmap().NewEncoder().String("<br>" + footer)
+}
+
+// DescriptionLocales returns the locales for which descriptions are generated from commits.
+// If Locales is not set, only Russian is used.
+func (c *Changelog) DescriptionLocales() []types.Locale {
+	if len(c.Locales) == 0 {
+		return []types.Locale{types.Ru}
+	}
+
+	return c.Locales
+}
+
+// UsesWorktree reports whether the release is compared against the working tree
+// (`to: {type: worktree}`) instead of a commit or a tag.
+// Such builds include uncommitted changes and cannot be reproduced from a commit.
+func (c *Changelog) UsesWorktree() bool {
+	return c.To.Type == types.Worktree
+}
+
+// LocalizedMessage returns the changelog text of the commit for the locale.
+//
+// If the commit body contains a `Changelog-<Locale>:` trailer (e.g. `Changelog-En:`),
+// its transformed value is returned. Otherwise, the transformed subject is returned.
+func (c *Changelog) LocalizedMessage(commit Commit, locale types.Locale) string {
+	if text := commit.Trailer(TrailerKey(locale)); text != "" {
+		return c.ApplyTransformation(text)
+	}
+
+	return commit.Message
+}
+
+// TrailerKey returns the commit trailer key with the localized changelog text,
+// e.g. `Changelog-En` for English.
+func TrailerKey(locale types.Locale) string {
+	l := string(locale)
+	if l == "" {
+		return "Changelog"
+	}
+
+	return "Changelog-" + strings.ToUpper(l[:1]) + l[1:]
 }
 
 // ApplyTransformation applies the transformation rules defined in the Transform field
@@ -31,19 +84,36 @@ func (c *Changelog) EncodedFooter() (string, error) {
 // Supported rule types:
 //   - StripPrefix: removes the prefix if the string starts with any of the specified values.
 //   - StripSuffix: removes the suffix if the string ends with any of the specified values.
+//   - RemoveAll: removes all occurrences of the specified values and normalizes spaces.
+//   - RegexReplace: replaces matches of the pattern (first value) with the replacement
+//     (second value); capture groups are available as `$1`, `${name}`.
+//   - Capitalize: converts the first letter to upper case.
+//   - Map: replaces every word equal to a key of the `key=value` pairs with its value.
+//   - LinkIssues: turns issue keys like `TASK-123` into HTML links using the `PROJECT=url`
+//     pairs, where `{key}` in the url is replaced by the issue key.
 //
-// After applying all applicable transformations, the result is trimmed of
-// leading and trailing whitespace using strings.TrimSpace.
+// Rules are applied in the order they are defined, then the result is truncated to MaxLength.
+// LinkIssues rules are applied after truncation so that links are never cut.
+// Finally, the result is trimmed of leading and trailing whitespace using strings.TrimSpace.
 //
 // If no transformations are defined, the input string is returned unchanged.
 //
+// The patterns compiled by Compile are used; if it has not been called, the patterns
+// are compiled for this call only.
+//
 // Returns the transformed and trimmed string.
 func (c *Changelog) ApplyTransformation(s string) string {
 	if c.Transform == nil {
 		return s
 	}
 
-	for _, rule := range *c.Transform {
+	compiled := c.compiled
+	if compiled == nil {
+		compiled = c.compileTransform()
+	}
+
+	var links []int
+	for i, rule := range *c.Transform {
 		switch rule.Type {
 		default:
 			continue
@@ -53,14 +123,64 @@ func (c *Changelog) ApplyTransformation(s string) string {
 			s = stripSuffix(s, rule.Value)
 		case types.RemoveAll:
 			s = removeAll(s, rule.Value)
+		case types.RegexReplace:
+			s = regexReplace(s, compiled[i].regex, rule.Value)
+		case types.Capitalize:
+			s = capitalize(s)
+		case types.Map:
+			s = mapWords(s, rule.Value)
+		case types.LinkIssues:
+			links = append(links, i)
 		}
 	}
 
 	s = truncate(s, c.MaxLength)
 
+	for _, i := range links {
+		s = linkIssues(s, (*c.Transform)[i].Value, compiled[i].issues)
+	}
+
 	return strings.TrimSpace(s)
 }
 
+// Compile compiles the patterns of the RegexReplace and LinkIssues rules, so that
+// ApplyTransformation does not compile them for every commit. It is called when a module
+// is read and when the changelog is generated; it must be called again after the rules change.
+// Invalid patterns are left out; IsValid reports them.
+func (c *Changelog) Compile() {
+	c.compiled = c.compileTransform()
+}
+
+// compileTransform compiles the patterns of the RegexReplace and LinkIssues rules.
+//
+// Returns:
+//   - []compiledRule: The compiled patterns, in the order of the rules.
+func (c *Changelog) compileTransform() []compiledRule {
+	if c.Transform == nil {
+		return nil
+	}
+
+	rules := make([]compiledRule, len(*c.Transform))
+	for i, rule := range *c.Transform {
+		switch rule.Type {
+		case types.RegexReplace:
+			if len(rule.Value) == 2 {
+				rules[i].regex, _ = regexp.Compile(rule.Value[0])
+			}
+		case types.LinkIssues:
+			rules[i].issues = make([]*regexp.Regexp, len(rule.Value))
+			for j, value := range rule.Value {
+				project, _, ok := strings.Cut(value, "=")
+				if ok && issueProjectRegex.MatchString(project) {
+					rules[i].issues[j] = regexp.MustCompile(`\b` + project + `-\d+\b`)
+				}
+			}
+		}
+	}
+
+	return rules
+}
+
 func (c *Changelog) IsValid() error {
 	if err := changeLogFromToValidate(c); err != nil {
 		return err
@@ -71,16 +191,43 @@ func (c *Changelog) IsValid() error {
 	}
 
 	switch c.Sort {
-	case "", types.Asc, types.Desc:
+	case "", types.Asc, types.Desc, types.DateAsc, types.DateDesc, types.Topological,
+		types.ConventionalType:
 	default:
-		return fmt.Errorf("changelog sort must be %s or %s", types.Asc, types.Desc)
+		return fmt.Errorf(
+			"changelog sort must be one of %s, %s, %s, %s, %s, %s",
+			types.Asc,
+			types.Desc,
+			types.DateAsc,
+			types.DateDesc,
+			types.Topological,
+			types.ConventionalType,
+		)
+	}
+
+	if err := scopeValidate(c); err != nil {
+		return err
+	}
+
+	if err := localesValidate(c); err != nil {
+		return err
+	}
+
+	for i, t := range c.TypesOrder {
+		if strings.TrimSpace(t) == "" {
+			return fmt.Errorf("changelog types order [%d]: value is required", i)
+		}
 	}
 
 	if c.MaxLength < 0 {
 		return fmt.Errorf("changelog max length must be non-negative")
 	}
 
-	return transformValidate(c.Transform)
+	if err := transformValidate(c.Transform); err != nil {
+		return err
+	}
+
+	return nil
 }
 
 func transformValidate(transform *[]types.TypeValue[types.TransformType, []string]) error {
@@ -89,19 +236,152 @@ func transformValidate(transform *[]types.TypeValue[types.TransformType, []strin
 	}
 
 	for _, rule := range *transform {
+		if rule.Type == types.Capitalize {
+			continue
+		}
+
 		if len(rule.Value) == 0 {
 			return fmt.Errorf("transform rule: value is empty")
 		}
 
 		switch rule.Type {
 		default:
-			return fmt.Errorf("transform rule: type must be %s", types.StripPrefix)
+			return fmt.Errorf(
+				"transform rule: type must be one of %s, %s, %s, %s, %s, %s, %s",
+				types.StripPrefix,
+				types.StripSuffix,
+				types.RemoveAll,
+				types.RegexReplace,
+				types.Capitalize,
+				types.Map,
+				types.LinkIssues,
+			)
 		case types.StripPrefix, types.StripSuffix, types.RemoveAll:
 			for _, value := range rule.Value {
 				if value == "" {
 					return fmt.Errorf("transform rule: value is required")
 				}
 			}
+		case types.RegexReplace:
+			if err := regexReplaceValidate(rule.Value); err != nil {
+				return err
+			}
+		case types.Map:
+			if err := pairsValidate(rule.Type, rule.Value); err != nil {
+				return err
+			}
+		case types.LinkIssues:
+			if err := linkIssuesValidate(rule.Value); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func regexReplaceValidate(values []string) error {
+	if len(values) != 2 {
+		return fmt.Errorf("transform rule %s: value must contain a pattern and a replacement", types.RegexReplace)
+	}
+
+	if values[0] == "" {
+		return fmt.Errorf("transform rule %s: pattern is required", types.RegexReplace)
+	}
+
+	if _, err := regexp.Compile(values[0]); err != nil {
+		return fmt.Errorf("transform rule %s: invalid pattern: %w", types.RegexReplace, err)
+	}
+
+	return nil
+}
+
+func pairsValidate(t types.TransformType, values []string) error {
+	for i, value := range values {
+		key, _, ok := strings.Cut(value, "=")
+		if !ok {
+			return fmt.Errorf("transform rule %s [%d]: value must have key=value format", t, i)
+		}
+
+		if strings.TrimSpace(key) == "" {
+			return fmt.Errorf("transform rule %s [%d]: key is required", t, i)
+		}
+	}
+
+	return nil
+}
+
+func linkIssuesValidate(values []string) error {
+	if err := pairsValidate(types.LinkIssues, values); err != nil {
+		return err
+	}
+
+	for i, value := range values {
+		project, template, _ := strings.Cut(value, "=")
+		if !issueProjectRegex.MatchString(project) {
+			return fmt.Errorf("transform rule %s [%d]: invalid project key %s", types.LinkIssues, i, project)
+		}
+
+		if !strings.Contains(template, "{key}") {
+			return fmt.Errorf("transform rule %s [%d]: url must contain {key}", types.LinkIssues, i)
+		}
+
+		u, err := url.Parse(strings.ReplaceAll(template, "{key}", project+"-1"))
+		if err != nil {
+			return fmt.Errorf("transform rule %s [%d]: invalid url: %w", types.LinkIssues, i, err)
+		}
+
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return fmt.Errorf("transform rule %s [%d]: url scheme must be http or https", types.LinkIssues, i)
+		}
+	}
+
+	return nil
+}
+
+func localesValidate(c *Changelog) error {
+	for i, locale := range c.Locales {
+		if !locale.IsValid() {
+			return fmt.Errorf("changelog locales [%d]: locale must be %s or %s", i, types.Ru, types.En)
+		}
+	}
+
+	for locale := range c.FooterTemplates {
+		if !locale.IsValid() {
+			return fmt.Errorf("changelog footer templates: locale must be %s or %s", types.Ru, types.En)
+		}
+	}
+
+	if c.FooterTemplate != "" && c.FooterTemplates[types.Ru] != "" {
+		return fmt.Errorf("changelog: footerTemplate and footerTemplates.ru cannot be used together")
+	}
+
+	return nil
+}
+
+func scopeValidate(c *Changelog) error {
+	switch c.Scope {
+	case "", types.StagesScope:
+	default:
+		return fmt.Errorf("changelog scope must be %s", types.StagesScope)
+	}
+
+	if c.Scope != "" && len(c.Paths) > 0 {
+		return fmt.Errorf("changelog: scope and paths cannot be used together")
+	}
+
+	for i, path := range c.Paths {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			return fmt.Errorf("changelog paths [%d]: value is required", i)
+		}
+
+		if filepath.IsAbs(path) {
+			return fmt.Errorf("changelog paths [%d]: path must be relative to the repository", i)
+		}
+
+		if slices.Contains(strings.Split(filepath.ToSlash(path), "/"), "..") {
+			return fmt.Errorf("changelog paths [%d]: path must not leave the repository", i)
 		}
 	}
 
@@ -109,7 +389,7 @@ func transformValidate(transform *[]types.TypeValue[types.TransformType, []strin
 }
 
 func changeLogFromToValidate(c *Changelog) error {
-	if c.From.Value == "" || c.To.Value == "" {
+	if c.From.Value == "" || (c.To.Value == "" && !c.UsesWorktree()) {
 		return errors.ErrChangelogValue
 	}
 
@@ -117,8 +397,12 @@ func changeLogFromToValidate(c *Changelog) error {
 		return fmt.Errorf("changelog from: type must be %s or %s", types.Commit, types.Tag)
 	}
 
-	if c.To.Type != types.Commit && c.To.Type != types.Tag {
-		return fmt.Errorf("changelog to: type must be %s or %s", types.Commit, types.Tag)
+	if c.To.Type != types.Commit && c.To.Type != types.Tag && !c.UsesWorktree() {
+		return fmt.Errorf("changelog to: type must be %s, %s or %s", types.Commit, types.Tag, types.Worktree)
+	}
+
+	if c.UsesWorktree() && c.To.Value != "" {
+		return fmt.Errorf("changelog to: value must be empty for type %s", types.Worktree)
 	}
 
 	return nil
@@ -191,6 +475,58 @@ func removeAll(s string, values []string) string {
 	return s
 }
 
+func regexReplace(s string, reg *regexp.Regexp, values []string) string {
+	if reg == nil || len(values) != 2 {
+		return s
+	}
+
+	return reg.ReplaceAllString(s, values[1])
+}
+
+func capitalize(s string) string {
+	s = strings.TrimSpace(s)
+	r, size := utf8.DecodeRuneInString(s)
+	if r == utf8.RuneError {
+		return s
+	}
+
+	return string(unicode.ToUpper(r)) + s[size:]
+}
+
+func mapWords(s string, values []string) string {
+	dictionary := make(map[string]string, len(values))
+	for _, value := range values {
+		if key, replacement, ok := strings.Cut(value, "="); ok {
+			dictionary[key] = replacement
+		}
+	}
+
+	words := strings.Fields(s)
+	for i, word := range words {
+		if replacement, ok := dictionary[word]; ok {
+			words[i] = replacement
+		}
+	}
+
+	return strings.Join(words, " ")
+}
+
+func linkIssues(s string, values []string, regs []*regexp.Regexp) string {
+	for i, value := range values {
+		if i >= len(regs) || regs[i] == nil {
+			continue
+		}
+
+		_, template, _ := strings.Cut(value, "=")
+		s = regs[i].ReplaceAllStringFunc(s, func(key string) string {
+			href := strings.ReplaceAll(template, "{key}", url.PathEscape(key))
+			return fmt.Sprintf(`<a href="%s">%s</a>`, html.EscapeString(href), key)
+		})
+	}
+
+	return s
+}
+
 func truncate(s string, max int) string {
 	if max <= 0 || len(s) <= max {
 		return s
@@ -38,7 +38,7 @@ func TestChangelog_EncodedFooter(t *testing.T) {
 				FooterTemplate: tt.fields.FooterTemplate,
 			}
 
-			got, err := c.EncodedFooter()
+			got, err := c.EncodedFooter(types.Ru)
 			if tt.wantErr {
 				require.Error(t, err)
 			} else {
@@ -50,6 +50,67 @@ func TestChangelog_EncodedFooter(t *testing.T) {
 	}
 }
 
+func TestChangelog_EncodedFooter_locales(t *testing.T) {
+	t.Parallel()
+
+	c := &Changelog{
+		FooterTemplate: "Внимание",
+		FooterTemplates: map[types.Locale]string{
+			types.En: "Attention, café",
+		},
+	}
+
+	ru, err := c.EncodedFooter(types.Ru)
+	require.NoError(t, err)
+	assert.Equal(t, "<br>\xc2\xed\xe8\xec\xe0\xed\xe8\xe5", ru)
+
+	en, err := c.EncodedFooter(types.En)
+	require.NoError(t, err)
+	assert.Equal(t, "<br>Attention, caf\xe9", en)
+
+	c.FooterTemplates[types.En] = "Внимание"
+	_, err = c.EncodedFooter(types.En)
+	require.Error(t, err)
+}
+
+func TestChangelog_LocalizedMessage(t *testing.T) {
+	t.Parallel()
+
+	c := &Changelog{Transform: &[]types.TypeValue[types.TransformType, []string]{
+		{Type: types.Capitalize},
+	}}
+
+	commit := Commit{
+		Subject: "fix: исправлена ошибка",
+		Message: "Fix: исправлена ошибка",
+		Body:    "Details\n\nChangelog-En: fixed a bug\nSigned-off-by: tester",
+	}
+
+	assert.Equal(t, "Fix: исправлена ошибка", c.LocalizedMessage(commit, types.Ru))
+	assert.Equal(t, "Fixed a bug", c.LocalizedMessage(commit, types.En))
+
+	commit.Body += "\nchangelog-ru: исправлено"
+	assert.Equal(t, "Исправлено", c.LocalizedMessage(commit, types.Ru))
+}
+
+func TestTrailerKey(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "Changelog-Ru", TrailerKey(types.Ru))
+	assert.Equal(t, "Changelog-En", TrailerKey(types.En))
+	assert.Equal(t, "Changelog", TrailerKey(""))
+}
+
+func TestChangelog_DescriptionLocales(t *testing.T) {
+	t.Parallel()
+
+	c := &Changelog{}
+	assert.Equal(t, []types.Locale{types.Ru}, c.DescriptionLocales())
+
+	c.Locales = []types.Locale{types.Ru, types.En}
+	assert.Equal(t, []types.Locale{types.Ru, types.En}, c.DescriptionLocales())
+}
+
 func TestChangelog_ApplyTransformation(t *testing.T) {
 	t.Parallel()
 
@@ -136,6 +197,60 @@ func TestChangelog_ApplyTransformation(t *testing.T) {
 				}},
 			},
 		},
+		{
+			"regex replace with groups",
+			args{s: "feat(api): new endpoint"},
+			"[api] new endpoint",
+			fields{
+				Changelog: Changelog{Transform: &[]types.TypeValue[types.TransformType, []string]{
+					{Type: types.RegexReplace, Value: []string{`^\w+\((\w+)\):\s*`, "[$1] "}},
+				}},
+			},
+		},
+		{
+			"capitalize",
+			args{s: " исправлена ошибка"},
+			"Исправлена ошибка",
+			fields{
+				Changelog: Changelog{Transform: &[]types.TypeValue[types.TransformType, []string]{
+					{Type: types.Capitalize},
+				}},
+			},
+		},
+		{
+			"map",
+			args{s: "feat: new fix: feature"},
+			"Новое: new Исправлено: feature",
+			fields{
+				Changelog: Changelog{Transform: &[]types.TypeValue[types.TransformType, []string]{
+					{Type: types.Map, Value: []string{"feat:=Новое:", "fix:=Исправлено:"}},
+				}},
+			},
+		},
+		{
+			"link issues",
+			args{s: "TASK-12 fixed, see XTASK-3 and TASK-7"},
+			`<a href="https://tracker.example.com/browse/TASK-12">TASK-12</a> fixed, see XTASK-3 and ` +
+				`<a href="https://tracker.example.com/browse/TASK-7">TASK-7</a>`,
+			fields{
+				Changelog: Changelog{Transform: &[]types.TypeValue[types.TransformType, []string]{
+					{Type: types.LinkIssues, Value: []string{"TASK=https://tracker.example.com/browse/{key}"}},
+				}},
+			},
+		},
+		{
+			"link issues after truncate",
+			args{s: "fix TASK-1 in module"},
+			`fix <a href="https://t.example.com/?id=TASK-1&amp;a=b">TASK-1</a>`,
+			fields{
+				Changelog: Changelog{
+					MaxLength: 10,
+					Transform: &[]types.TypeValue[types.TransformType, []string]{
+						{Type: types.LinkIssues, Value: []string{"TASK=https://t.example.com/?id={key}&a=b"}},
+					},
+				},
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -182,6 +297,36 @@ func Test_transformValidate(t *testing.T) {
 		{args{transform: &[]types.TypeValue[types.TransformType, []string]{
 			{Type: types.RemoveAll, Value: []string{"substring"}},
 		}}, "valid remove all", false},
+		{args{transform: &[]types.TypeValue[types.TransformType, []string]{
+			{Type: types.RegexReplace, Value: []string{`^(\w+):`, "$1 -"}},
+		}}, "valid regex replace", false},
+		{args{transform: &[]types.TypeValue[types.TransformType, []string]{
+			{Type: types.RegexReplace, Value: []string{`^(\w+):`}},
+		}}, "regex replace without replacement", true},
+		{args{transform: &[]types.TypeValue[types.TransformType, []string]{
+			{Type: types.RegexReplace, Value: []string{`[unclosed`, ""}},
+		}}, "regex replace invalid pattern", true},
+		{args{transform: &[]types.TypeValue[types.TransformType, []string]{
+			{Type: types.Capitalize},
+		}}, "valid capitalize", false},
+		{args{transform: &[]types.TypeValue[types.TransformType, []string]{
+			{Type: types.Map, Value: []string{"feat:=Feature:"}},
+		}}, "valid map", false},
+		{args{transform: &[]types.TypeValue[types.TransformType, []string]{
+			{Type: types.Map, Value: []string{"feat:"}},
+		}}, "map without value", true},
+		{args{transform: &[]types.TypeValue[types.TransformType, []string]{
+			{Type: types.LinkIssues, Value: []string{"TASK=https://tracker.example.com/{key}"}},
+		}}, "valid link issues", false},
+		{args{transform: &[]types.TypeValue[types.TransformType, []string]{
+			{Type: types.LinkIssues, Value: []string{"TASK=https://tracker.example.com/"}},
+		}}, "link issues without placeholder", true},
+		{args{transform: &[]types.TypeValue[types.TransformType, []string]{
+			{Type: types.LinkIssues, Value: []string{"task=https://tracker.example.com/{key}"}},
+		}}, "link issues invalid project", true},
+		{args{transform: &[]types.TypeValue[types.TransformType, []string]{
+			{Type: types.LinkIssues, Value: []string{"TASK=ftp://tracker.example.com/{key}"}},
+		}}, "link issues invalid scheme", true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -283,7 +428,49 @@ func Test_changeLogFromToValidate(t *testing.T) {
 					},
 				},
 			},
-			wantErr: fmt.Errorf("changelog to: type must be %s or %s", types.Commit, types.Tag),
+			wantErr: fmt.Errorf("changelog to: type must be %s, %s or %s", types.Commit, types.Tag, types.Worktree),
+		},
+		{
+			name: "worktree",
+			args: args{
+				c: &Changelog{
+					From: types.TypeValue[types.ChangelogType, string]{
+						Type:  types.Tag,
+						Value: "v1.2.3",
+					},
+					To: types.TypeValue[types.ChangelogType, string]{Type: types.Worktree},
+				},
+			},
+			wantErr: nil,
+		},
+		{
+			name: "worktree with value",
+			args: args{
+				c: &Changelog{
+					From: types.TypeValue[types.ChangelogType, string]{
+						Type:  types.Tag,
+						Value: "v1.2.3",
+					},
+					To: types.TypeValue[types.ChangelogType, string]{
+						Type:  types.Worktree,
+						Value: "HEAD",
+					},
+				},
+			},
+			wantErr: fmt.Errorf("changelog to: value must be empty for type %s", types.Worktree),
+		},
+		{
+			name: "worktree as from",
+			args: args{
+				c: &Changelog{
+					From: types.TypeValue[types.ChangelogType, string]{Type: types.Worktree, Value: "x"},
+					To: types.TypeValue[types.ChangelogType, string]{
+						Type:  types.Tag,
+						Value: "v1.2.3",
+					},
+				},
+			},
+			wantErr: fmt.Errorf("changelog from: type must be %s or %s", types.Commit, types.Tag),
 		},
 	}
 	for _, tt := range tests {
@@ -300,6 +487,40 @@ func Test_changeLogFromToValidate(t *testing.T) {
 	}
 }
 
+func TestChangelog_Compile(t *testing.T) {
+	t.Parallel()
+
+	c := Changelog{
+		From: types.TypeValue[types.ChangelogType, string]{Type: types.Tag, Value: "v1.0.0"},
+		To:   types.TypeValue[types.ChangelogType, string]{Type: types.Tag, Value: "v1.1.0"},
+		Transform: &[]types.TypeValue[types.TransformType, []string]{
+			{Type: types.RegexReplace, Value: []string{`\s+`, " "}},
+			{Type: types.Capitalize},
+			{Type: types.LinkIssues, Value: []string{"TASK=https://tracker.example.com/{key}"}},
+		},
+	}
+	require.NoError(t, c.IsValid())
+	assert.Nil(t, c.compiled, "validation does not compile the patterns")
+
+	c.Compile()
+	require.Len(t, c.compiled, 3)
+	compiled := c.compiled
+	assert.NotNil(t, compiled[0].regex)
+	assert.Nil(t, compiled[1].regex)
+	require.Len(t, compiled[2].issues, 1)
+
+	for _, s := range []string{"fix   TASK-1", "add  TASK-2"} {
+		c.ApplyTransformation(s)
+	}
+	assert.Same(t, compiled[0].regex, c.compiled[0].regex, "the patterns are not compiled again")
+	assert.Equal(t, `Fix <a href="https://tracker.example.com/TASK-3">TASK-3</a>`,
+		c.ApplyTransformation("fix   TASK-3"))
+
+	(*c.Transform)[0].Value = []string{`TASK`, "ISSUE"}
+	c.Compile()
+	assert.Equal(t, "Fix   ISSUE-3", c.ApplyTransformation("fix   TASK-3"), "the changed rules are compiled again")
+}
+
 func TestChangelog_IsValid(t *testing.T) {
 	t.Parallel()
 
@@ -331,6 +552,81 @@ func TestChangelog_IsValid(t *testing.T) {
 			Sort: types.SortingType("unknown"),
 		},
 		}, "invalid sort", true},
+		{fields{Changelog: &Changelog{
+			From: tv{Type: types.Tag, Value: "tag1"},
+			To:   tv{Type: types.Tag, Value: "tag2"},
+			Sort: types.DateDesc,
+		},
+		}, "date sort", false},
+		{fields{Changelog: &Changelog{
+			From:       tv{Type: types.Tag, Value: "tag1"},
+			To:         tv{Type: types.Tag, Value: "tag2"},
+			Sort:       types.ConventionalType,
+			TypesOrder: []string{"feat", " "},
+		},
+		}, "empty types order value", true},
+		{fields{Changelog: &Changelog{
+			From:  tv{Type: types.Tag, Value: "tag1"},
+			To:    tv{Type: types.Tag, Value: "tag2"},
+			Scope: types.StagesScope,
+		},
+		}, "stages scope", false},
+		{fields{Changelog: &Changelog{
+			From:  tv{Type: types.Tag, Value: "tag1"},
+			To:    tv{Type: types.Tag, Value: "tag2"},
+			Scope: types.ChangelogScopeType("unknown"),
+		},
+		}, "invalid scope", true},
+		{fields{Changelog: &Changelog{
+			From:  tv{Type: types.Tag, Value: "tag1"},
+			To:    tv{Type: types.Tag, Value: "tag2"},
+			Paths: []string{"modules/a"},
+		},
+		}, "valid paths", false},
+		{fields{Changelog: &Changelog{
+			From:  tv{Type: types.Tag, Value: "tag1"},
+			To:    tv{Type: types.Tag, Value: "tag2"},
+			Scope: types.StagesScope,
+			Paths: []string{"modules/a"},
+		},
+		}, "scope with paths", true},
+		{fields{Changelog: &Changelog{
+			From:  tv{Type: types.Tag, Value: "tag1"},
+			To:    tv{Type: types.Tag, Value: "tag2"},
+			Paths: []string{"../modules/a"},
+		},
+		}, "path outside repository", true},
+		{fields{Changelog: &Changelog{
+			From:  tv{Type: types.Tag, Value: "tag1"},
+			To:    tv{Type: types.Tag, Value: "tag2"},
+			Paths: []string{"/modules/a"},
+		},
+		}, "absolute path", true},
+		{fields{Changelog: &Changelog{
+			From:    tv{Type: types.Tag, Value: "tag1"},
+			To:      tv{Type: types.Tag, Value: "tag2"},
+			Locales: []types.Locale{types.Ru, types.En},
+		},
+		}, "valid locales", false},
+		{fields{Changelog: &Changelog{
+			From:    tv{Type: types.Tag, Value: "tag1"},
+			To:      tv{Type: types.Tag, Value: "tag2"},
+			Locales: []types.Locale{"de"},
+		},
+		}, "invalid locale", true},
+		{fields{Changelog: &Changelog{
+			From:            tv{Type: types.Tag, Value: "tag1"},
+			To:              tv{Type: types.Tag, Value: "tag2"},
+			FooterTemplates: map[types.Locale]string{"de": "footer"},
+		},
+		}, "invalid footer locale", true},
+		{fields{Changelog: &Changelog{
+			From:            tv{Type: types.Tag, Value: "tag1"},
+			To:              tv{Type: types.Tag, Value: "tag2"},
+			FooterTemplate:  "footer",
+			FooterTemplates: map[types.Locale]string{types.Ru: "footer"},
+		},
+		}, "duplicate ru footer", true},
 		{fields{Changelog: &Changelog{
 			From: tv{Type: types.Tag, Value: "tag1"},
 			To:   tv{Type: types.Tag, Value: "tag2"},
@@ -0,0 +1,136 @@
+package changelog
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+)
+
+var (
+	// versionHeadingRegex matches a second-level heading and captures its title,
+	// e.g. `1.2.0` in `## [1.2.0] - 2025-01-01`.
+	versionHeadingRegex = regexp.MustCompile(`^##\s+\[?v?([^\]\s]+)\]?`)
+	// linkDefinitionRegex matches a link reference definition, e.g. `[1.2.0]: https://...`.
+	linkDefinitionRegex = regexp.MustCompile(`^\[[^\]]+\]:\s*\S+`)
+	listItemRegex       = regexp.MustCompile(`^\s*(?:[-*+]|\d+\.)\s+(.*)$`)
+	inlineLinkRegex     = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`)
+	boldRegex           = regexp.MustCompile(`(\*\*|__)(.+?)(\*\*|__)`)
+	italicRegex         = regexp.MustCompile(`(^|[^\w*])[*_]([^*_\s][^*_]*?)[*_]([^\w*]|$)`)
+	codeRegex           = regexp.MustCompile("`([^`]*)`")
+)
+
+// VersionSection extracts the section of the given version from a Keep a Changelog style
+// Markdown document.
+//
+// A section starts with a second-level heading such as `## [1.2.0] - 2025-01-01`,
+// `## 1.2.0` or `## v1.2.0`, and ends before the next second-level heading.
+// Link reference definitions are left out.
+//
+// Returns:
+//   - The trimmed section body without its heading.
+//   - An error if the version is empty or there is no section for it, or the section is empty.
+func VersionSection(markdown, version string) (string, error) {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	if version == "" {
+		return "", fmt.Errorf("changelog file: version is required")
+	}
+
+	var body []string
+	found := false
+
+	for _, line := range strings.Split(strings.ReplaceAll(markdown, "\r\n", "\n"), "\n") {
+		if strings.HasPrefix(line, "## ") {
+			if found {
+				break
+			}
+
+			m := versionHeadingRegex.FindStringSubmatch(line)
+			found = len(m) > 1 && m[1] == version
+			continue
+		}
+
+		if found && !linkDefinitionRegex.MatchString(line) {
+			body = append(body, line)
+		}
+	}
+
+	if !found {
+		return "", fmt.Errorf("changelog file: section for version %s not found", version)
+	}
+
+	section := strings.TrimSpace(strings.Join(body, "\n"))
+	if section == "" {
+		return "", fmt.Errorf("changelog file: section for version %s is empty", version)
+	}
+
+	return section, nil
+}
+
+// MarkdownToHTML converts a Markdown fragment to the limited HTML accepted
+// in Marketplace release descriptions.
+//
+// Supported elements:
+//   - Headings of any level become bold lines.
+//   - List items (`-`, `*`, `+`, `1.`) become `<ul><li>` lists; nesting is flattened.
+//   - `**bold**`, `*italic*` and `[text](http://url)` become `<b>`, `<i>` and `<a>`.
+//   - Code spans are kept as plain text.
+//   - Other lines become text followed by `<br>`.
+//
+// All text is HTML-escaped, so raw HTML in the source is never passed through.
+func MarkdownToHTML(markdown string) string {
+	out := strings.Builder{}
+	inList := false
+
+	closeList := func() {
+		if inList {
+			out.WriteString("</ul>")
+			inList = false
+		}
+	}
+
+	for _, line := range strings.Split(strings.ReplaceAll(markdown, "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case trimmed == "":
+			closeList()
+		case strings.HasPrefix(trimmed, "#"):
+			closeList()
+			title := strings.TrimSpace(strings.TrimLeft(trimmed, "#"))
+			out.WriteString("<b>" + inlineToHTML(title) + "</b><br>")
+		case listItemRegex.MatchString(line):
+			if !inList {
+				out.WriteString("<ul>")
+				inList = true
+			}
+			item := listItemRegex.FindStringSubmatch(line)[1]
+			out.WriteString("<li>" + inlineToHTML(item) + "</li>")
+		default:
+			closeList()
+			out.WriteString(inlineToHTML(trimmed) + "<br>")
+		}
+	}
+
+	closeList()
+
+	return out.String()
+}
+
+// inlineToHTML escapes the text and converts inline Markdown markup to HTML.
+func inlineToHTML(s string) string {
+	s = html.EscapeString(s)
+	s = codeRegex.ReplaceAllString(s, "$1")
+	s = inlineLinkRegex.ReplaceAllStringFunc(s, func(match string) string {
+		m := inlineLinkRegex.FindStringSubmatch(match)
+		if !strings.HasPrefix(m[2], "http://") && !strings.HasPrefix(m[2], "https://") {
+			return m[1]
+		}
+
+		return fmt.Sprintf(`<a href="%s">%s</a>`, m[2], m[1])
+	})
+	s = boldRegex.ReplaceAllString(s, "<b>$2</b>")
+	s = italicRegex.ReplaceAllString(s, "$1<i>$2</i>$3")
+
+	return s
+}
@@ -0,0 +1,88 @@
+package changelog
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testChangelog = `# Changelog
+
+## [Unreleased]
+- Draft
+
+## [1.2.0] - 2025-03-01
+### Added
+- Export to **CSV**
+
+## v1.1.0
+Small fixes.
+
+## 1.0.0
+- Initial release
+
+[1.2.0]: https://example.com/compare/v1.1.0...v1.2.0
+`
+
+func TestVersionSection(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		version string
+		want    string
+		wantErr bool
+	}{
+		{"bracketed with date", "1.2.0", "### Added\n- Export to **CSV**", false},
+		{"v prefix", "1.1.0", "Small fixes.", false},
+		{"version with v", "v1.1.0", "Small fixes.", false},
+		{"last section", "1.0.0", "- Initial release", false},
+		{"prefix is not a match", "1.2", "", true},
+		{"missing", "3.0.0", "", true},
+		{"empty version", "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := VersionSection(testChangelog, tt.version)
+			if tt.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestVersionSection_empty(t *testing.T) {
+	t.Parallel()
+	_, err := VersionSection("## [1.0.0]\n\n## [0.9.0]\n- x", "1.0.0")
+	require.Error(t, err)
+}
+
+func TestMarkdownToHTML(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		markdown string
+		want     string
+	}{
+		{"heading and list", "### Added\n- one\n- two", "<b>Added</b><br><ul><li>one</li><li>two</li></ul>"},
+		{"paragraph", "Line one\nLine two", "Line one<br>Line two<br>"},
+		{"list closes on blank line", "- one\n\ntext", "<ul><li>one</li></ul>text<br>"},
+		{"numbered list", "1. one\n2. two", "<ul><li>one</li><li>two</li></ul>"},
+		{"bold and italic", "**bold** and *italic* and _it_", "<b>bold</b> and <i>italic</i> and <i>it</i><br>"},
+		{"code span", "use `bx build`", "use bx build<br>"},
+		{"link", "[docs](https://example.com)", `<a href="https://example.com">docs</a><br>`},
+		{"relative link", "[docs](./docs.md)", "docs<br>"},
+		{"escape html", "<script>a & b</script>", "&lt;script&gt;a &amp; b&lt;/script&gt;<br>"},
+		{"snake case", "some_var_name", "some_var_name<br>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, MarkdownToHTML(tt.markdown))
+		})
+	}
+}
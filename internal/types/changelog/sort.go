@@ -0,0 +1,107 @@
+package changelog
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/pixel365/bx/internal/types"
+)
+
+// conventionalRegex matches the header of a conventional commit, e.g. `feat(api)!: message`.
+var conventionalRegex = regexp.MustCompile(`^([a-zA-Z]+)(\([^)]*\))?!?:`)
+
+// defaultTypesOrder is the order of conventional commit types used by the `type` sorting
+// when `typesOrder` is not set.
+var defaultTypesOrder = []string{
+	"feat",
+	"fix",
+	"perf",
+	"refactor",
+	"revert",
+	"docs",
+	"style",
+	"test",
+	"build",
+	"ci",
+	"chore",
+}
+
+// SortCommits sorts the commits in place according to the Sort field.
+//
+// Supported sorting types:
+//   - Asc, Desc: alphabetical order of the transformed commit messages.
+//   - DateAsc, DateDesc: committer time, oldest or newest first.
+//   - Topological: children before parents, like `git log --topo-order`.
+//   - ConventionalType: grouped by conventional commit type using TypesOrder
+//     (or the default order); commits without a known type go last.
+//     Topological order is kept inside each group.
+//
+// If Sort is empty, the order in which the commits were read from the repository is kept.
+func (c *Changelog) SortCommits(commits []Commit) {
+	switch c.Sort {
+	case types.Asc:
+		slices.SortStableFunc(commits, compareMessages)
+	case types.Desc:
+		slices.SortStableFunc(commits, compareMessages)
+		slices.Reverse(commits)
+	case types.DateAsc:
+		slices.SortStableFunc(commits, compareDates)
+	case types.DateDesc:
+		slices.SortStableFunc(commits, func(a, b Commit) int {
+			return compareDates(b, a)
+		})
+	case types.Topological:
+		slices.SortStableFunc(commits, compareOrder)
+	case types.ConventionalType:
+		order := c.TypesOrder
+		if len(order) == 0 {
+			order = defaultTypesOrder
+		}
+
+		slices.SortStableFunc(commits, func(a, b Commit) int {
+			if diff := typeRank(a.Subject, order) - typeRank(b.Subject, order); diff != 0 {
+				return diff
+			}
+
+			return compareOrder(a, b)
+		})
+	}
+}
+
+// CommitType returns the lower-cased conventional commit type of the subject
+// (e.g. "feat" for "feat(api): message"), or an empty string if the subject
+// does not follow the conventional commits format.
+func CommitType(subject string) string {
+	m := conventionalRegex.FindStringSubmatch(strings.TrimSpace(subject))
+	if len(m) < 2 {
+		return ""
+	}
+
+	return strings.ToLower(m[1])
+}
+
+// typeRank returns the position of the subject type in order; the types are compared case-insensitively.
+// Unknown and missing types are ranked after all known ones.
+func typeRank(subject string, order []string) int {
+	t := CommitType(subject)
+	if t != "" {
+		if i := slices.IndexFunc(order, func(o string) bool { return strings.EqualFold(o, t) }); i >= 0 {
+			return i
+		}
+	}
+
+	return len(order)
+}
+
+func compareMessages(a, b Commit) int {
+	return strings.Compare(a.Message, b.Message)
+}
+
+func compareDates(a, b Commit) int {
+	return a.When.Compare(b.When)
+}
+
+func compareOrder(a, b Commit) int {
+	return a.Order - b.Order
+}
@@ -0,0 +1,83 @@
+package changelog
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pixel365/bx/internal/types"
+)
+
+func TestChangelog_SortCommits(t *testing.T) {
+	t.Parallel()
+
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	commits := func() []Commit {
+		return []Commit{
+			{Subject: "fix: b", Message: "b", When: base.Add(time.Hour), Order: 1},
+			{Subject: "chore: c", Message: "c", When: base, Order: 2},
+			{Subject: "feat: a", Message: "a", When: base.Add(2 * time.Hour), Order: 0},
+			{Subject: "unknown d", Message: "d", When: base.Add(3 * time.Hour), Order: 3},
+		}
+	}
+
+	messages := func(cs []Commit) []string {
+		result := make([]string, 0, len(cs))
+		for _, c := range cs {
+			result = append(result, c.Message)
+		}
+		return result
+	}
+
+	tests := []struct {
+		name       string
+		sort       types.SortingType
+		typesOrder []string
+		want       []string
+	}{
+		{"no sort", "", nil, []string{"b", "c", "a", "d"}},
+		{"asc", types.Asc, nil, []string{"a", "b", "c", "d"}},
+		{"desc", types.Desc, nil, []string{"d", "c", "b", "a"}},
+		{"date asc", types.DateAsc, nil, []string{"c", "b", "a", "d"}},
+		{"date desc", types.DateDesc, nil, []string{"d", "a", "b", "c"}},
+		{"topological", types.Topological, nil, []string{"a", "b", "c", "d"}},
+		{"type", types.ConventionalType, nil, []string{"a", "b", "c", "d"}},
+		{"custom type order", types.ConventionalType, []string{"chore", "fix"}, []string{"c", "b", "a", "d"}},
+		{"custom type order in upper case", types.ConventionalType, []string{"Chore", "FIX"}, []string{"c", "b", "a", "d"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			c := &Changelog{Sort: tt.sort, TypesOrder: tt.typesOrder}
+			got := commits()
+			c.SortCommits(got)
+			assert.Equal(t, tt.want, messages(got))
+		})
+	}
+}
+
+func TestCommitType(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		subject string
+		want    string
+	}{
+		{"feat: message", "feat"},
+		{"Fix(api): message", "fix"},
+		{"refactor!: message", "refactor"},
+		{"feat(core)!: message", "feat"},
+		{"message without type", ""},
+		{"feat message", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.subject, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, CommitType(tt.subject))
+		})
+	}
+}
@@ -1,59 +1,96 @@
 package types
 
-import "strings"
+import (
+	"path/filepath"
+	"strings"
+	"sync"
+)
 
+// Changes describes the files changed between two points of a Git repository.
+//
+// All paths are relative to the repository root and use forward slashes,
+// as reported by Git.
 type Changes struct {
+	// Renamed maps the new path of a renamed file to its old path.
+	Renamed map[string]string
+	// index is the set of added, modified and moved paths used by IsChangedFile.
+	index map[string]struct{}
+	// Root is the absolute path of the repository working tree.
+	// It is used to resolve absolute paths passed to IsChangedFile.
+	Root     string
 	Added    []string
 	Modified []string
 	Deleted  []string
 	Moved    []string
+	once     sync.Once
 }
 
-// IsChangedFile checks whether the given file path corresponds to a file that has been added or modified.
+// IsChangedFile checks whether the given file has been added, modified or moved (renamed).
 //
 // Parameters:
-//   - path: The file path to check.
+//   - path: An absolute path, or a path relative to the repository root.
 //
 // Returns:
-//   - true if the file is in the list of added or modified files.
-//   - false otherwise.
+//   - true if the file is in the list of added, modified or moved files.
+//   - false otherwise, including absolute paths outside Root.
 //
 // Behavior:
-//   - Iterates through the `Added` and `Modified` slices of the `Changes` struct.
-//   - Uses `strings.HasSuffix` to check if the provided path ends with any of the added or modified file names.
-//   - Returns true on the first match, otherwise false.
-//
-// Notes:
-//   - This method assumes that `o.Added` and `o.Modified` contain relative or base file names.
-//   - If files are stored with full paths in `o.Added` and `o.Modified`, this method may produce false negatives.
+//   - Absolute paths are made relative to Root; if Root is empty, they never match.
+//   - The path is cleaned and compared exactly, so `lib/a.php` does not match `otherlib/a.php`.
+//   - Lookups use a set built on the first call; the lists must not be changed after that.
+//   - The old path of a renamed file is not a changed file: it no longer exists.
 //
 // Example:
 //
 //	changes := Changes{
-//	    Added:    []string{"file1.txt", "dir/file2.go"},
+//	    Root:     "/home/user/module",
+//	    Added:    []string{"lib/a.php"},
 //	    Modified: []string{"config.yaml"},
 //	}
-//	fmt.Println(changes.IsChangedFile("project/dir/file2.go")) // true
-//	fmt.Println(changes.IsChangedFile("config.yaml"))         // true
-//	fmt.Println(changes.IsChangedFile("untracked.txt"))       // false
+//	fmt.Println(changes.IsChangedFile("/home/user/module/lib/a.php"))      // true
+//	fmt.Println(changes.IsChangedFile("config.yaml"))                      // true
+//	fmt.Println(changes.IsChangedFile("/home/user/module/otherlib/a.php")) // false
 func (o *Changes) IsChangedFile(path string) bool {
-	for _, f := range o.Added {
-		if strings.HasSuffix(path, f) {
-			return true
-		}
+	o.once.Do(o.buildIndex)
+
+	path, ok := o.relative(path)
+	if !ok {
+		return false
 	}
 
-	for _, f := range o.Modified {
-		if strings.HasSuffix(path, f) {
-			return true
+	_, ok = o.index[path]
+	return ok
+}
+
+// relative converts the path to a cleaned, slash-separated path relative to Root.
+// It reports false if the path cannot be resolved inside the repository.
+func (o *Changes) relative(path string) (string, bool) {
+	if filepath.IsAbs(path) {
+		if o.Root == "" {
+			return "", false
 		}
-	}
 
-	for _, f := range o.Moved {
-		if strings.HasSuffix(path, f) {
-			return true
+		rel, err := filepath.Rel(o.Root, path)
+		if err != nil {
+			return "", false
 		}
+		path = rel
 	}
 
-	return false
+	path = filepath.ToSlash(filepath.Clean(path))
+	if path == "." || path == ".." || strings.HasPrefix(path, "../") {
+		return "", false
+	}
+
+	return path, true
+}
+
+func (o *Changes) buildIndex() {
+	o.index = make(map[string]struct{}, len(o.Added)+len(o.Modified)+len(o.Moved))
+
+	for _, list := range [][]string{o.Added, o.Modified, o.Moved} {
+		for _, f := range list {
+			o.index[filepath.ToSlash(filepath.Clean(f))] = struct{}{}
+		}
+	}
 }
@@ -64,3 +64,46 @@ func TestChanges_IsChangedFile(t *testing.T) {
 		})
 	}
 }
+
+func TestChanges_IsChangedFile_paths(t *testing.T) {
+	t.Parallel()
+
+	o := &Changes{
+		Root:     "/repo",
+		Added:    []string{"lib/a.php"},
+		Modified: []string{"install/index.php"},
+		Moved:    []string{"lib/new.php"},
+		Renamed:  map[string]string{"lib/new.php": "lib/old.php"},
+	}
+
+	tests := []struct {
+		name string
+		path string
+		want bool
+	}{
+		{"absolute", "/repo/lib/a.php", true},
+		{"relative", "lib/a.php", true},
+		{"not cleaned", "/repo/lib/../lib/./a.php", true},
+		{"suffix of another dir", "/repo/otherlib/a.php", false},
+		{"relative suffix", "otherlib/a.php", false},
+		{"outside root", "/other/lib/a.php", false},
+		{"parent", "../repo/lib/a.php", false},
+		{"root itself", "/repo", false},
+		{"rename new path", "/repo/lib/new.php", true},
+		{"rename old path", "/repo/lib/old.php", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, o.IsChangedFile(tt.path))
+		})
+	}
+}
+
+func TestChanges_IsChangedFile_no_root(t *testing.T) {
+	t.Parallel()
+
+	o := &Changes{Added: []string{"a.php"}}
+	assert.False(t, o.IsChangedFile("/repo/a.php"))
+	assert.True(t, o.IsChangedFile("a.php"))
+}
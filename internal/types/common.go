@@ -6,24 +6,36 @@ type ChangelogConditionType string
 type SortingType string
 type BuildType string
 type TransformType string
+type ChangelogScopeType string
 
 const (
 	Replace        FileExistsAction = "replace"
 	Skip           FileExistsAction = "skip"
 	ReplaceIfNewer FileExistsAction = "replace_if_newer"
 
-	Commit ChangelogType = "commit"
-	Tag    ChangelogType = "tag"
+	Commit   ChangelogType = "commit"
+	Tag      ChangelogType = "tag"
+	Worktree ChangelogType = "worktree"
 
 	Include ChangelogConditionType = "include"
 	Exclude ChangelogConditionType = "exclude"
 
-	Asc  SortingType = "asc"
-	Desc SortingType = "desc"
-
-	StripPrefix TransformType = "stripPrefix"
-	StripSuffix TransformType = "stripSuffix"
-	RemoveAll   TransformType = "removeAll"
+	StagesScope ChangelogScopeType = "stages"
+
+	Asc              SortingType = "asc"
+	Desc             SortingType = "desc"
+	DateAsc          SortingType = "dateAsc"
+	DateDesc         SortingType = "dateDesc"
+	Topological      SortingType = "topo"
+	ConventionalType SortingType = "type"
+
+	StripPrefix  TransformType = "stripPrefix"
+	StripSuffix  TransformType = "stripSuffix"
+	RemoveAll    TransformType = "removeAll"
+	RegexReplace TransformType = "regexReplace"
+	Capitalize   TransformType = "capitalize"
+	Map          TransformType = "map"
+	LinkIssues   TransformType = "linkIssues"
 )
 
 type TypeValue[T1 any, T2 any] struct {
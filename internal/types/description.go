@@ -0,0 +1,51 @@
+package types
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Description is the release description of the module.
+//
+// In YAML, it is either a plain string with the description text:
+//
+//	description: "Bug fixes"
+//
+// or a mapping with the path to a Keep a Changelog style Markdown file:
+//
+//	description:
+//	  fromFile: "./CHANGELOG.md"
+type Description struct {
+	Text     string `yaml:"text,omitempty"`
+	FromFile string `yaml:"fromFile,omitempty"`
+}
+
+// IsEmpty reports whether neither the text nor the file is set.
+func (d Description) IsEmpty() bool {
+	return d.Text == "" && d.FromFile == ""
+}
+
+// UnmarshalYAML accepts both a scalar (the description text) and a mapping.
+func (d *Description) UnmarshalYAML(node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		d.FromFile = ""
+		return node.Decode(&d.Text)
+	case yaml.MappingNode:
+		type plain Description
+		return node.Decode((*plain)(d))
+	default:
+		return fmt.Errorf("description: line %d: string or mapping expected", node.Line)
+	}
+}
+
+// MarshalYAML writes the description as a plain string unless a file is set.
+func (d Description) MarshalYAML() (any, error) {
+	if d.FromFile == "" {
+		return d.Text, nil
+	}
+
+	type plain Description
+	return plain(d), nil
+}
@@ -0,0 +1,59 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestDescription_YAML(t *testing.T) {
+	t.Parallel()
+
+	type wrapper struct {
+		Description Description `yaml:"description,omitempty"`
+	}
+
+	tests := []struct {
+		name    string
+		in      string
+		want    Description
+		wantErr bool
+	}{
+		{"scalar", `description: "some text"`, Description{Text: "some text"}, false},
+		{"mapping", "description:\n  fromFile: CHANGELOG.md", Description{FromFile: "CHANGELOG.md"}, false},
+		{"sequence", "description:\n  - a", Description{}, true},
+		{"missing", `name: test`, Description{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var w wrapper
+			err := yaml.Unmarshal([]byte(tt.in), &w)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, w.Description)
+
+			out, err := yaml.Marshal(w)
+			require.NoError(t, err)
+
+			var back wrapper
+			require.NoError(t, yaml.Unmarshal(out, &back))
+			assert.Equal(t, tt.want, back.Description)
+		})
+	}
+}
+
+func TestDescription_IsEmpty(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, Description{}.IsEmpty())
+	assert.False(t, Description{Text: "text"}.IsEmpty())
+	assert.False(t, Description{FromFile: "CHANGELOG.md"}.IsEmpty())
+}
@@ -0,0 +1,111 @@
+package types
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	DefaultHTTPTimeout   = 30 * time.Second
+	DefaultUploadTimeout = 10 * time.Minute
+	DefaultRetries       = 3
+	DefaultRetryDelay    = 500 * time.Millisecond
+	DefaultMaxRetryDelay = 10 * time.Second
+)
+
+// Operation names a kind of request to the partner portal.
+// It selects the timeout and the retry policy of the request.
+type Operation string
+
+const (
+	OperationLogin    Operation = "login"
+	OperationSession  Operation = "session"
+	OperationVersions Operation = "versions"
+	OperationLabels   Operation = "labels"
+	OperationUpload   Operation = "upload"
+)
+
+type operationContextKey struct{}
+
+var operationKey operationContextKey
+
+// Idempotent reports whether the operation can be repeated safely after a failure.
+// Logging in, reading the session ID and reading the versions can be; changing labels
+// and uploading a release are never retried blindly.
+func (o Operation) Idempotent() bool {
+	switch o {
+	case OperationLogin, OperationSession, OperationVersions:
+		return true
+	default:
+		return false
+	}
+}
+
+// WithOperation returns a copy of the context that carries the operation of a portal request.
+func WithOperation(ctx context.Context, op Operation) context.Context {
+	return context.WithValue(ctx, operationKey, op)
+}
+
+// OperationFromContext returns the operation set by WithOperation, or an empty string.
+func OperationFromContext(ctx context.Context) Operation {
+	op, _ := ctx.Value(operationKey).(Operation)
+	return op
+}
+
+// HTTP configures timeouts and retries of the requests to the partner portal.
+// Zero values mean the defaults.
+type HTTP struct {
+	// Retries is the number of retries of idempotent requests. Zero disables retries.
+	Retries *int `yaml:"retries,omitempty"`
+	// Timeout limits every attempt of a request, except uploads.
+	Timeout time.Duration `yaml:"timeout,omitempty"`
+	// UploadTimeout limits the release upload.
+	UploadTimeout time.Duration `yaml:"uploadTimeout,omitempty"`
+	// RetryDelay is the delay before the first retry; it doubles with every retry.
+	RetryDelay time.Duration `yaml:"retryDelay,omitempty"`
+	// MaxRetryDelay caps the delay between retries.
+	MaxRetryDelay time.Duration `yaml:"maxRetryDelay,omitempty"`
+}
+
+// RequestTimeout returns the timeout of a single attempt of the operation.
+func (h *HTTP) RequestTimeout(op Operation) time.Duration {
+	if op == OperationUpload {
+		if h == nil || h.UploadTimeout <= 0 {
+			return DefaultUploadTimeout
+		}
+		return h.UploadTimeout
+	}
+
+	if h == nil || h.Timeout <= 0 {
+		return DefaultHTTPTimeout
+	}
+
+	return h.Timeout
+}
+
+// MaxRetries returns the number of retries of idempotent requests.
+func (h *HTTP) MaxRetries() int {
+	if h == nil || h.Retries == nil {
+		return DefaultRetries
+	}
+
+	return max(*h.Retries, 0)
+}
+
+// Backoff returns the delay before the retry with the given number, starting at 1,
+// without jitter: RetryDelay doubled for every previous retry, capped by MaxRetryDelay.
+func (h *HTTP) Backoff(retry int) time.Duration {
+	delay, limit := DefaultRetryDelay, DefaultMaxRetryDelay
+	if h != nil && h.RetryDelay > 0 {
+		delay = h.RetryDelay
+	}
+	if h != nil && h.MaxRetryDelay > 0 {
+		limit = h.MaxRetryDelay
+	}
+
+	for i := 1; i < retry && delay < limit; i++ {
+		delay *= 2
+	}
+
+	return min(delay, limit)
+}
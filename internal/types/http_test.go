@@ -0,0 +1,51 @@
+package types
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestHTTP_defaults(t *testing.T) {
+	t.Parallel()
+
+	var h *HTTP
+	assert.Equal(t, DefaultHTTPTimeout, h.RequestTimeout(OperationVersions))
+	assert.Equal(t, DefaultUploadTimeout, h.RequestTimeout(OperationUpload))
+	assert.Equal(t, DefaultRetries, h.MaxRetries())
+	assert.Equal(t, DefaultRetryDelay, h.Backoff(1))
+	assert.Equal(t, DefaultMaxRetryDelay, h.Backoff(100))
+}
+
+func TestHTTP_yaml(t *testing.T) {
+	t.Parallel()
+
+	var h HTTP
+	err := yaml.Unmarshal([]byte("timeout: 15s\nuploadTimeout: 30m\nretries: 0\nretryDelay: 1s\nmaxRetryDelay: 5s\n"), &h)
+	require.NoError(t, err)
+
+	assert.Equal(t, 15*time.Second, h.RequestTimeout(OperationLogin))
+	assert.Equal(t, 30*time.Minute, h.RequestTimeout(OperationUpload))
+	assert.Equal(t, 0, h.MaxRetries())
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second},
+		[]time.Duration{h.Backoff(1), h.Backoff(2), h.Backoff(3), h.Backoff(4)})
+}
+
+func TestOperation(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, OperationLogin.Idempotent())
+	assert.True(t, OperationSession.Idempotent())
+	assert.True(t, OperationVersions.Idempotent())
+	assert.False(t, OperationLabels.Idempotent())
+	assert.False(t, OperationUpload.Idempotent())
+	assert.False(t, Operation("").Idempotent())
+
+	ctx := WithOperation(context.Background(), OperationUpload)
+	assert.Equal(t, OperationUpload, OperationFromContext(ctx))
+	assert.Equal(t, Operation(""), OperationFromContext(context.Background()))
+}
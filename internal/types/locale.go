@@ -0,0 +1,28 @@
+package types
+
+import "golang.org/x/text/encoding/charmap"
+
+type Locale string
+
+const (
+	Ru Locale = "ru"
+	En Locale = "en"
+)
+
+// Locales lists the description locales supported by the Marketplace.
+var Locales = []Locale{Ru, En}
+
+// IsValid reports whether the locale is supported by the Marketplace.
+func (l Locale) IsValid() bool {
+	return l == Ru || l == En
+}
+
+// Charmap returns the charset used for files of the locale:
+// Windows-1251 for Russian and ISO-8859-1 for English.
+func (l Locale) Charmap() *charmap.Charmap {
+	if l == En {
+		return charmap.ISO8859_1
+	}
+
+	return charmap.Windows1251
+}
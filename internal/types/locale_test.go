@@ -0,0 +1,19 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/text/encoding/charmap"
+)
+
+func TestLocale(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, Ru.IsValid())
+	assert.True(t, En.IsValid())
+	assert.False(t, Locale("de").IsValid())
+
+	assert.Equal(t, charmap.Windows1251, Ru.Charmap())
+	assert.Equal(t, charmap.ISO8859_1, En.Charmap())
+}
@@ -1,8 +1,34 @@
 package types
 
+import (
+	"io"
+	"os"
+	"path/filepath"
+)
+
 type Path struct {
+	// Source is the file tree From is read from. If nil, the local file system is used.
+	Source         Source
 	From           string
 	To             string
 	ActionIfExists FileExistsAction
 	Convert        bool
+	// Done, if set, is called after the copy task is processed; copied reports whether the file was written.
+	Done func(copied bool)
+}
+
+// Source is a read-only file tree the stage sources are copied from,
+// e.g. the local file system or a Git tree at a given ref.
+//
+// Paths are file system paths as written in the stages (absolute or relative
+// to the working directory); each implementation maps them onto its own tree.
+//
+// Methods:
+//   - Walk: Walks the tree rooted at root like `filepath.Walk`, including `filepath.SkipDir` support.
+//   - IsDir: Reports whether the path is a directory; returns an error if it does not exist.
+//   - Open: Opens a regular file for reading and returns its info.
+type Source interface {
+	Walk(root string, fn filepath.WalkFunc) error
+	IsDir(path string) (bool, error)
+	Open(path string) (io.ReadCloser, os.FileInfo, error)
 }
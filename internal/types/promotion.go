@@ -0,0 +1,16 @@
+package types
+
+// PromotionRule moves the newest version with the From label to the To label (see `bx promote`).
+type PromotionRule struct {
+	From VersionLabel `yaml:"from"`
+	To   VersionLabel `yaml:"to"`
+}
+
+// DefaultPromotionRules are used when the module has no promotion rules:
+// the newest alpha becomes beta, and the newest beta becomes stable.
+func DefaultPromotionRules() []PromotionRule {
+	return []PromotionRule{
+		{From: Alpha, To: Beta},
+		{From: Beta, To: Stable},
+	}
+}
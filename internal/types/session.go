@@ -0,0 +1,23 @@
+package types
+
+import "time"
+
+// DefaultSessionTTL is how long a cached portal session is reused when the TTL is not set.
+const DefaultSessionTTL = 12 * time.Hour
+
+// Session configures the on-disk cache of the partners.1c-bitrix.ru session.
+type Session struct {
+	// TTL limits how long cached cookies are reused, e.g. `8h`. Zero means DefaultSessionTTL.
+	TTL time.Duration `yaml:"ttl,omitempty"`
+	// Cache enables the session cache.
+	Cache bool `yaml:"cache"`
+}
+
+// Lifetime returns the TTL, or DefaultSessionTTL if it is not set.
+func (s *Session) Lifetime() time.Duration {
+	if s == nil || s.TTL <= 0 {
+		return DefaultSessionTTL
+	}
+
+	return s.TTL
+}
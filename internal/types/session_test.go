@@ -0,0 +1,22 @@
+package types
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestSession_Lifetime(t *testing.T) {
+	t.Parallel()
+
+	var s Session
+	require.NoError(t, yaml.Unmarshal([]byte("cache: true\nttl: 8h\n"), &s))
+	assert.True(t, s.Cache)
+	assert.Equal(t, 8*time.Hour, s.Lifetime())
+
+	assert.Equal(t, DefaultSessionTTL, (&Session{Cache: true}).Lifetime())
+	assert.Equal(t, DefaultSessionTTL, (*Session)(nil).Lifetime())
+}
@@ -8,13 +8,18 @@ import (
 	"syscall"
 
 	"github.com/pixel365/bx/cmd"
+	"github.com/pixel365/bx/internal/redact"
 )
 
 func main() {
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
 
+	// errors and warnings may contain passwords and tokens
+	log.SetOutput(redact.Writer(os.Stderr))
+
 	root := cmd.NewRootCmd(ctx)
+	cmd.SetOutput(root, os.Stdout, os.Stderr)
 	if err := root.Execute(); err != nil {
 		stop()
 		log.Fatalf("error: %v", err)